@@ -14,6 +14,7 @@ type FrameworkConfig struct {
 	Security SecurityConfig `json:"security"`
 	Timeouts TimeoutConfig  `json:"timeouts"`
 	Mobile   MobileConfig   `json:"mobile"`
+	IDs      IDConfig       `json:"ids"`
 }
 
 // HTTPConfig holds HTTP client configuration
@@ -30,6 +31,36 @@ type HTTPConfig struct {
 	RequestTimeout      time.Duration `json:"request_timeout"`
 	EnableFallback      bool          `json:"enable_fallback"`
 	UserAgent           string        `json:"user_agent"`
+	MaxInFlight         int           `json:"max_in_flight"` // process-wide cap on concurrent upstream calls, 0 for none
+
+	// RateLimit limits the requests each upstream client sends; a zero
+	// RequestsPerSecond disables it
+	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// Upstreams configures named upstream services, each getting its own
+	// connection pool, circuit breaker and bulkhead
+	Upstreams map[string]UpstreamConfig `json:"upstreams,omitempty"`
+}
+
+// UpstreamConfig configures the client of one upstream service. Zero values
+// inherit the HTTPConfig setting; MaxRetries and EnableFallback are pointers
+// so that 0 and false can be set explicitly.
+type UpstreamConfig struct {
+	Hosts               []string      `json:"hosts,omitempty"` // request hosts routed to this upstream
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host,omitempty"`
+	MaxRetries          *int          `json:"max_retries,omitempty"`
+	RetryDelay          time.Duration `json:"retry_delay,omitempty"`
+	MaxRetryDelay       time.Duration `json:"max_retry_delay,omitempty"`
+	FailureThreshold    uint          `json:"failure_threshold,omitempty"`
+	SuccessThreshold    uint          `json:"success_threshold,omitempty"`
+	CircuitBreakerDelay time.Duration `json:"circuit_breaker_delay,omitempty"`
+	RequestTimeout      time.Duration `json:"request_timeout,omitempty"`
+	EnableFallback      *bool         `json:"enable_fallback,omitempty"`
+	MaxConcurrent       int           `json:"max_concurrent,omitempty"` // bulkhead size, 0 for none
+	MaxWait             time.Duration `json:"max_wait,omitempty"`       // how long to wait for the bulkhead, 0 to fail fast
+
+	// RateLimit replaces the HTTPConfig rate limit when set
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
 }
 
 // CacheConfig holds caching configuration
@@ -62,11 +93,15 @@ type TokenValidationConfig struct {
 	ExpiryBuffer time.Duration `json:"expiry_buffer"`
 }
 
-// RateLimitConfig holds rate limiting settings
+// RateLimitConfig holds rate limiting settings. PerHost and FailFast only
+// apply to HTTP client limits: they give each request host its own limit,
+// and fail requests at once instead of waiting for the limit.
 type RateLimitConfig struct {
 	RequestsPerSecond int           `json:"requests_per_second"`
 	BurstSize         int           `json:"burst_size"`
 	WindowSize        time.Duration `json:"window_size"`
+	PerHost           bool          `json:"per_host,omitempty"`
+	FailFast          bool          `json:"fail_fast,omitempty"`
 }
 
 // TimeoutConfig holds various timeout settings
@@ -102,6 +137,13 @@ func DefaultConfig() *FrameworkConfig {
 			RequestTimeout:      getEnvDuration("HTTP_REQUEST_TIMEOUT", 15*time.Second),
 			EnableFallback:      getEnvBool("HTTP_ENABLE_FALLBACK", true),
 			UserAgent:           getEnvString("HTTP_USER_AGENT", "API-Orchestration-Framework/2.0"),
+			MaxInFlight:         getEnvInt("HTTP_MAX_IN_FLIGHT", 0),
+			RateLimit: RateLimitConfig{
+				RequestsPerSecond: getEnvInt("HTTP_RATE_LIMIT_RPS", 0),
+				BurstSize:         getEnvInt("HTTP_RATE_LIMIT_BURST", 10),
+				PerHost:           getEnvBool("HTTP_RATE_LIMIT_PER_HOST", false),
+				FailFast:          getEnvBool("HTTP_RATE_LIMIT_FAIL_FAST", false),
+			},
 		},
 		Cache: CacheConfig{
 			DefaultTTL:    getEnvDuration("CACHE_DEFAULT_TTL", 5*time.Minute),
@@ -141,6 +183,9 @@ func DefaultConfig() *FrameworkConfig {
 			EnableCompression: getEnvBool("MOBILE_ENABLE_COMPRESSION", true),
 			OptimizeImages:    getEnvBool("MOBILE_OPTIMIZE_IMAGES", true),
 		},
+		IDs: IDConfig{
+			Strategy: getEnvString("ID_STRATEGY", IDStrategySortable),
+		},
 	}
 }
 
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// ID generation strategies for IDConfig
+const (
+	IDStrategyRandom   = "random"   // 128 random bits
+	IDStrategySortable = "sortable" // UUIDv7, ordered by creation time
+)
+
+// IDConfig controls how execution, request and branch IDs are generated
+type IDConfig struct {
+	Strategy string `json:"strategy"` // random, sortable
+
+	// Generator, when set, replaces the strategy with caller-supplied IDs,
+	// e.g. ones issued by an API gateway. It must be safe for concurrent use.
+	Generator func() string `json:"-"`
+}
+
+// NewID generates an ID with the configured generator or strategy,
+// defaulting to sortable IDs
+func (c IDConfig) NewID() string {
+	if c.Generator != nil {
+		return c.Generator()
+	}
+	if c.Strategy == IDStrategyRandom {
+		return RandomID()
+	}
+	return SortableID()
+}
+
+// RandomID returns 128 random bits as a 32 character hex string
+func RandomID() string {
+	var b [16]byte
+	readRandom(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// sortableState keeps SortableID monotonic within a millisecond
+var sortableState struct {
+	mu     sync.Mutex
+	millis int64
+	seq    uint16
+}
+
+// SortableID returns a UUIDv7 (RFC 9562): a 48-bit millisecond timestamp
+// followed by random bits, so IDs sort by creation time. IDs created in the
+// same millisecond by this process keep increasing through a 12-bit
+// counter.
+func SortableID() string {
+	var b [16]byte
+	readRandom(b[:])
+
+	millis := time.Now().UnixMilli()
+	sortableState.mu.Lock()
+	if millis <= sortableState.millis {
+		// Same millisecond (or the clock went back): keep the previous
+		// timestamp and bump the counter, moving on once it overflows
+		sortableState.seq++
+		if sortableState.seq > 0x0fff {
+			sortableState.millis++
+			sortableState.seq = 0
+		}
+		millis = sortableState.millis
+	} else {
+		sortableState.millis = millis
+		sortableState.seq = binary.BigEndian.Uint16(b[6:8]) & 0x07ff
+	}
+	seq := sortableState.seq
+	sortableState.mu.Unlock()
+
+	b[0] = byte(millis >> 40)
+	b[1] = byte(millis >> 32)
+	b[2] = byte(millis >> 24)
+	b[3] = byte(millis >> 16)
+	b[4] = byte(millis >> 8)
+	b[5] = byte(millis)
+	b[6] = 0x70 | byte(seq>>8) // version 7
+	b[7] = byte(seq)
+	b[8] = 0x80 | b[8]&0x3f // RFC 9562 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+func readRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS source is unavailable, in which
+		// case nothing else will work either
+		panic("config: reading random bytes: " + err.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+)
+
+var uuidV7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRandomID(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := RandomID()
+		if len(id) != 32 {
+			t.Fatalf("RandomID() = %q, want 32 characters", id)
+		}
+		if seen[id] {
+			t.Fatalf("RandomID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSortableID(t *testing.T) {
+	ids := make([]string, 5000)
+	for i := range ids {
+		ids[i] = SortableID()
+		if !uuidV7Pattern.MatchString(ids[i]) {
+			t.Fatalf("SortableID() = %q, want a UUIDv7", ids[i])
+		}
+	}
+
+	if !sort.StringsAreSorted(ids) {
+		t.Error("SortableIDs created in sequence should sort in creation order")
+	}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("SortableID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
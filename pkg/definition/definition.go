@@ -0,0 +1,201 @@
+package definition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+)
+
+// FlowDefinition describes a flow declaratively so it can be loaded from
+// YAML or JSON instead of being assembled in Go. WriteCheck names the
+// flow.WriteCheck applied when two steps write the same key and defaults to
+// "warn"; AllowOverwrite lists keys exempt from it. History records the
+// context's change history (see flow.Flow.WithHistory).
+type FlowDefinition struct {
+	Name           string           `json:"name" yaml:"name"`
+	Description    string           `json:"description,omitempty" yaml:"description,omitempty"`
+	Timeout        string           `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	WriteCheck     string           `json:"write_check,omitempty" yaml:"write_check,omitempty"`
+	AllowOverwrite []string         `json:"allow_overwrite,omitempty" yaml:"allow_overwrite,omitempty"`
+	History        bool             `json:"history,omitempty" yaml:"history,omitempty"`
+	Steps          []StepDefinition `json:"steps" yaml:"steps"`
+}
+
+// StepDefinition describes a single step. Exactly one of Type, Choice,
+// Parallel, ForEach, Scope, Transform or Delay must be set.
+type StepDefinition struct {
+	Name    string                 `json:"name" yaml:"name"`
+	Type    string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Config  map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	Timeout string                 `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retry   *RetryDefinition       `json:"retry,omitempty" yaml:"retry,omitempty"`
+
+	Choice    *ChoiceDefinition    `json:"choice,omitempty" yaml:"choice,omitempty"`
+	Parallel  *ParallelDefinition  `json:"parallel,omitempty" yaml:"parallel,omitempty"`
+	ForEach   *ForEachDefinition   `json:"for_each,omitempty" yaml:"for_each,omitempty"`
+	Scope     *ScopeDefinition     `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Transform *TransformDefinition `json:"transform,omitempty" yaml:"transform,omitempty"`
+	Delay     string               `json:"delay,omitempty" yaml:"delay,omitempty"`
+}
+
+// RetryDefinition configures retries for a step
+type RetryDefinition struct {
+	MaxRetries int    `json:"max_retries" yaml:"max_retries"`
+	Delay      string `json:"delay,omitempty" yaml:"delay,omitempty"`
+}
+
+// ChoiceDefinition describes conditional branches evaluated in order
+type ChoiceDefinition struct {
+	When      []WhenDefinition `json:"when" yaml:"when"`
+	Otherwise []StepDefinition `json:"otherwise,omitempty" yaml:"otherwise,omitempty"`
+}
+
+// WhenDefinition is a single conditional branch
+type WhenDefinition struct {
+	Condition ConditionDefinition `json:"condition" yaml:"condition"`
+	Steps     []StepDefinition    `json:"steps" yaml:"steps"`
+}
+
+// ConditionDefinition is a field/operator/value condition evaluated with the
+// same semantics as core.ConditionStep, or an expression in the expr
+// language such as `user.tier == "gold" && len(cart.items) > 3`. Exactly one
+// of Field and Expr must be set.
+type ConditionDefinition struct {
+	Field    string      `json:"field,omitempty" yaml:"field,omitempty"`
+	Operator string      `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Expr     string      `json:"expr,omitempty" yaml:"expr,omitempty"`
+}
+
+// ParallelDefinition describes steps executed concurrently. Merge names the
+// flow.MergePolicy for branch writes and defaults to "written". OnFailure
+// names the flow.FailurePolicy and defaults to "wait_all"; Quorum is the
+// number of branches that must succeed under "quorum". MaxConcurrency
+// limits how many branches run at once.
+type ParallelDefinition struct {
+	Steps          []StepDefinition `json:"steps" yaml:"steps"`
+	Merge          string           `json:"merge,omitempty" yaml:"merge,omitempty"`
+	OnFailure      string           `json:"on_failure,omitempty" yaml:"on_failure,omitempty"`
+	Quorum         int              `json:"quorum,omitempty" yaml:"quorum,omitempty"`
+	MaxConcurrency int              `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty"`
+}
+
+// ForEachDefinition runs Steps once per element of the slice at Source.
+// Item and Index name the keys the element and its index are bound to
+// ("item" and "index" by default). Collect is the key read from each item's
+// context as its output, and Target the key the outputs are stored under
+// (the step name by default). Concurrency > 0 runs that many items at once.
+// OnItemFailure names the flow.ItemFailurePolicy and defaults to "fail".
+type ForEachDefinition struct {
+	Source        string           `json:"source" yaml:"source"`
+	Item          string           `json:"item,omitempty" yaml:"item,omitempty"`
+	Index         string           `json:"index,omitempty" yaml:"index,omitempty"`
+	Collect       string           `json:"collect,omitempty" yaml:"collect,omitempty"`
+	Target        string           `json:"target,omitempty" yaml:"target,omitempty"`
+	Concurrency   int              `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	OnItemFailure string           `json:"on_item_failure,omitempty" yaml:"on_item_failure,omitempty"`
+	Steps         []StepDefinition `json:"steps" yaml:"steps"`
+}
+
+// ScopeDefinition runs Steps in their own scope (see flow.ScopeStep).
+// Export lists keys copied to the parent under the same name, ExportAs maps
+// dot-notation paths in the scope to parent keys, and Namespace publishes
+// everything the scope wrote as one map.
+type ScopeDefinition struct {
+	Steps     []StepDefinition  `json:"steps" yaml:"steps"`
+	Export    []string          `json:"export,omitempty" yaml:"export,omitempty"`
+	ExportAs  map[string]string `json:"export_as,omitempty" yaml:"export_as,omitempty"`
+	Namespace string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// TransformDefinition applies a transformer chain to a map stored in the
+// context and stores the result under Target (Source when empty)
+type TransformDefinition struct {
+	Source       string                  `json:"source" yaml:"source"`
+	Target       string                  `json:"target,omitempty" yaml:"target,omitempty"`
+	Transformers []TransformerDefinition `json:"transformers" yaml:"transformers"`
+}
+
+// TransformerDefinition describes one transformer in a chain. Which fields
+// are used depends on Type.
+type TransformerDefinition struct {
+	Type     string                 `json:"type" yaml:"type"`
+	Name     string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	Fields   []string               `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Prefix   string                 `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	MaxDepth int                    `json:"max_depth,omitempty" yaml:"max_depth,omitempty"`
+	Mapping  map[string]string      `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+	Values   map[string]interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// ParseYAML decodes a YAML flow definition. Unknown keys are rejected so
+// typos surface as errors instead of silently being ignored.
+func ParseYAML(data []byte) (*FlowDefinition, error) {
+	var def FlowDefinition
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return nil, parseError("yaml", err)
+	}
+	return &def, nil
+}
+
+// ParseJSON decodes a JSON flow definition. Unknown keys are rejected.
+func ParseJSON(data []byte) (*FlowDefinition, error) {
+	var def FlowDefinition
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&def); err != nil {
+		return nil, parseError("json", err)
+	}
+	return &def, nil
+}
+
+// ParseFile decodes a flow definition file, choosing the format from the
+// file extension (.yaml, .yml or .json)
+func ParseFile(path string) (*FlowDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewConfigurationError(errors.ErrCodeMissingConfiguration,
+			fmt.Sprintf("Failed to read flow definition %s", path)).
+			WithContext("file", path).
+			WithCause(err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	case ".json":
+		return ParseJSON(data)
+	default:
+		return nil, errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+			fmt.Sprintf("Unsupported flow definition format: %s", filepath.Ext(path))).
+			WithContext("file", path)
+	}
+}
+
+func parseError(format string, cause error) *errors.FrameworkError {
+	err := errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+		fmt.Sprintf("Failed to parse %s flow definition", format)).
+		WithContext("format", format).
+		WithCause(cause)
+	err.Details = cause.Error()
+	return err
+}
+
+// definitionError reports a schema problem at a document path such as
+// "steps[2].parallel.steps[0].config.url"
+func definitionError(path, format string, args ...interface{}) *errors.FrameworkError {
+	err := errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+		fmt.Sprintf("Invalid flow definition at %s", path)).
+		WithContext("path", path)
+	err.Details = fmt.Sprintf(format, args...)
+	return err
+}
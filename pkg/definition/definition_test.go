@@ -0,0 +1,101 @@
+package definition
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+)
+
+const sampleYAML = `
+name: user_profile
+description: Fetch and shape a user profile
+timeout: 5s
+steps:
+  - name: fetch
+    type: http_get
+    config:
+      url: https://api.example.com/users
+    timeout: 1s
+    retry:
+      max_retries: 2
+      delay: 10ms
+  - name: pause
+    delay: 5ms
+`
+
+const sampleJSON = `{
+  "name": "user_profile",
+  "steps": [
+    {"name": "fetch", "type": "http_get", "config": {"url": "https://api.example.com/users"}}
+  ]
+}`
+
+func TestParseYAML(t *testing.T) {
+	def, err := ParseYAML([]byte(sampleYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, "user_profile", def.Name)
+	assert.Equal(t, "Fetch and shape a user profile", def.Description)
+	assert.Equal(t, "5s", def.Timeout)
+	require.Len(t, def.Steps, 2)
+	assert.Equal(t, "http_get", def.Steps[0].Type)
+	assert.Equal(t, "https://api.example.com/users", def.Steps[0].Config["url"])
+	require.NotNil(t, def.Steps[0].Retry)
+	assert.Equal(t, 2, def.Steps[0].Retry.MaxRetries)
+	assert.Equal(t, "5ms", def.Steps[1].Delay)
+}
+
+func TestParseYAML_UnknownField(t *testing.T) {
+	_, err := ParseYAML([]byte("name: f\nstepz: []\n"))
+
+	var fwErr *errors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, errors.ErrorTypeConfiguration, fwErr.Type)
+	assert.Contains(t, fwErr.Details, "stepz")
+}
+
+func TestParseJSON(t *testing.T) {
+	def, err := ParseJSON([]byte(sampleJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, "user_profile", def.Name)
+	require.Len(t, def.Steps, 1)
+	assert.Equal(t, "https://api.example.com/users", def.Steps[0].Config["url"])
+}
+
+func TestParseJSON_UnknownField(t *testing.T) {
+	_, err := ParseJSON([]byte(`{"name": "f", "steps": [{"name": "s", "kind": "x"}]}`))
+
+	var fwErr *errors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Contains(t, fwErr.Details, "kind")
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "flow.yml")
+	jsonPath := filepath.Join(dir, "flow.json")
+	textPath := filepath.Join(dir, "flow.txt")
+	require.NoError(t, os.WriteFile(yamlPath, []byte(sampleYAML), 0o600))
+	require.NoError(t, os.WriteFile(jsonPath, []byte(sampleJSON), 0o600))
+	require.NoError(t, os.WriteFile(textPath, []byte(sampleJSON), 0o600))
+
+	def, err := ParseFile(yamlPath)
+	require.NoError(t, err)
+	assert.Len(t, def.Steps, 2)
+
+	def, err = ParseFile(jsonPath)
+	require.NoError(t, err)
+	assert.Len(t, def.Steps, 1)
+
+	_, err = ParseFile(textPath)
+	assert.Error(t, err)
+
+	_, err = ParseFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,594 @@
+package definition
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/expr"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/core"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/transformers"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
+)
+
+// Loader compiles flow definitions into executable flows, resolving step
+// types through a step registry
+type Loader struct {
+	registry *registry.StepRegistry
+}
+
+// NewLoader creates a loader backed by the given registry. A nil registry
+// means the global registry.
+func NewLoader(reg *registry.StepRegistry) *Loader {
+	if reg == nil {
+		reg = registry.GetGlobalRegistry()
+	}
+	return &Loader{registry: reg}
+}
+
+// LoadYAML parses and builds a YAML flow definition
+func (l *Loader) LoadYAML(data []byte) (*flow.Flow, error) {
+	def, err := ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return l.Build(def)
+}
+
+// LoadJSON parses and builds a JSON flow definition
+func (l *Loader) LoadJSON(data []byte) (*flow.Flow, error) {
+	def, err := ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return l.Build(def)
+}
+
+// LoadFile parses and builds a flow definition file
+func (l *Loader) LoadFile(path string) (*flow.Flow, error) {
+	def, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.Build(def)
+}
+
+// Build validates a definition and compiles it into a flow. Validation
+// happens entirely before any step is created, so a definition either
+// builds completely or reports the first offending document path.
+func (l *Loader) Build(def *FlowDefinition) (*flow.Flow, error) {
+	if def.Name == "" {
+		return nil, definitionError("name", "flow name is required")
+	}
+	if len(def.Steps) == 0 {
+		return nil, definitionError("steps", "flow must have at least one step")
+	}
+
+	var timeout time.Duration
+	if def.Timeout != "" {
+		d, err := parseDuration("timeout", def.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		timeout = d
+	}
+
+	var writeCheck flow.WriteCheck
+	if def.WriteCheck != "" {
+		check, err := flow.ParseWriteCheck(def.WriteCheck)
+		if err != nil {
+			return nil, definitionError("write_check", "%v", err)
+		}
+		writeCheck = check
+	}
+
+	if err := l.validateSteps("steps", def.Steps); err != nil {
+		return nil, err
+	}
+
+	f := flow.NewFlow(def.Name)
+	if def.Description != "" {
+		f.WithDescription(def.Description)
+	}
+	if timeout > 0 {
+		f.WithTimeout(timeout)
+	}
+	if writeCheck != "" {
+		f.WithWriteCheck(writeCheck)
+	}
+	f.AllowOverwrite(def.AllowOverwrite...)
+	f.WithHistory(def.History)
+
+	for i, stepDef := range def.Steps {
+		step, err := l.buildStep(fmt.Sprintf("steps[%d]", i), stepDef)
+		if err != nil {
+			return nil, err
+		}
+		f.Step(stepDef.Name, step)
+	}
+
+	return f, nil
+}
+
+// Package-level helpers using the global registry
+
+// LoadYAML builds a YAML flow definition using the global registry
+func LoadYAML(data []byte) (*flow.Flow, error) {
+	return NewLoader(nil).LoadYAML(data)
+}
+
+// LoadJSON builds a JSON flow definition using the global registry
+func LoadJSON(data []byte) (*flow.Flow, error) {
+	return NewLoader(nil).LoadJSON(data)
+}
+
+// LoadFile builds a flow definition file using the global registry
+func LoadFile(path string) (*flow.Flow, error) {
+	return NewLoader(nil).LoadFile(path)
+}
+
+// Validation
+
+func (l *Loader) validateSteps(path string, steps []StepDefinition) error {
+	names := make(map[string]int, len(steps))
+	for i, step := range steps {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		if prev, exists := names[step.Name]; exists && step.Name != "" {
+			return definitionError(stepPath+".name", "duplicate step name '%s' (also used by %s[%d])", step.Name, path, prev)
+		}
+		names[step.Name] = i
+
+		if err := l.validateStep(stepPath, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loader) validateStep(path string, step StepDefinition) error {
+	if step.Name == "" {
+		return definitionError(path+".name", "step name is required")
+	}
+
+	kinds := 0
+	for _, set := range []bool{step.Type != "", step.Choice != nil, step.Parallel != nil, step.ForEach != nil, step.Scope != nil, step.Transform != nil, step.Delay != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return definitionError(path, "step must set exactly one of type, choice, parallel, for_each, scope, transform or delay")
+	}
+
+	if step.Config != nil && step.Type == "" {
+		return definitionError(path+".config", "config is only valid for registry steps with a type")
+	}
+	if step.Timeout != "" {
+		if _, err := parseDuration(path+".timeout", step.Timeout); err != nil {
+			return err
+		}
+	}
+	if step.Retry != nil {
+		if step.Retry.MaxRetries < 0 {
+			return definitionError(path+".retry.max_retries", "must not be negative")
+		}
+		if step.Retry.Delay != "" {
+			if _, err := parseDuration(path+".retry.delay", step.Retry.Delay); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch {
+	case step.Type != "":
+		return l.validateRegistryStep(path, step)
+	case step.Choice != nil:
+		return l.validateChoice(path+".choice", step.Choice)
+	case step.Parallel != nil:
+		if len(step.Parallel.Steps) == 0 {
+			return definitionError(path+".parallel.steps", "parallel block must have at least one step")
+		}
+		if step.Parallel.Merge != "" {
+			if _, err := flow.ParseMergePolicy(step.Parallel.Merge); err != nil {
+				return definitionError(path+".parallel.merge", "%v", err)
+			}
+		}
+		if err := validateFailurePolicy(path+".parallel", step.Parallel); err != nil {
+			return err
+		}
+		if step.Parallel.MaxConcurrency < 0 {
+			return definitionError(path+".parallel.max_concurrency", "max_concurrency must not be negative")
+		}
+		return l.validateSteps(path+".parallel.steps", step.Parallel.Steps)
+	case step.ForEach != nil:
+		return l.validateForEach(path+".for_each", step.ForEach)
+	case step.Scope != nil:
+		if len(step.Scope.Steps) == 0 {
+			return definitionError(path+".scope.steps", "scope must have at least one step")
+		}
+		return l.validateSteps(path+".scope.steps", step.Scope.Steps)
+	case step.Transform != nil:
+		return validateTransform(path+".transform", step.Transform)
+	default:
+		_, err := parseDuration(path+".delay", step.Delay)
+		return err
+	}
+}
+
+func (l *Loader) validateRegistryStep(path string, step StepDefinition) error {
+	if !l.registry.Exists(step.Type) {
+		return definitionError(path+".type", "step type '%s' is not registered", step.Type)
+	}
+
+	if err := l.registry.ValidateConfig(step.Type, step.Config); err != nil {
+		var configErr *registry.ConfigError
+		if errors.As(err, &configErr) {
+			return definitionError(path+".config."+configErr.Field, "%s", configErr.Message)
+		}
+		return definitionError(path+".config", "%v", err)
+	}
+	return nil
+}
+
+func (l *Loader) validateChoice(path string, choice *ChoiceDefinition) error {
+	if len(choice.When) == 0 {
+		return definitionError(path+".when", "choice must have at least one when branch")
+	}
+
+	for i, when := range choice.When {
+		whenPath := fmt.Sprintf("%s.when[%d]", path, i)
+		if err := validateCondition(whenPath+".condition", when.Condition); err != nil {
+			return err
+		}
+		if len(when.Steps) == 0 {
+			return definitionError(whenPath+".steps", "when branch must have at least one step")
+		}
+		if err := l.validateSteps(whenPath+".steps", when.Steps); err != nil {
+			return err
+		}
+	}
+
+	return l.validateSteps(path+".otherwise", choice.Otherwise)
+}
+
+func validateCondition(path string, condition ConditionDefinition) error {
+	if condition.Expr != "" {
+		if condition.Field != "" || condition.Operator != "" {
+			return definitionError(path+".expr", "expr cannot be combined with field and operator")
+		}
+		if _, err := expr.Compile(condition.Expr); err != nil {
+			return definitionError(path+".expr", "%v", err)
+		}
+		return nil
+	}
+	if condition.Field == "" {
+		return definitionError(path+".field", "condition field or expr is required")
+	}
+	if !core.IsSupportedOperator(condition.Operator) {
+		return definitionError(path+".operator", "unsupported operator '%s'", condition.Operator)
+	}
+	return nil
+}
+
+func (l *Loader) validateForEach(path string, forEach *ForEachDefinition) error {
+	if forEach.Source == "" {
+		return definitionError(path+".source", "source is required")
+	}
+	if len(forEach.Steps) == 0 {
+		return definitionError(path+".steps", "for_each block must have at least one step")
+	}
+	if forEach.Concurrency < 0 {
+		return definitionError(path+".concurrency", "concurrency must not be negative")
+	}
+	if forEach.OnItemFailure != "" {
+		if _, err := flow.ParseItemFailurePolicy(forEach.OnItemFailure); err != nil {
+			return definitionError(path+".on_item_failure", "%v", err)
+		}
+	}
+	return l.validateSteps(path+".steps", forEach.Steps)
+}
+
+func (l *Loader) buildForEach(path, name string, def *ForEachDefinition) (interfaces.Step, error) {
+	steps, err := l.buildSteps(path+".steps", def.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	forEach := flow.NewForEachStep(name, def.Source, steps...)
+	item, index := def.Item, def.Index
+	if item == "" {
+		item = "item"
+	}
+	if index == "" {
+		index = "index"
+	}
+	forEach.WithItemKey(item, index)
+	if def.Collect != "" {
+		forEach.WithCollect(def.Collect)
+	}
+	if def.Target != "" {
+		forEach.WithTarget(def.Target)
+	}
+	if def.Concurrency > 0 {
+		forEach.WithParallel(def.Concurrency)
+	}
+	if def.OnItemFailure != "" {
+		policy, _ := flow.ParseItemFailurePolicy(def.OnItemFailure)
+		forEach.WithItemFailure(policy)
+	}
+	return forEach, nil
+}
+
+// validateFailurePolicy checks a parallel block's on_failure and quorum
+func validateFailurePolicy(path string, parallel *ParallelDefinition) error {
+	policy := flow.FailWaitAll
+	if parallel.OnFailure != "" {
+		var err error
+		if policy, err = flow.ParseFailurePolicy(parallel.OnFailure); err != nil {
+			return definitionError(path+".on_failure", "%v", err)
+		}
+	}
+
+	switch {
+	case parallel.Quorum < 0 || parallel.Quorum > len(parallel.Steps):
+		return definitionError(path+".quorum", "quorum must be between 1 and the number of steps (%d)", len(parallel.Steps))
+	case parallel.Quorum > 0 && parallel.OnFailure != "" && policy != flow.FailQuorum:
+		return definitionError(path+".quorum", "quorum requires on_failure 'quorum'")
+	case policy == flow.FailQuorum && parallel.Quorum == 0:
+		return definitionError(path+".quorum", "on_failure 'quorum' requires a quorum")
+	}
+	return nil
+}
+
+func validateTransform(path string, transform *TransformDefinition) error {
+	if transform.Source == "" {
+		return definitionError(path+".source", "transform source is required")
+	}
+	if len(transform.Transformers) == 0 {
+		return definitionError(path+".transformers", "transform must have at least one transformer")
+	}
+	for i, t := range transform.Transformers {
+		if _, err := buildTransformer(fmt.Sprintf("%s.transformers[%d]", path, i), t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseDuration(path, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, definitionError(path, "invalid duration '%s'", value)
+	}
+	if d < 0 {
+		return 0, definitionError(path, "duration must not be negative")
+	}
+	return d, nil
+}
+
+// Building
+
+// buildStep compiles a validated step definition. Timeouts apply to each
+// attempt, so a retried step gets a fresh deadline per try.
+func (l *Loader) buildStep(path string, def StepDefinition) (interfaces.Step, error) {
+	var step interfaces.Step
+	var err error
+
+	switch {
+	case def.Type != "":
+		step, err = l.registry.Create(def.Type, def.Config)
+		if err != nil {
+			return nil, definitionError(path+".type", "failed to create step '%s': %v", def.Type, err)
+		}
+		step = flow.NewNamedStep(def.Name, step)
+	case def.Choice != nil:
+		step, err = l.buildChoice(path+".choice", def.Name, def.Choice)
+	case def.Parallel != nil:
+		var steps []interfaces.Step
+		steps, err = l.buildSteps(path+".parallel.steps", def.Parallel.Steps)
+		parallel := flow.NewParallelStep(def.Name, steps...)
+		if def.Parallel.Merge != "" {
+			policy, _ := flow.ParseMergePolicy(def.Parallel.Merge)
+			parallel.WithMergePolicy(policy)
+		}
+		if def.Parallel.OnFailure != "" {
+			policy, _ := flow.ParseFailurePolicy(def.Parallel.OnFailure)
+			parallel.WithFailurePolicy(policy)
+		}
+		if def.Parallel.Quorum > 0 {
+			parallel.WithQuorum(def.Parallel.Quorum)
+		}
+		parallel.WithMaxConcurrency(def.Parallel.MaxConcurrency)
+		step = parallel
+	case def.ForEach != nil:
+		step, err = l.buildForEach(path+".for_each", def.Name, def.ForEach)
+	case def.Scope != nil:
+		var steps []interfaces.Step
+		steps, err = l.buildSteps(path+".scope.steps", def.Scope.Steps)
+		step = flow.NewScopeStep(def.Name, steps...).
+			Export(def.Scope.Export...).
+			WithExports(def.Scope.ExportAs).
+			WithNamespace(def.Scope.Namespace)
+	case def.Transform != nil:
+		step, err = buildTransformStep(path+".transform", def.Name, def.Transform)
+	default:
+		delay, _ := time.ParseDuration(def.Delay)
+		step = flow.NewDelayStep(def.Name, delay)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if def.Timeout != "" {
+		timeout, _ := time.ParseDuration(def.Timeout)
+		step = flow.NewTimeoutStep(def.Name, step, timeout)
+	}
+
+	if def.Retry != nil {
+		var delay time.Duration
+		if def.Retry.Delay != "" {
+			delay, _ = time.ParseDuration(def.Retry.Delay)
+		}
+		step = flow.NewRetryStep(def.Name, step, def.Retry.MaxRetries, delay)
+	}
+
+	return step, nil
+}
+
+func (l *Loader) buildSteps(path string, defs []StepDefinition) ([]interfaces.Step, error) {
+	steps := make([]interfaces.Step, 0, len(defs))
+	for i, def := range defs {
+		step, err := l.buildStep(fmt.Sprintf("%s[%d]", path, i), def)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (l *Loader) buildChoice(path, name string, def *ChoiceDefinition) (interfaces.Step, error) {
+	branches := make([]flow.ChoiceBranch, 0, len(def.When))
+	for i, when := range def.When {
+		steps, err := l.buildSteps(fmt.Sprintf("%s.when[%d].steps", path, i), when.Steps)
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, flow.ChoiceBranch{
+			Condition: buildCondition(name, when.Condition),
+			Steps:     steps,
+		})
+	}
+
+	var otherwise interfaces.Step
+	if len(def.Otherwise) > 0 {
+		steps, err := l.buildSteps(path+".otherwise", def.Otherwise)
+		if err != nil {
+			return nil, err
+		}
+		otherwise = flow.NewSequentialStep("otherwise", steps...)
+	}
+
+	return flow.NewChoiceStep(name, branches, otherwise), nil
+}
+
+// buildCondition evaluates a condition definition with core.ConditionStep so
+// declarative and programmatic conditions share operator semantics.
+// Expressions were compiled during validation and cannot fail here.
+func buildCondition(choiceName string, def ConditionDefinition) func(interfaces.ExecutionContext) bool {
+	condition := core.NewConditionStep(choiceName+"_condition", def.Field, def.Operator, def.Value)
+	if def.Expr != "" {
+		condition.WithExpression(expr.MustCompile(def.Expr))
+	}
+	return func(ctx interfaces.ExecutionContext) bool {
+		result, err := condition.Evaluate(ctx)
+		return err == nil && result
+	}
+}
+
+func buildTransformStep(path, name string, def *TransformDefinition) (interfaces.Step, error) {
+	chain := transformers.NewTransformerChain(name)
+	for i, t := range def.Transformers {
+		transformer, err := buildTransformer(fmt.Sprintf("%s.transformers[%d]", path, i), t)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(transformer)
+	}
+
+	target := def.Target
+	if target == "" {
+		target = def.Source
+	}
+
+	return flow.NewTransformStep(name, func(ctx interfaces.ExecutionContext) error {
+		value, ok := utils.LookupNestedValue(def.Source, ctx)
+		if !ok {
+			return frameworkErrors.MissingField(def.Source)
+		}
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return frameworkErrors.TransformationFailed(name,
+				fmt.Sprintf("source '%s' is %T, expected an object", def.Source, value))
+		}
+
+		result, err := chain.Transform(data)
+		if err != nil {
+			return frameworkErrors.TransformationFailed(name, err.Error()).WithCause(err)
+		}
+		ctx.Set(target, result)
+		return nil
+	}), nil
+}
+
+// buildTransformer maps a transformer definition onto the transformers
+// package constructors
+func buildTransformer(path string, def TransformerDefinition) (transformers.Transformer, error) {
+	name := def.Name
+	if name == "" {
+		name = def.Type
+	}
+
+	requireFields := func() error {
+		if len(def.Fields) == 0 {
+			return definitionError(path+".fields", "transformer '%s' requires fields", def.Type)
+		}
+		return nil
+	}
+
+	switch def.Type {
+	case "fields":
+		if err := requireFields(); err != nil {
+			return nil, err
+		}
+		t := transformers.NewFieldTransformer(name, def.Fields)
+		if def.Prefix != "" {
+			t.WithPrefix(def.Prefix)
+		}
+		return t, nil
+	case "include":
+		if err := requireFields(); err != nil {
+			return nil, err
+		}
+		return transformers.IncludeFieldsTransformer(def.Fields...), nil
+	case "exclude":
+		if err := requireFields(); err != nil {
+			return nil, err
+		}
+		return transformers.ExcludeFieldsTransformer(def.Fields...), nil
+	case "mobile":
+		if err := requireFields(); err != nil {
+			return nil, err
+		}
+		return transformers.NewMobileTransformer(def.Fields), nil
+	case "rename":
+		if len(def.Mapping) == 0 {
+			return nil, definitionError(path+".mapping", "transformer 'rename' requires mapping")
+		}
+		return transformers.RenameFieldsTransformer(def.Mapping), nil
+	case "add":
+		if len(def.Values) == 0 {
+			return nil, definitionError(path+".values", "transformer 'add' requires values")
+		}
+		return transformers.AddFieldsTransformer(def.Values), nil
+	case "flatten":
+		t := transformers.NewFlattenTransformer(name, def.Prefix)
+		if def.MaxDepth > 0 {
+			t.WithMaxDepth(def.MaxDepth)
+		}
+		return t, nil
+	case "prefix":
+		if def.Prefix == "" {
+			return nil, definitionError(path+".prefix", "transformer 'prefix' requires prefix")
+		}
+		return transformers.NewPrefixTransformer(name, def.Prefix), nil
+	case "":
+		return nil, definitionError(path+".type", "transformer type is required")
+	default:
+		return nil, definitionError(path+".type", "unknown transformer type '%s'", def.Type)
+	}
+}
@@ -0,0 +1,455 @@
+package definition
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+// setStep stores a configured value under a configured key
+type setStep struct {
+	name  string
+	key   string
+	value interface{}
+	runs  *int32
+	sleep time.Duration
+}
+
+func (s *setStep) Run(ctx interfaces.ExecutionContext) error {
+	if s.runs != nil {
+		atomic.AddInt32(s.runs, 1)
+	}
+	if s.sleep > 0 {
+		select {
+		case <-time.After(s.sleep):
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+	}
+	ctx.Set(s.key, s.value)
+	return nil
+}
+
+func (s *setStep) Name() string        { return s.name }
+func (s *setStep) Description() string { return "Set a value" }
+
+func newTestRegistry(t *testing.T, runs *int32) *registry.StepRegistry {
+	t.Helper()
+	reg := registry.NewStepRegistry()
+	err := reg.Register(&registry.StepInfo{
+		Name: "set",
+		ConfigSpec: map[string]interface{}{
+			"Key":   map[string]interface{}{"type": "string", "required": true, "json_name": "key"},
+			"Value": map[string]interface{}{"type": "interface {}", "required": false, "json_name": "value,omitempty"},
+			"Sleep": map[string]interface{}{"type": "time.Duration", "required": false, "json_name": "sleep,omitempty", "default": "0s"},
+		},
+		Factory: func(config map[string]interface{}) (interfaces.Step, error) {
+			step := &setStep{name: "set", runs: runs}
+			step.key, _ = config["key"].(string)
+			step.value = config["value"]
+			if sleep, ok := config["sleep"].(string); ok {
+				step.sleep, _ = time.ParseDuration(sleep)
+			}
+			return step, nil
+		},
+	})
+	require.NoError(t, err)
+	return reg
+}
+
+func newTestContext() *flow.Context {
+	return flow.NewContext().WithLogger(zap.NewNop())
+}
+
+func requireDefinitionError(t *testing.T, err error, path string) *errors.FrameworkError {
+	t.Helper()
+	var fwErr *errors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, errors.ErrorTypeConfiguration, fwErr.Type)
+	assert.Equal(t, path, fwErr.Context["path"])
+	return fwErr
+}
+
+func TestNewLoader_DefaultsToGlobalRegistry(t *testing.T) {
+	assert.Same(t, registry.GetGlobalRegistry(), NewLoader(nil).registry)
+}
+
+func TestLoader_LoadYAML(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadYAML([]byte(`
+name: profile
+description: Build a profile
+timeout: 2s
+steps:
+  - name: load
+    type: set
+    config:
+      key: user
+      value:
+        id: 42
+        name: Ada
+        password: secret
+  - name: shape
+    transform:
+      source: user
+      target: profile
+      transformers:
+        - type: exclude
+          fields: [password]
+        - type: rename
+          mapping:
+            name: display_name
+  - name: route
+    choice:
+      when:
+        - condition: {field: user.id, operator: gt, value: 10}
+          steps:
+            - name: mark_big
+              type: set
+              config: {key: tier, value: big}
+      otherwise:
+        - name: mark_small
+          type: set
+          config: {key: tier, value: small}
+  - name: fanout
+    parallel:
+      steps:
+        - name: a
+          type: set
+          config: {key: a, value: 1}
+        - name: b
+          type: set
+          config: {key: b, value: 2}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "profile", f.Name())
+	assert.Equal(t, "Build a profile", f.Description())
+	require.Len(t, f.Steps(), 4)
+	assert.Equal(t, "load", f.Steps()[0].Name())
+
+	ctx := newTestContext()
+	_, err = f.Execute(ctx)
+	require.NoError(t, err)
+
+	profile, err := ctx.GetMap("profile")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", profile["display_name"])
+	assert.NotContains(t, profile, "password")
+
+	tier, _ := ctx.GetString("tier")
+	assert.Equal(t, "big", tier)
+	assert.True(t, ctx.Has("a"))
+	assert.True(t, ctx.Has("b"))
+}
+
+func TestLoader_ChoiceExpr(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadYAML([]byte(`
+name: route
+steps:
+  - name: route
+    choice:
+      when:
+        - condition:
+            expr: user.tier == "gold" && len(cart.items) > 1
+          steps:
+            - name: mark_vip
+              type: set
+              config: {key: lane, value: vip}
+      otherwise:
+        - name: mark_standard
+          type: set
+          config: {key: lane, value: standard}
+`))
+	require.NoError(t, err)
+
+	ctx := newTestContext()
+	ctx.Set("user", map[string]interface{}{"tier": "gold"})
+	ctx.Set("cart", map[string]interface{}{"items": []interface{}{"a", "b"}})
+	_, err = f.Execute(ctx)
+	require.NoError(t, err)
+	lane, _ := ctx.GetString("lane")
+	assert.Equal(t, "vip", lane)
+
+	ctx = newTestContext()
+	ctx.Set("user", map[string]interface{}{"tier": "gold"})
+	_, err = f.Execute(ctx)
+	require.NoError(t, err)
+	lane, _ = ctx.GetString("lane")
+	assert.Equal(t, "standard", lane)
+}
+
+func TestLoader_Scope(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadYAML([]byte(`
+name: scoped
+write_check: fail
+steps:
+  - name: user
+    scope:
+      namespace: user
+      steps:
+        - {name: load_user, type: set, config: {key: response, value: {name: Ada}}}
+  - name: orders
+    scope:
+      export: [total]
+      export_as: {response.count: order_count}
+      steps:
+        - {name: load_orders, type: set, config: {key: response, value: {count: 2}}}
+        - {name: load_total, type: set, config: {key: total, value: 30}}
+`))
+	require.NoError(t, err)
+
+	ctx := newTestContext()
+	result, err := f.Execute(ctx)
+	require.NoError(t, err, "scoped writes of the same key are not overwrites")
+	assert.Empty(t, result.Overwrites)
+
+	assert.False(t, ctx.Has("response"))
+	user, err := ctx.GetMap("user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Ada"}, user["response"])
+	count, err := ctx.GetInt("order_count")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, ctx.Has("total"))
+}
+
+func TestLoader_WriteCheck(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+	flowYAML := `
+name: clobber
+write_check: fail
+%s
+steps:
+  - {name: first, type: set, config: {key: response, value: 1}}
+  - {name: second, type: set, config: {key: response, value: 2}}
+`
+
+	f, err := loader.LoadYAML([]byte(fmt.Sprintf(flowYAML, "")))
+	require.NoError(t, err)
+	result, err := f.Execute(newTestContext())
+	require.Error(t, err)
+	assert.Equal(t, "second", result.FailedStep)
+
+	f, err = loader.LoadYAML([]byte(fmt.Sprintf(flowYAML, "allow_overwrite: [response]\nhistory: true")))
+	require.NoError(t, err)
+	result, err = f.Execute(newTestContext())
+	assert.NoError(t, err)
+	require.NotNil(t, result.History)
+	assert.Len(t, result.History.Diff("second"), 1)
+}
+
+func TestLoader_LoadJSON(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadJSON([]byte(`{
+		"name": "json_flow",
+		"steps": [{"name": "load", "type": "set", "config": {"key": "greeting", "value": "hi"}}]
+	}`))
+	require.NoError(t, err)
+
+	ctx := newTestContext()
+	_, err = f.Execute(ctx)
+	require.NoError(t, err)
+	greeting, _ := ctx.GetString("greeting")
+	assert.Equal(t, "hi", greeting)
+}
+
+func TestLoader_StepTimeoutAndRetry(t *testing.T) {
+	var runs int32
+	loader := NewLoader(newTestRegistry(t, &runs))
+
+	f, err := loader.LoadYAML([]byte(`
+name: slow
+steps:
+  - name: slow_step
+    type: set
+    config: {key: done, value: true, sleep: 1s}
+    timeout: 20ms
+    retry:
+      max_retries: 1
+      delay: 1ms
+`))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = f.Execute(newTestContext())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Contains(t, err.Error(), "timed out")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs), "timeout should apply to each attempt")
+}
+
+func TestLoader_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		path string
+	}{
+		{"missing flow name", "steps: [{name: a, delay: 1ms}]", "name"},
+		{"no steps", "name: f", "steps"},
+		{"bad flow timeout", "name: f\ntimeout: soon\nsteps: [{name: a, delay: 1ms}]", "timeout"},
+		{"missing step name", "name: f\nsteps: [{delay: 1ms}]", "steps[0].name"},
+		{"duplicate step name", "name: f\nsteps: [{name: a, delay: 1ms}, {name: a, delay: 1ms}]", "steps[1].name"},
+		{"no step kind", "name: f\nsteps: [{name: a}]", "steps[0]"},
+		{"two step kinds", "name: f\nsteps: [{name: a, delay: 1ms, type: set}]", "steps[0]"},
+		{"unknown type", "name: f\nsteps: [{name: a, type: nope}]", "steps[0].type"},
+		{"missing config field", "name: f\nsteps: [{name: a, type: set, config: {}}]", "steps[0].config.key"},
+		{"wrong config type", "name: f\nsteps: [{name: a, type: set, config: {key: 1}}]", "steps[0].config.key"},
+		{"unknown config field", "name: f\nsteps: [{name: a, type: set, config: {key: k, kee: 1}}]", "steps[0].config.kee"},
+		{"bad step timeout", "name: f\nsteps: [{name: a, delay: 1ms, timeout: x}]", "steps[0].timeout"},
+		{"bad retry delay", "name: f\nsteps: [{name: a, delay: 1ms, retry: {max_retries: 1, delay: x}}]", "steps[0].retry.delay"},
+		{
+			"nested parallel config",
+			"name: f\nsteps:\n  - name: p\n    parallel:\n      steps:\n        - {name: a, type: set, config: {key: k}}\n        - {name: b, type: set, config: {}}",
+			"steps[0].parallel.steps[1].config.key",
+		},
+		{
+			"unknown merge policy",
+			"name: f\nsteps:\n  - name: p\n    parallel:\n      merge: random\n      steps: [{name: a, delay: 1ms}]",
+			"steps[0].parallel.merge",
+		},
+		{
+			"for_each without source",
+			"name: f\nsteps:\n  - name: e\n    for_each:\n      steps: [{name: a, delay: 1ms}]",
+			"steps[0].for_each.source",
+		},
+		{
+			"unknown item failure policy",
+			"name: f\nsteps:\n  - name: e\n    for_each:\n      source: items\n      on_item_failure: retry\n      steps: [{name: a, delay: 1ms}]",
+			"steps[0].for_each.on_item_failure",
+		},
+		{
+			"unknown failure policy",
+			"name: f\nsteps:\n  - name: p\n    parallel:\n      on_failure: retry\n      steps: [{name: a, delay: 1ms}]",
+			"steps[0].parallel.on_failure",
+		},
+		{
+			"quorum larger than parallel block",
+			"name: f\nsteps:\n  - name: p\n    parallel:\n      on_failure: quorum\n      quorum: 2\n      steps: [{name: a, delay: 1ms}]",
+			"steps[0].parallel.quorum",
+		},
+		{
+			"bad choice operator",
+			"name: f\nsteps:\n  - name: c\n    choice:\n      when:\n        - condition: {field: x, operator: approx}\n          steps: [{name: a, delay: 1ms}]",
+			"steps[0].choice.when[0].condition.operator",
+		},
+		{
+			"choice condition without field or expr",
+			"name: f\nsteps:\n  - name: c\n    choice:\n      when:\n        - condition: {operator: exists}\n          steps: [{name: a, delay: 1ms}]",
+			"steps[0].choice.when[0].condition.field",
+		},
+		{
+			"bad choice expr",
+			"name: f\nsteps:\n  - name: c\n    choice:\n      when:\n        - condition: {expr: 'user.id >'}\n          steps: [{name: a, delay: 1ms}]",
+			"steps[0].choice.when[0].condition.expr",
+		},
+		{
+			"choice expr with field",
+			"name: f\nsteps:\n  - name: c\n    choice:\n      when:\n        - condition: {expr: 'user.id > 1', field: user.id}\n          steps: [{name: a, delay: 1ms}]",
+			"steps[0].choice.when[0].condition.expr",
+		},
+		{
+			"unknown write check",
+			"name: f\nwrite_check: loud\nsteps: [{name: a, delay: 1ms}]",
+			"write_check",
+		},
+		{
+			"empty scope",
+			"name: f\nsteps:\n  - name: s\n    scope: {namespace: user}",
+			"steps[0].scope.steps",
+		},
+		{
+			"bad otherwise step",
+			"name: f\nsteps:\n  - name: c\n    choice:\n      when:\n        - condition: {field: x, operator: exists}\n          steps: [{name: a, delay: 1ms}]\n      otherwise: [{name: b, type: nope}]",
+			"steps[0].choice.otherwise[0].type",
+		},
+		{
+			"unknown transformer",
+			"name: f\nsteps:\n  - name: t\n    transform:\n      source: user\n      transformers: [{type: shuffle}]",
+			"steps[0].transform.transformers[0].type",
+		},
+		{
+			"transformer missing fields",
+			"name: f\nsteps:\n  - name: t\n    transform:\n      source: user\n      transformers: [{type: include}]",
+			"steps[0].transform.transformers[0].fields",
+		},
+	}
+
+	loader := NewLoader(newTestRegistry(t, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loader.LoadYAML([]byte(tt.yaml))
+			fwErr := requireDefinitionError(t, err, tt.path)
+			assert.NotEmpty(t, fwErr.Details)
+		})
+	}
+}
+
+func TestLoader_ConfigErrorDetails(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	_, err := loader.LoadYAML([]byte("name: f\nsteps: [{name: a, type: set, config: {key: k, sleep: later}}]"))
+	fwErr := requireDefinitionError(t, err, "steps[0].config.sleep")
+	assert.Equal(t, `must be a duration, got "later"`, fwErr.Details)
+	assert.Equal(t, "Invalid flow definition at steps[0].config.sleep", fwErr.Message)
+}
+
+func TestLoader_TransformMissingSource(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadYAML([]byte(`
+name: f
+steps:
+  - name: shape
+    transform:
+      source: user
+      transformers: [{type: include, fields: [id]}]
+`))
+	require.NoError(t, err)
+
+	_, err = f.Execute(newTestContext())
+	assert.Error(t, err)
+}
+
+func TestLoader_ForEach(t *testing.T) {
+	loader := NewLoader(newTestRegistry(t, nil))
+
+	f, err := loader.LoadYAML([]byte(`
+name: f
+steps:
+  - name: lines
+    for_each:
+      source: order.items
+      collect: seen
+      target: results
+      concurrency: 2
+      on_item_failure: skip
+      steps:
+        - {name: mark, type: set, config: {key: seen, value: true}}
+`))
+	require.NoError(t, err)
+
+	ctx := newTestContext()
+	ctx.Set("order", map[string]interface{}{"items": []interface{}{"a", "b", "c"}})
+	_, err = f.Execute(ctx)
+	require.NoError(t, err)
+
+	results, _ := ctx.Get("results")
+	assert.Equal(t, []interface{}{true, true, true}, results)
+	assert.False(t, ctx.Has("seen"), "item writes should stay in the item context")
+}
@@ -201,6 +201,10 @@ const (
 	// Internal errors
 	ErrCodeInternalFailure = "INTERNAL_FAILURE"
 	ErrCodeUnexpectedError = "UNEXPECTED_ERROR"
+	ErrCodeMergeConflict   = "MERGE_CONFLICT"
+	ErrCodeDuplicateWrite  = "DUPLICATE_WRITE"
+	ErrCodeReadOnlyContext = "READ_ONLY_CONTEXT"
+	ErrCodeLoopExhausted   = "LOOP_EXHAUSTED"
 
 	// External errors
 	ErrCodeExternalServiceUnavailable = "EXTERNAL_SERVICE_UNAVAILABLE"
@@ -0,0 +1,565 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// node is an element of a compiled expression
+type node interface {
+	eval(env Env) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(Env) (interface{}, error) {
+	return n.value, nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(env Env) (interface{}, error) {
+	list := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = value
+	}
+	return list, nil
+}
+
+// nameNode is a top-level name resolved through the Env
+type nameNode struct {
+	name string
+}
+
+func (n *nameNode) eval(env Env) (interface{}, error) {
+	value, _ := env.Get(n.name)
+	return value, nil
+}
+
+// indexNode is a field access (a.b) or an index (a[0], a["b"])
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(env Env) (interface{}, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return member(target, index), nil
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(env Env) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(value), nil
+}
+
+type negateNode struct {
+	operand node
+}
+
+func (n *negateNode) eval(env Env) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	number, ok := toNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %s", describe(value))
+	}
+	return -number, nil
+}
+
+// logicalNode is a short-circuiting && or ||
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(left) != n.and {
+		return !n.and, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return order(n.op, left, right)
+	case "in":
+		return contains(right, left)
+	case "not in":
+		found, err := contains(right, left)
+		return !found, err
+	case "+":
+		return add(left, right)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(env Env) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	result, err := n.fn.call(env, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", n.name, err)
+	}
+	return result, nil
+}
+
+// function is a built-in function callable from expressions
+type function struct {
+	arity int
+	call  func(env Env, args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"len":         {1, fnLen},
+	"header":      {1, fnHeader},
+	"lower":       {1, stringFunc(strings.ToLower)},
+	"upper":       {1, stringFunc(strings.ToUpper)},
+	"trim":        {1, stringFunc(strings.TrimSpace)},
+	"contains":    {2, fnContains},
+	"starts_with": {2, stringPredicate(strings.HasPrefix)},
+	"ends_with":   {2, stringPredicate(strings.HasSuffix)},
+	"string":      {1, func(_ Env, args []interface{}) (interface{}, error) { return toString(args[0]), nil }},
+	"number":      {1, fnNumber},
+}
+
+// fnLen returns the length of a string (in characters), list or map; null
+// has length 0
+func fnLen(_ Env, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	}
+	rv := reflect.ValueOf(args[0])
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(rv.Len()), nil
+	}
+	return nil, fmt.Errorf("%s has no length", describe(args[0]))
+}
+
+// fnHeader returns a request header from the "request_headers" map of the
+// environment, where flow.NewContextFromGin stores the incoming request
+// headers, or else from "headers". The name is matched case-insensitively.
+// Missing headers are null.
+func fnHeader(env Env, args []interface{}) (interface{}, error) {
+	name := toString(args[0])
+	headers, ok := env.Get("request_headers")
+	if !ok {
+		headers, _ = env.Get("headers")
+	}
+
+	var value interface{}
+	switch h := headers.(type) {
+	case http.Header:
+		if values := h.Values(name); len(values) > 0 {
+			value = values[0]
+		}
+	case map[string][]string:
+		for key, values := range h {
+			if strings.EqualFold(key, name) && len(values) > 0 {
+				value = values[0]
+			}
+		}
+	case map[string]string:
+		for key, v := range h {
+			if strings.EqualFold(key, name) {
+				value = v
+			}
+		}
+	case map[string]interface{}:
+		for key, v := range h {
+			if strings.EqualFold(key, name) {
+				value = v
+			}
+		}
+	}
+
+	// Multi-valued headers decoded from JSON arrive as lists
+	if list, ok := value.([]interface{}); ok {
+		value = nil
+		if len(list) > 0 {
+			value = list[0]
+		}
+	}
+	if value == nil || value == "" {
+		return nil, nil
+	}
+	return toString(value), nil
+}
+
+func fnContains(_ Env, args []interface{}) (interface{}, error) {
+	return contains(args[0], args[1])
+}
+
+func fnNumber(_ Env, args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	number, ok := numericOperand(args[0])
+	if !ok {
+		return nil, fmt.Errorf("%s is not a number", describe(args[0]))
+	}
+	return number, nil
+}
+
+func stringFunc(fn func(string) string) func(Env, []interface{}) (interface{}, error) {
+	return func(_ Env, args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return nil, nil
+		}
+		return fn(toString(args[0])), nil
+	}
+}
+
+func stringPredicate(fn func(s, part string) bool) func(Env, []interface{}) (interface{}, error) {
+	return func(_ Env, args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return false, nil
+		}
+		return fn(toString(args[0]), toString(args[1])), nil
+	}
+}
+
+// member returns target.index for maps and structs, or target[index] for
+// lists; anything that does not resolve is null
+func member(target, index interface{}) interface{} {
+	switch t := target.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return t[toString(index)]
+	case map[string]string:
+		if value, ok := t[toString(index)]; ok {
+			return value
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(target)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		n, ok := toNumber(index)
+		if !ok || n != math.Trunc(n) {
+			return nil
+		}
+		i := int(n)
+		if i < 0 {
+			i += rv.Len()
+		}
+		if i < 0 || i >= rv.Len() {
+			return nil
+		}
+		return rv.Index(i).Interface()
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		value := rv.MapIndex(reflect.ValueOf(toString(index)).Convert(rv.Type().Key()))
+		if !value.IsValid() {
+			return nil
+		}
+		return value.Interface()
+
+	case reflect.Struct:
+		name := toString(index)
+		field := rv.FieldByNameFunc(func(field string) bool { return strings.EqualFold(field, name) })
+		if !field.IsValid() || !field.CanInterface() {
+			return nil
+		}
+		return field.Interface()
+	}
+	return nil
+}
+
+// truthy reports whether a value counts as true in a condition
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if number, ok := toNumber(value); ok {
+		return number != 0
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Ptr:
+		return !rv.IsNil()
+	}
+	return true
+}
+
+// equal compares two values. Numbers compare by value across Go types, and
+// a numeric string equals the number it spells, as in core.ConditionStep.
+func equal(left, right interface{}) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if l, ok := toNumber(left); ok {
+		if r, ok := toNumber(right); ok {
+			return l == r
+		}
+	}
+	if l, ok := numericOperand(left); ok {
+		if r, ok := numericOperand(right); ok {
+			return l == r
+		}
+	}
+	if l, ok := left.(string); ok {
+		r, ok := right.(string)
+		return ok && l == r
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// order evaluates <, <=, > and >= over numbers or strings
+func order(op string, left, right interface{}) (interface{}, error) {
+	var cmp int
+	l, lok := numericOperand(left)
+	r, rok := numericOperand(right)
+	ls, lstr := left.(string)
+	rs, rstr := right.(string)
+	switch {
+	case lok && rok && !(lstr && rstr):
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case lstr && rstr:
+		cmp = strings.Compare(ls, rs)
+	case left == nil || right == nil:
+		// Missing values never satisfy an ordering
+		return false, nil
+	default:
+		return nil, fmt.Errorf("cannot compare %s %s %s", describe(left), op, describe(right))
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// contains reports whether item is in a list, a key of a map, or a
+// substring of a string
+func contains(collection, item interface{}) (bool, error) {
+	switch c := collection.(type) {
+	case nil:
+		return false, nil
+	case string:
+		return strings.Contains(c, toString(item)), nil
+	}
+
+	rv := reflect.ValueOf(collection)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if equal(rv.Index(i).Interface(), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		return member(collection, item) != nil, nil
+	}
+	return false, fmt.Errorf("cannot look for a value in %s", describe(collection))
+}
+
+// add sums numbers and concatenates strings
+func add(left, right interface{}) (interface{}, error) {
+	_, lstr := left.(string)
+	_, rstr := right.(string)
+	if lstr || rstr {
+		return toString(left) + toString(right), nil
+	}
+	return arithmetic("+", left, right)
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	l, lok := numericOperand(left)
+	r, rok := numericOperand(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", op, describe(left), describe(right))
+	}
+
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+// toNumber converts Go numeric types to float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// numericOperand is toNumber that also accepts numeric strings, e.g. query
+// parameters and headers
+func numericOperand(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return f, err == nil
+	}
+	return toNumber(value)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// describe names a value's type for error messages
+func describe(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	if _, ok := toNumber(value); ok {
+		return "number"
+	}
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map:
+		return "map"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,140 @@
+// Package expr implements a small, side-effect free expression language for
+// conditions over the execution context, e.g.
+//
+//	user.tier == "gold" && len(cart.items) > 3 || header("X-Platform") in ["ios", "android"]
+//
+// Expressions are compiled once and can then be evaluated concurrently
+// against any number of contexts. They support boolean logic (&&, ||, !, and
+// their keyword forms and, or, not), arithmetic (+, -, *, /, %), comparisons,
+// membership with in / not in, list literals, nested paths with dot and
+// index access, and a fixed set of functions (see Functions). Paths that do
+// not resolve evaluate to null instead of failing.
+package expr
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// Env resolves the top-level names of an expression. An
+// interfaces.ExecutionContext is an Env; MapEnv adapts plain maps.
+type Env interface {
+	Get(key string) (interface{}, bool)
+}
+
+// MapEnv is an Env over a map, e.g. the data seen by a transformer or
+// validator
+type MapEnv map[string]interface{}
+
+func (m MapEnv) Get(key string) (interface{}, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Expression is a compiled expression. It is safe for concurrent use.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile parses source into an Expression. Syntax errors, unknown
+// functions and wrong argument counts are reported as a *SyntaxError.
+func Compile(source string) (*Expression, error) {
+	p, err := newParser(source)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustCompile is like Compile but panics on error. It is meant for
+// expressions written as literals in code.
+func MustCompile(source string) *Expression {
+	e, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against env. Numbers are returned as
+// float64.
+func (e *Expression) Eval(env Env) (interface{}, error) {
+	value, err := e.root.eval(env)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", e.source, err)
+	}
+	return value, nil
+}
+
+// Bool evaluates the expression and reports whether the result is truthy:
+// true, a non-zero number, or a non-empty string, list or map
+func (e *Expression) Bool(env Env) (bool, error) {
+	value, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// Condition adapts the expression to the condition functions taken by
+// flow.ChoiceBuilder.When and similar APIs. Evaluation errors are logged
+// and count as false.
+func (e *Expression) Condition() func(interfaces.ExecutionContext) bool {
+	return func(ctx interfaces.ExecutionContext) bool {
+		result, err := e.Bool(ctx)
+		if err != nil {
+			ctx.Logger().Warn("Condition evaluation failed",
+				zap.String("expression", e.source),
+				zap.Error(err))
+			return false
+		}
+		return result
+	}
+}
+
+// MapCondition adapts the expression to the map conditions taken by
+// transformers.ConditionalTransformerChain.When and
+// validators.ConditionalRequiredValidator.When. Evaluation errors count as
+// false.
+func (e *Expression) MapCondition() func(map[string]interface{}) bool {
+	return func(data map[string]interface{}) bool {
+		result, err := e.Bool(MapEnv(data))
+		return err == nil && result
+	}
+}
+
+// SyntaxError reports an expression that does not compile. Pos is the byte
+// offset of the problem in Expr.
+type SyntaxError struct {
+	Expr    string
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("expression %q: %s at position %d", e.Expr, e.Message, e.Pos)
+}
+
+// Functions returns the names of the functions expressions can call
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,188 @@
+package expr
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnv() MapEnv {
+	return MapEnv{
+		"user": map[string]interface{}{
+			"tier":  "gold",
+			"age":   32,
+			"name":  "  Ada Lovelace ",
+			"roles": []interface{}{"admin", "beta"},
+		},
+		"cart": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"sku": "a", "price": 10.5},
+				map[string]interface{}{"sku": "b", "price": 4.5},
+				map[string]interface{}{"sku": "c", "price": 1},
+				map[string]interface{}{"sku": "d", "price": 2},
+			},
+		},
+		"limits":  map[string]int{"max": 5},
+		"headers": map[string]interface{}{"X-Platform": "ios", "X-App-Version": "3"},
+		"query":   map[string]string{"page": "2"},
+		"flag":    true,
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		// Literals and arithmetic
+		{`1 + 2 * 3`, 7.0},
+		{`(1 + 2) * 3`, 9.0},
+		{`10 / 4 - 1`, 1.5},
+		{`7 % 3`, 1.0},
+		{`-user.age + 2`, -30.0},
+		{`"a" + 'b' + 1`, "ab1"},
+		{`[1, "two", null]`, []interface{}{1.0, "two", nil}},
+
+		// Paths
+		{`user.tier`, "gold"},
+		{`cart.items[1].sku`, "b"},
+		{`cart.items.0.sku`, "a"},
+		{`cart.items[-1]["sku"]`, "d"},
+		{`limits.max`, 5},
+		{`user.missing.deeper`, nil},
+		{`nothing`, nil},
+
+		// Comparisons
+		{`user.age >= 18`, true},
+		{`user.age == "32"`, true},
+		{`query.page > 1`, true},
+		{`user.tier != "gold"`, false},
+		{`"abc" < "abd"`, true},
+		{`nothing == null`, true},
+		{`nothing > 3`, false},
+
+		// Boolean logic
+		{`flag && !false`, true},
+		{`not flag or user.tier == "gold"`, true},
+		{`nothing && missing.call`, false},
+
+		// Membership
+		{`"beta" in user.roles`, true},
+		{`"root" not in user.roles`, true},
+		{`"tier" in user`, true},
+		{`"Love" in user.name`, true},
+		{`2 in [1, 2, 3]`, true},
+		{`nothing in [1, 2]`, false},
+
+		// Functions
+		{`len(cart.items)`, 4.0},
+		{`len("héllo")`, 5.0},
+		{`len(nothing)`, 0.0},
+		{`header("x-platform")`, "ios"},
+		{`header("X-Missing")`, nil},
+		{`number(header("X-App-Version")) >= 3`, true},
+		{`upper(user.tier)`, "GOLD"},
+		{`lower(trim(user.name))`, "ada lovelace"},
+		{`starts_with(user.tier, "go") && ends_with(user.tier, "ld")`, true},
+		{`contains(user.roles, "admin") && contains(user.tier, "ol")`, true},
+		{`string(user.age) + "y"`, "32y"},
+	}
+
+	env := testEnv()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Compile(tt.expr)
+			require.NoError(t, err)
+			got, err := e.Eval(env)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBool_RequestExample(t *testing.T) {
+	e := MustCompile(`user.tier == "gold" && len(cart.items) > 3 || header("X-Platform") in ["ios","android"]`)
+
+	env := testEnv()
+	ok, err := e.Bool(env)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	env["user"] = map[string]interface{}{"tier": "silver"}
+	env["headers"] = http.Header{"X-Platform": []string{"android"}}
+	ok, err = e.Bool(env)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	env["headers"] = map[string]string{"x-platform": "web"}
+	ok, err = e.Bool(env)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestHeader_PrefersRequestHeaders(t *testing.T) {
+	e := MustCompile(`header("Content-Type")`)
+	env := MapEnv{
+		"request_headers": http.Header{"Content-Type": []string{"application/json"}},
+		"headers":         http.Header{"Content-Type": []string{"text/html"}},
+	}
+
+	got, err := e.Eval(env)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", got)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{``, 0},
+		{`user.tier ==`, 12},
+		{`(1 + 2`, 6},
+		{`a == b == c`, 7},
+		{`"unterminated`, 0},
+		{`user.tier = "gold"`, 10},
+		{`exec("rm -rf /")`, 0},
+		{`len(a, b)`, 0},
+		{`a.`, 2},
+		{`[1, 2`, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "Compile(%q) error = %v", tt.expr, err)
+			assert.Equal(t, tt.pos, syntaxErr.Pos, syntaxErr.Error())
+		})
+	}
+
+	assert.Panics(t, func() { MustCompile(`a &&`) })
+}
+
+func TestEval_Errors(t *testing.T) {
+	for _, expr := range []string{
+		`1 / 0`,
+		`user.tier * 2`,
+		`user.roles < 3`,
+		`"a" in 3`,
+		`len(3)`,
+		`-user.tier`,
+	} {
+		_, err := MustCompile(expr).Eval(testEnv())
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestMapCondition(t *testing.T) {
+	condition := MustCompile(`status == "active" && len(items) > 0`).MapCondition()
+	assert.True(t, condition(map[string]interface{}{"status": "active", "items": []int{1}}))
+	assert.False(t, condition(map[string]interface{}{"status": "active"}))
+
+	// Evaluation errors count as false
+	assert.False(t, MustCompile(`status / 2`).MapCondition()(map[string]interface{}{"status": "x"}))
+}
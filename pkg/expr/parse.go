@@ -0,0 +1,451 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies lexer tokens
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenKeyword
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{} // decoded literal for numbers and strings
+	pos   int
+}
+
+var keywords = map[string]bool{
+	"true": true, "false": true, "null": true, "nil": true,
+	"and": true, "or": true, "not": true, "in": true,
+}
+
+// operators lists the operator tokens, longest first so "==" wins over "="
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"<", ">", "!", "+", "-", "*", "/", "%", "(", ")", "[", "]", ",", ".",
+}
+
+// tokenize splits source into tokens
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(source); {
+		c := rune(source[pos])
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case isDigit(source[pos]):
+			start := pos
+			pos = scanDigits(source, pos)
+			// A dot starts a fraction only when a digit follows, so that
+			// paths such as items.0.name still tokenize
+			if pos+1 < len(source) && source[pos] == '.' && isDigit(source[pos+1]) {
+				pos = scanDigits(source, pos+1)
+			}
+			n, err := strconv.ParseFloat(source[start:pos], 64)
+			if err != nil {
+				return nil, &SyntaxError{Expr: source, Pos: start, Message: fmt.Sprintf("invalid number '%s'", source[start:pos])}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:pos], value: n, pos: start})
+
+		case c == '"' || c == '\'':
+			s, end, err := scanString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: source[pos:end], value: s, pos: pos})
+			pos = end
+
+		case c == '_' || unicode.IsLetter(c):
+			start := pos
+			for pos < len(source) && (source[pos] == '_' || unicode.IsLetter(rune(source[pos])) || unicode.IsDigit(rune(source[pos]))) {
+				pos++
+			}
+			word := source[start:pos]
+			kind := tokenIdent
+			if keywords[word] {
+				kind = tokenKeyword
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: start})
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[pos:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Expr: source, Pos: pos, Message: fmt.Sprintf("unexpected character '%c'", c)}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// scanDigits returns the offset of the first non-digit at or after pos
+func scanDigits(source string, pos int) int {
+	for pos < len(source) && isDigit(source[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// scanString decodes the quoted string starting at start and returns it
+// with the offset just past the closing quote
+func scanString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+	for pos := start + 1; pos < len(source); pos++ {
+		c := source[pos]
+		switch {
+		case c == quote:
+			return b.String(), pos + 1, nil
+		case c == '\\' && pos+1 < len(source):
+			pos++
+			switch source[pos] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(source[pos])
+			default:
+				return "", 0, &SyntaxError{Expr: source, Pos: pos - 1, Message: fmt.Sprintf("unknown escape '\\%c'", source[pos])}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, &SyntaxError{Expr: source, Pos: start, Message: "unterminated string"}
+}
+
+// parser is a recursive descent parser over the token stream. From lowest
+// to highest precedence: ||, &&, comparisons and in, + and -, * / and %,
+// unary ! and -, then member access, indexing and calls.
+type parser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+func newParser(source string) (*parser, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{source: source, tokens: tokens}, nil
+}
+
+func (p *parser) parse() (node, error) {
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "empty expression")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected '%s'", tok.text)
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is one of the given operators or
+// keywords
+func (p *parser) accept(texts ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator && tok.kind != tokenKeyword {
+		return tok, false
+	}
+	for _, text := range texts {
+		if tok.text == text {
+			return p.next(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *parser) expect(text string) error {
+	if _, ok := p.accept(text); !ok {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return p.errorf(tok, "expected '%s' but the expression ended", text)
+		}
+		return p.errorf(tok, "expected '%s' but found '%s'", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &SyntaxError{Expr: p.source, Pos: tok.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: false, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=", "in")
+	if !ok {
+		// "not in"
+		if tok := p.peek(); tok.kind == tokenKeyword && tok.text == "not" &&
+			p.tokens[p.pos+1].kind == tokenKeyword && p.tokens[p.pos+1].text == "in" {
+			p.pos += 2
+			op, ok = token{text: "not in", pos: tok.pos}, true
+		}
+	}
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if tok, chained := p.accept("==", "!=", "<", "<=", ">", ">=", "in"); chained {
+		return nil, p.errorf(tok, "comparisons cannot be chained; use && to combine them")
+	}
+	return &binaryNode{op: op.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "not", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op.text == "-" {
+			return &negateNode{operand: operand}, nil
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("."); ok {
+			tok := p.next()
+			switch tok.kind {
+			case tokenIdent, tokenKeyword:
+				target = &indexNode{target: target, index: &literalNode{value: tok.text}}
+			case tokenNumber:
+				target = &indexNode{target: target, index: &literalNode{value: tok.value}}
+			default:
+				return nil, p.errorf(tok, "expected a field name after '.'")
+			}
+			continue
+		}
+		if _, ok := p.accept("["); ok {
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			target = &indexNode{target: target, index: index}
+			continue
+		}
+		return target, nil
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: tok.value}, nil
+
+	case tokenKeyword:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+
+	case tokenIdent:
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		return &nameNode{name: tok.text}, nil
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseList()
+		}
+
+	case tokenEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	}
+	return nil, p.errorf(tok, "unexpected '%s'", tok.text)
+}
+
+// parseList parses the items of a list literal after its opening bracket
+func (p *parser) parseList() (node, error) {
+	list := &listNode{}
+	if _, ok := p.accept("]"); ok {
+		return list, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if _, ok := p.accept("]"); ok {
+			return list, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseCall parses the arguments of a call to the function named by name,
+// after the opening parenthesis, and checks them against its arity
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function '%s'", name.text)
+	}
+
+	call := &callNode{name: name.text, fn: fn}
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if _, ok := p.accept(")"); ok {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(call.args) != fn.arity {
+		return nil, p.errorf(name, "%s() takes %d argument(s), got %d", name.text, fn.arity, len(call.args))
+	}
+	return call, nil
+}
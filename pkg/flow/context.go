@@ -13,6 +13,7 @@ import (
 	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
 )
 
 // Context represents the execution context for a flow
@@ -22,13 +23,30 @@ type Context struct {
 	// Core context
 	ctx context.Context
 
-	// Data storage with thread safety
+	// Data storage with thread safety. The mutex is shared with any views
+	// created over the same values map.
 	values map[string]interface{}
-	mu     sync.RWMutex
+	mu     *sync.RWMutex
+
+	// Scoped views (see scope.go) read through to parent for keys they do
+	// not hold and that were not deleted in the scope; readOnly views
+	// reject writes
+	parent   interfaces.ExecutionContext
+	deleted  map[string]struct{}
+	readOnly *readOnlyWrites
+
+	// detached is set on the view a step runs on under a deadline; writes
+	// are dropped once the step was abandoned (see runWithDeadline)
+	detached *detachment
+
+	// history records changes when enabled; it is shared with views and
+	// clones (see history.go)
+	history *ContextHistory
 
 	// Metadata
 	flowName    string
 	executionID string
+	branchID    string
 	startTime   time.Time
 
 	// Observability
@@ -42,13 +60,15 @@ type Context struct {
 
 // NewContext creates a new execution context
 func NewContext() *Context {
+	cfg := config.DefaultConfig()
 	return &Context{
 		ctx:         context.Background(),
 		values:      make(map[string]interface{}),
-		executionID: generateExecutionID(),
+		mu:          &sync.RWMutex{},
+		executionID: generateExecutionID(cfg),
 		startTime:   time.Now(),
 		timeout:     30 * time.Second,
-		config:      config.DefaultConfig(),
+		config:      cfg,
 	}
 }
 
@@ -57,7 +77,8 @@ func NewContextWithConfig(cfg *config.FrameworkConfig) *Context {
 	return &Context{
 		ctx:         context.Background(),
 		values:      make(map[string]interface{}),
-		executionID: generateExecutionID(),
+		mu:          &sync.RWMutex{},
+		executionID: generateExecutionID(cfg),
 		startTime:   time.Now(),
 		timeout:     cfg.Timeouts.FlowExecution,
 		config:      cfg,
@@ -72,7 +93,8 @@ func NewContextWithGinContext(ginCtx context.Context, cfg *config.FrameworkConfi
 	return &Context{
 		ctx:         ginCtx,
 		values:      make(map[string]interface{}),
-		executionID: generateExecutionID(),
+		mu:          &sync.RWMutex{},
+		executionID: generateExecutionID(cfg),
 		startTime:   time.Now(),
 		timeout:     cfg.Timeouts.FlowExecution,
 		config:      cfg,
@@ -81,25 +103,45 @@ func NewContextWithGinContext(ginCtx context.Context, cfg *config.FrameworkConfi
 
 // Set stores a value in the context with thread safety
 func (c *Context) Set(key string, value interface{}) {
+	if c.readOnly != nil {
+		c.readOnly.reject(c, key)
+		return
+	}
+	if !c.detached.acquire() {
+		c.detached.reject(c, key)
+		return
+	}
+	defer c.detached.release()
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.values[key] = value
+	delete(c.deleted, key)
+	goCtx := c.ctx
+	history := c.history
+	c.mu.Unlock()
+	recordWrite(goCtx, key)
+	if history != nil {
+		history.record(c, ChangeSet, key, value)
+	}
 }
 
 // Get retrieves a typed value from the context
 func (c *Context) Get(key string) (interface{}, bool) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
 	val, ok := c.values[key]
-	return val, ok
+	_, deleted := c.deleted[key]
+	parent := c.parent
+	c.mu.RUnlock()
+
+	if ok || deleted || parent == nil {
+		return val, ok
+	}
+	return parent.Get(key)
 }
 
-// GetTyped retrieves a value with type assertion
+// GetTyped retrieves a value into the variable target points to,
+// converting it leniently as Get does
 func (c *Context) GetTyped(key string, target interface{}) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	val, ok := c.values[key]
+	val, ok := c.Get(key)
 	if !ok {
 		return fmt.Errorf("key not found: %s", key)
 	}
@@ -110,14 +152,12 @@ func (c *Context) GetTyped(key string, target interface{}) error {
 		return fmt.Errorf("target must be a pointer")
 	}
 
-	sourceValue := reflect.ValueOf(val)
-	targetType := targetValue.Elem().Type()
-
-	if !sourceValue.Type().AssignableTo(targetType) {
-		return fmt.Errorf("type assertion failed for key %s: expected %v, got %v", key, targetType, sourceValue.Type())
+	converted, err := values.ConvertValue(val, targetValue.Elem().Type())
+	if err != nil {
+		return fmt.Errorf("type assertion failed for key %s: %w", key, err)
 	}
 
-	targetValue.Elem().Set(sourceValue)
+	targetValue.Elem().Set(converted)
 	return nil
 }
 
@@ -135,32 +175,34 @@ func (c *Context) GetString(key string) (string, error) {
 	return "", fmt.Errorf("value is not a string: %T", val)
 }
 
-// GetInt is a convenience method for int values
+// GetInt is a convenience method for int values. Whole float64 values, as
+// decoded from JSON, and numeric strings are accepted.
 func (c *Context) GetInt(key string) (int, error) {
 	val, ok := c.Get(key)
 	if !ok {
 		return 0, fmt.Errorf("key not found: %s", key)
 	}
 
-	if i, ok := val.(int); ok {
-		return i, nil
+	converted, err := values.Convert[int](val)
+	if err != nil {
+		return 0, fmt.Errorf("value is not an int: %w", err)
 	}
-
-	return 0, fmt.Errorf("value is not an int: %T", val)
+	return converted, nil
 }
 
-// GetBool is a convenience method for bool values
+// GetBool is a convenience method for bool values. Strings such as "true"
+// are accepted.
 func (c *Context) GetBool(key string) (bool, error) {
 	val, ok := c.Get(key)
 	if !ok {
 		return false, fmt.Errorf("key not found: %s", key)
 	}
 
-	if b, ok := val.(bool); ok {
-		return b, nil
+	converted, err := values.Convert[bool](val)
+	if err != nil {
+		return false, fmt.Errorf("value is not a bool: %w", err)
 	}
-
-	return false, fmt.Errorf("value is not a bool: %T", val)
+	return converted, nil
 }
 
 // GetMap is a convenience method for map values
@@ -170,65 +212,117 @@ func (c *Context) GetMap(key string) (map[string]interface{}, error) {
 		return nil, fmt.Errorf("key not found: %s", key)
 	}
 
-	if m, ok := val.(map[string]interface{}); ok {
-		return m, nil
+	converted, err := values.Convert[map[string]interface{}](val)
+	if err != nil {
+		return nil, fmt.Errorf("value is not a map[string]interface{}: %w", err)
 	}
-
-	return nil, fmt.Errorf("value is not a map[string]interface{}: %T", val)
+	return converted, nil
 }
 
 // Has checks if a key exists in the context
 func (c *Context) Has(key string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	_, ok := c.values[key]
+	_, ok := c.Get(key)
 	return ok
 }
 
-// Delete removes a key from the context
+// Delete removes a key from the context. In a scope the key is only hidden
+// from the scope; the parent keeps it.
 func (c *Context) Delete(key string) {
+	if c.readOnly != nil {
+		c.readOnly.reject(c, key)
+		return
+	}
+	if !c.detached.acquire() {
+		c.detached.reject(c, key)
+		return
+	}
+	defer c.detached.release()
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	delete(c.values, key)
+	if c.parent != nil {
+		c.deleted[key] = struct{}{}
+	}
+	history := c.history
+	c.mu.Unlock()
+	if history != nil {
+		history.record(c, ChangeDelete, key, nil)
+	}
 }
 
 // Keys returns all keys in the context
 func (c *Context) Keys() []string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	keys := make([]string, 0, len(c.values))
-	for k := range c.values {
+	values := c.ToMap()
+	keys := make([]string, 0, len(values))
+	for k := range values {
 		keys = append(keys, k)
 	}
 	return keys
 }
 
-// Clone creates a deep copy of the context
+// Clone creates a deep copy of the context. Clones of scoped and read-only
+// views are plain contexts holding everything the view can read. A clone
+// records into the same history, so changes made in parallel branches are
+// recorded too.
 func (c *Context) Clone() interfaces.ExecutionContext {
+	values := c.ToMap()
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	newCtx := &Context{
 		ctx:         c.ctx, // Preserve the original Go context for proper cancellation
-		values:      make(map[string]interface{}),
+		values:      values,
+		mu:          &sync.RWMutex{},
 		flowName:    c.flowName,
 		executionID: c.executionID, // Keep same execution ID for traceability
-		startTime:   c.startTime,   // Keep same start time for accurate duration tracking
+		branchID:    newID(c.config),
+		startTime:   c.startTime, // Keep same start time for accurate duration tracking
 		logger:      c.logger,
 		span:        c.span,
 		timeout:     c.timeout,
 		config:      c.config,
-	}
-
-	// Deep copy values
-	for k, v := range c.values {
-		newCtx.values[k] = v
+		history:     c.history,
 	}
 
 	return newCtx
 }
 
+// startBranch gives the context a new branch ID and tags its logger with
+// the branch, so logs from concurrent branches can be told apart
+func (c *Context) startBranch(branch string) {
+	c.branchID = newID(c.config)
+	c.logger = c.Logger().With(
+		zap.String("branch", branch),
+		zap.String("branch_id", c.branchID))
+}
+
+// withGoContext returns a view of the context that shares its data and
+// metadata but carries a different Go context, e.g. one with a step deadline.
+// Writes through the view are visible to the original context.
+func (c *Context) withGoContext(goCtx context.Context) *Context {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &Context{
+		ctx:         goCtx,
+		values:      c.values,
+		mu:          c.mu,
+		parent:      c.parent,
+		deleted:     c.deleted,
+		readOnly:    c.readOnly,
+		detached:    c.detached,
+		history:     c.history,
+		flowName:    c.flowName,
+		executionID: c.executionID,
+		branchID:    c.branchID,
+		startTime:   c.startTime,
+		logger:      c.logger,
+		span:        c.span,
+		timeout:     c.timeout,
+		config:      c.config,
+	}
+}
+
 // WithTimeout sets the timeout for the context
 func (c *Context) WithTimeout(timeout time.Duration) *Context {
 	c.timeout = timeout
@@ -274,8 +368,12 @@ func (c *Context) Logger() *zap.Logger {
 	return c.logger
 }
 
-// Span returns the tracing span
+// Span returns the tracing span of the running step or flow, falling back
+// to the span set with WithSpan
 func (c *Context) Span() trace.Span {
+	if span := trace.SpanFromContext(c.ctx); span.SpanContext().IsValid() {
+		return span
+	}
 	return c.span
 }
 
@@ -289,6 +387,14 @@ func (c *Context) ExecutionID() string {
 	return c.executionID
 }
 
+// BranchID returns the ID of the branch the context belongs to. Each clone,
+// such as the one a parallel branch runs on, gets its own branch ID while
+// sharing the execution ID of the flow; it is empty for a context that was
+// not cloned.
+func (c *Context) BranchID() string {
+	return c.branchID
+}
+
 // StartTime returns the execution start time
 func (c *Context) StartTime() time.Time {
 	return c.startTime
@@ -301,10 +407,24 @@ func (c *Context) Duration() time.Duration {
 
 // ToMap returns all values as a map (for serialization)
 func (c *Context) ToMap() map[string]interface{} {
+	result := make(map[string]interface{})
+
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	parent := c.parent
+	c.mu.RUnlock()
+	if parent != nil {
+		for _, k := range parent.Keys() {
+			if v, ok := parent.Get(k); ok {
+				result[k] = v
+			}
+		}
+	}
 
-	result := make(map[string]interface{})
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for k := range c.deleted {
+		delete(result, k)
+	}
 	for k, v := range c.values {
 		result[k] = v
 	}
@@ -321,7 +441,148 @@ func (c *Context) RecordMetrics(stepName string, duration time.Duration, success
 	metrics.RecordStepExecution(stepName, duration, success)
 }
 
+// contextView wraps an ExecutionContext that is not a *Context so it can
+// carry a different Go context while delegating all data access
+type contextView struct {
+	interfaces.ExecutionContext
+	ctx      context.Context
+	detached *detachment
+}
+
+func (v *contextView) Context() context.Context {
+	return v.ctx
+}
+
+func (v *contextView) Set(key string, value interface{}) {
+	if !v.detached.acquire() {
+		v.detached.reject(v, key)
+		return
+	}
+	defer v.detached.release()
+	v.ExecutionContext.Set(key, value)
+	recordWrite(v.ctx, key)
+}
+
+func (v *contextView) Delete(key string) {
+	if !v.detached.acquire() {
+		v.detached.reject(v, key)
+		return
+	}
+	defer v.detached.release()
+	v.ExecutionContext.Delete(key)
+}
+
+func (v *contextView) Clone() interfaces.ExecutionContext {
+	return withGoContext(v.ExecutionContext.Clone(), v.ctx)
+}
+
+// withGoContext returns a view of ctx that shares its data but reports goCtx
+// from Context()
+func withGoContext(ctx interfaces.ExecutionContext, goCtx context.Context) interfaces.ExecutionContext {
+	switch c := ctx.(type) {
+	case *Context:
+		return c.withGoContext(goCtx)
+	case *contextView:
+		return &contextView{ExecutionContext: c.ExecutionContext, ctx: goCtx, detached: c.detached}
+	default:
+		return &contextView{ExecutionContext: ctx, ctx: goCtx}
+	}
+}
+
+// WithGoContext returns a view of ctx that shares its data but carries
+// goCtx, such as a context returned by Limiter.Hold
+func WithGoContext(ctx interfaces.ExecutionContext, goCtx context.Context) interfaces.ExecutionContext {
+	return withGoContext(ctx, goCtx)
+}
+
+// detachedView returns a view of ctx carrying goCtx whose writes can be cut
+// off with the returned detachment
+func detachedView(ctx interfaces.ExecutionContext, goCtx context.Context) (interfaces.ExecutionContext, *detachment) {
+	switch view := withGoContext(ctx, goCtx).(type) {
+	case *Context:
+		view.detached = &detachment{parent: view.detached}
+		return view, view.detached
+	case *contextView:
+		view.detached = &detachment{parent: view.detached}
+		return view, view.detached
+	default:
+		return view, nil
+	}
+}
+
+// detachment cuts a step run off from the context once the executor has
+// abandoned it at its deadline, so that it cannot change the context under
+// the steps that follow. Writes hold the read lock, so detach waits for the
+// writes in progress and every later one is dropped. Detaching a view also
+// detaches the views derived from it. A nil detachment never detaches.
+type detachment struct {
+	parent   *detachment
+	mu       sync.RWMutex
+	detached bool
+}
+
+// acquire reports whether a write may proceed; it must then be followed by
+// release once the write is done
+func (d *detachment) acquire() bool {
+	if d == nil {
+		return true
+	}
+	if !d.parent.acquire() {
+		return false
+	}
+	d.mu.RLock()
+	if d.detached {
+		d.mu.RUnlock()
+		d.parent.release()
+		return false
+	}
+	return true
+}
+
+func (d *detachment) release() {
+	if d == nil {
+		return
+	}
+	d.mu.RUnlock()
+	d.parent.release()
+}
+
+// detach drops all later writes, once the writes in progress are done
+func (d *detachment) detach() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.detached = true
+	d.mu.Unlock()
+}
+
+func (d *detachment) reject(ctx interfaces.ExecutionContext, key string) {
+	ctx.Logger().Warn("Write from a step abandoned at its deadline dropped", zap.String("key", key))
+}
+
+// contextConfig returns the framework configuration carried by ctx, if any
+func contextConfig(ctx interfaces.ExecutionContext) *config.FrameworkConfig {
+	switch c := ctx.(type) {
+	case *Context:
+		return c.config
+	case *contextView:
+		return contextConfig(c.ExecutionContext)
+	default:
+		return nil
+	}
+}
+
 // generateExecutionID creates a unique execution ID
-func generateExecutionID() string {
-	return fmt.Sprintf("exec_%d", time.Now().UnixNano())
+func generateExecutionID(cfg *config.FrameworkConfig) string {
+	return "exec_" + newID(cfg)
+}
+
+// newID generates an ID with the configured strategy, defaulting to
+// time-sortable IDs
+func newID(cfg *config.FrameworkConfig) string {
+	if cfg == nil {
+		return config.SortableID()
+	}
+	return cfg.IDs.NewID()
 }
@@ -1,6 +1,7 @@
 package flow
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 )
 
 func TestNewContext(t *testing.T) {
@@ -329,9 +331,12 @@ func TestContext_Clone(t *testing.T) {
 		t.Error("Cloned context should have same flow name")
 	}
 
-	// Verify execution IDs are different
-	if clonedCtx.ExecutionID() == ctx.ExecutionID() {
-		t.Error("Cloned context should have different execution ID")
+	// Verify the clone belongs to the same execution on its own branch
+	if clonedCtx.ExecutionID() != ctx.ExecutionID() {
+		t.Error("Cloned context should share the execution ID")
+	}
+	if clonedCtx.BranchID() == "" || clonedCtx.BranchID() == ctx.BranchID() {
+		t.Error("Cloned context should have a distinct branch ID")
 	}
 
 	// Verify modifying clone doesn't affect original
@@ -405,26 +410,13 @@ func TestContext_WithFlowName(t *testing.T) {
 func TestContext_Context(t *testing.T) {
 	ctx := NewContext()
 
-	// Test without timeout
-	ctx.timeout = 0
-	goCtx := ctx.Context()
-	if goCtx != ctx.ctx {
-		t.Error("Context should return the underlying context when no timeout")
-	}
-
-	// Test with timeout
+	// The timeout is only a setting; the deadline is applied by Flow.Execute
 	ctx.timeout = 100 * time.Millisecond
-	goCtx = ctx.Context()
-	if goCtx == ctx.ctx {
-		t.Error("Context should return a new context with timeout")
+	if ctx.Context() != ctx.ctx {
+		t.Error("Context should return the underlying context")
 	}
-
-	// Verify timeout works
-	select {
-	case <-goCtx.Done():
-		// Expected after timeout
-	case <-time.After(200 * time.Millisecond):
-		t.Error("Context should have timed out")
+	if _, hasDeadline := ctx.Context().Deadline(); hasDeadline {
+		t.Error("Context should not carry a deadline outside of flow execution")
 	}
 }
 
@@ -554,9 +546,8 @@ func TestContext_RecordMetrics(t *testing.T) {
 }
 
 func TestGenerateExecutionID(t *testing.T) {
-	id1 := generateExecutionID()
-	time.Sleep(1 * time.Millisecond) // Small delay to ensure different timestamps
-	id2 := generateExecutionID()
+	id1 := generateExecutionID(nil)
+	id2 := generateExecutionID(nil)
 
 	if id1 == "" {
 		t.Error("generateExecutionID should not return empty string")
@@ -576,6 +567,88 @@ func TestGenerateExecutionID(t *testing.T) {
 	}
 }
 
+func TestGenerateExecutionID_Concurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+	ids := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- generateExecutionID(nil)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate execution ID %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestNewID_Strategies(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	cfg.IDs.Strategy = config.IDStrategyRandom
+	if id := newID(cfg); len(id) != 32 {
+		t.Errorf("Random ID = %q, want 32 hex characters", id)
+	}
+
+	cfg.IDs.Strategy = config.IDStrategySortable
+	first, second := newID(cfg), newID(cfg)
+	if len(first) != 36 || first[14] != '7' {
+		t.Errorf("Sortable ID = %q, want a UUIDv7", first)
+	}
+	if second <= first {
+		t.Errorf("Sortable IDs should increase: %q then %q", first, second)
+	}
+
+	cfg.IDs.Generator = func() string { return "custom" }
+	ctx := NewContextWithConfig(cfg)
+	if ctx.ExecutionID() != "exec_custom" {
+		t.Errorf("ExecutionID() = %q, want exec_custom", ctx.ExecutionID())
+	}
+}
+
+func TestParallelStep_BranchIDs(t *testing.T) {
+	var mu sync.Mutex
+	branchIDs := make(map[string]string)
+	executionIDs := make(map[string]string)
+	record := func(name string) interfaces.Step {
+		return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			branchIDs[name] = contextBranchID(ctx)
+			executionIDs[name] = ctx.ExecutionID()
+			return nil
+		}}
+	}
+
+	ctx := NewContext().WithLogger(zap.NewNop())
+	step := NewParallelStep("fanout", record("a"), record("b"))
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if branchIDs["a"] == "" || branchIDs["b"] == "" || branchIDs["a"] == branchIDs["b"] {
+		t.Errorf("Branch IDs = %v, want distinct non-empty IDs", branchIDs)
+	}
+	for name, id := range executionIDs {
+		if id != ctx.ExecutionID() {
+			t.Errorf("Branch %s execution ID = %q, want the parent's %q", name, id, ctx.ExecutionID())
+		}
+	}
+	if ctx.BranchID() != "" {
+		t.Errorf("Parent BranchID() = %q, want empty", ctx.BranchID())
+	}
+}
+
 func TestContext_ThreadSafety(t *testing.T) {
 	ctx := NewContext()
 	done := make(chan bool)
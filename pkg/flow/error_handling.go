@@ -0,0 +1,261 @@
+package flow
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// StepError describes the step failure that stopped a flow. Type is taken
+// from the first *errors.FrameworkError in the chain, or ErrorTypeInternal
+// when the step returned a plain error.
+type StepError struct {
+	Step string
+	Type errors.ErrorType
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step '%s' failed: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// FrameworkError returns the *errors.FrameworkError in the chain, if any
+func (e *StepError) FrameworkError() (*errors.FrameworkError, bool) {
+	var frameworkErr *errors.FrameworkError
+	if stderrors.As(e.Err, &frameworkErr) {
+		return frameworkErr, true
+	}
+	return nil, false
+}
+
+func newStepError(step string, err error) *StepError {
+	stepErr := &StepError{Step: step, Type: errors.ErrorTypeInternal, Err: err}
+	if frameworkErr, ok := stepErr.FrameworkError(); ok {
+		stepErr.Type = frameworkErr.Type
+	} else if stderrors.Is(err, context.DeadlineExceeded) {
+		stepErr.Type = errors.ErrorTypeTimeout
+	}
+	return stepErr
+}
+
+// ErrorHandler handles a step failure. The returned error replaces the
+// flow's error: return stepErr to keep it, another error to translate it, or
+// nil to recover so the flow reports success. Steps after the failing one
+// do not run either way.
+type ErrorHandler func(ctx interfaces.ExecutionContext, stepErr *StepError) error
+
+// errorFlowKey is the Go context key under which Catch and Finally steps
+// find the failure being handled
+type errorFlowKey struct{}
+
+// StepErrorFromContext returns the failure being handled when called from a
+// Catch or Finally step; ok is false when the flow has not failed
+func StepErrorFromContext(ctx interfaces.ExecutionContext) (*StepError, bool) {
+	goCtx := ctx.Context()
+	if goCtx == nil {
+		return nil, false
+	}
+	stepErr, ok := goCtx.Value(errorFlowKey{}).(*StepError)
+	return stepErr, ok
+}
+
+// OnError registers a handler for failures of the named step. It takes
+// precedence over an OnErrorType handler; registering the same step again
+// replaces the handler.
+func (f *Flow) OnError(step string, handler ErrorHandler) *Flow {
+	f.stepErrorHandlers[step] = handler
+	return f
+}
+
+// OnErrorType registers a handler for step failures of errType, such as
+// errors.ErrorTypeTimeout or errors.ErrorTypeValidation, that have no
+// OnError handler of their own. Step and type handlers are kept apart, so a
+// step named after an error type does not replace the type handler.
+func (f *Flow) OnErrorType(errType errors.ErrorType, handler ErrorHandler) *Flow {
+	f.typeErrorHandlers[errType] = handler
+	return f
+}
+
+// Catch starts a block of compensating steps that runs when the flow fails,
+// after any OnError handler. The error is still returned unless the block
+// is marked with Swallow.
+func (f *Flow) Catch(name string) *CatchBuilder {
+	return &CatchBuilder{
+		flow: f,
+		name: name,
+	}
+}
+
+// Finally starts a block that runs after the flow whether it succeeded or
+// failed, e.g. for analytics or cache cleanup
+func (f *Flow) Finally(name string) *FinallyBuilder {
+	return &FinallyBuilder{
+		flow: f,
+		name: name,
+	}
+}
+
+// CatchBuilder builds a catch block
+type CatchBuilder struct {
+	flow    *Flow
+	name    string
+	steps   []interfaces.Step
+	swallow bool
+}
+
+// Step adds a step to the catch block
+func (cb *CatchBuilder) Step(name string, step interfaces.Step) *CatchBuilder {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	cb.steps = append(cb.steps, step)
+	return cb
+}
+
+// StepFunc adds a function step to the catch block
+func (cb *CatchBuilder) StepFunc(name string, fn func(interfaces.ExecutionContext) error) *CatchBuilder {
+	step := &namedStep{Step: StepFunc(fn), name: name}
+	cb.steps = append(cb.steps, step)
+	return cb
+}
+
+// Swallow marks the error as handled once the catch block succeeds, so the
+// flow reports success
+func (cb *CatchBuilder) Swallow() *CatchBuilder {
+	cb.swallow = true
+	return cb
+}
+
+// EndCatch completes the catch block
+func (cb *CatchBuilder) EndCatch() *Flow {
+	cb.flow.catchBlocks = append(cb.flow.catchBlocks, &handlerBlock{
+		step:    NewSequentialStep(cb.name, cb.steps...),
+		swallow: cb.swallow,
+	})
+	return cb.flow
+}
+
+// FinallyBuilder builds a finally block
+type FinallyBuilder struct {
+	flow  *Flow
+	name  string
+	steps []interfaces.Step
+}
+
+// Step adds a step to the finally block
+func (fb *FinallyBuilder) Step(name string, step interfaces.Step) *FinallyBuilder {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	fb.steps = append(fb.steps, step)
+	return fb
+}
+
+// StepFunc adds a function step to the finally block
+func (fb *FinallyBuilder) StepFunc(name string, fn func(interfaces.ExecutionContext) error) *FinallyBuilder {
+	step := &namedStep{Step: StepFunc(fn), name: name}
+	fb.steps = append(fb.steps, step)
+	return fb
+}
+
+// EndFinally completes the finally block
+func (fb *FinallyBuilder) EndFinally() *Flow {
+	fb.flow.finallyBlocks = append(fb.flow.finallyBlocks, &handlerBlock{
+		step: NewSequentialStep(fb.name, fb.steps...),
+	})
+	return fb.flow
+}
+
+// handlerBlock is a catch or finally block
+type handlerBlock struct {
+	step    interfaces.Step
+	swallow bool
+}
+
+// stepError returns the StepError behind a failed execution. A flow
+// timeout is attributed to the step that was running when it fired.
+func stepError(err error, result *ExecutionResult) *StepError {
+	var stepErr *StepError
+	if result.TimedOut || !stderrors.As(err, &stepErr) {
+		stepErr = newStepError(result.TimedOutStep, err)
+	}
+	return stepErr
+}
+
+// handleFailure runs the OnError handler and catch blocks for a failed
+// execution and returns the error the flow should report. ctx carries
+// stepErr for StepErrorFromContext.
+func (f *Flow) handleFailure(ctx interfaces.ExecutionContext, stepErr *StepError, err error, result *ExecutionResult) error {
+	if handler := f.errorHandler(stepErr); handler != nil {
+		handled := handler(ctx, stepErr)
+		if handled == nil {
+			ctx.Logger().Info("Flow error handled",
+				zap.String("flow", f.name),
+				zap.String("step", stepErr.Step),
+				zap.String("error_type", string(stepErr.Type)))
+			result.Recovered = true
+			return nil
+		}
+		if handled != error(stepErr) {
+			err = handled
+		}
+	}
+
+	for _, block := range f.catchBlocks {
+		if catchErr := runStep(ctx, block.step); catchErr != nil {
+			ctx.Logger().Error("Catch block failed",
+				zap.String("flow", f.name),
+				zap.String("catch", block.step.Name()),
+				zap.Error(catchErr))
+			return err
+		}
+		if block.swallow {
+			ctx.Logger().Info("Flow error swallowed by catch block",
+				zap.String("flow", f.name),
+				zap.String("catch", block.step.Name()),
+				zap.String("step", stepErr.Step))
+			result.Recovered = true
+			return nil
+		}
+	}
+
+	return err
+}
+
+// errorHandler returns the handler registered for the failing step, falling
+// back to the handler for its error type
+func (f *Flow) errorHandler(stepErr *StepError) ErrorHandler {
+	if handler, ok := f.stepErrorHandlers[stepErr.Step]; ok {
+		return handler
+	}
+	return f.typeErrorHandlers[stepErr.Type]
+}
+
+// runFinally runs the finally blocks. A finally failure becomes the flow's
+// error only when the flow had otherwise succeeded.
+func (f *Flow) runFinally(ctx interfaces.ExecutionContext, err error) error {
+	for _, block := range f.finallyBlocks {
+		finallyErr := runStep(ctx, block.step)
+		if finallyErr == nil {
+			continue
+		}
+
+		ctx.Logger().Error("Finally block failed",
+			zap.String("flow", f.name),
+			zap.String("finally", block.step.Name()),
+			zap.Error(finallyErr))
+		if err == nil {
+			err = newStepError(block.step.Name(), finallyErr)
+		}
+	}
+	return err
+}
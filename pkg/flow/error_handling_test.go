@@ -0,0 +1,375 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+func failWith(err error) func(interfaces.ExecutionContext) error {
+	return func(interfaces.ExecutionContext) error { return err }
+}
+
+func newTestContext() *Context {
+	return NewContext().WithLogger(zap.NewNop())
+}
+
+func TestFlow_StepError(t *testing.T) {
+	validationErr := frameworkErrors.MissingField("email")
+	flow := NewFlow("errors").StepFunc("validate", failWith(validationErr))
+
+	result, err := flow.Execute(newTestContext())
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) {
+		t.Fatalf("Error = %v, want a StepError", err)
+	}
+	if stepErr.Step != "validate" || stepErr.Type != frameworkErrors.ErrorTypeValidation {
+		t.Errorf("StepError = %v/%v, want validate/validation", stepErr.Step, stepErr.Type)
+	}
+	if err.Error() != "step 'validate' failed: "+validationErr.Error() {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if fwErr, ok := stepErr.FrameworkError(); !ok || fwErr != validationErr {
+		t.Error("FrameworkError() should return the step's error")
+	}
+	if result.FailedStep != "validate" || result.Recovered {
+		t.Errorf("FailedStep/Recovered = %v/%v, want validate/false", result.FailedStep, result.Recovered)
+	}
+
+	plain := newStepError("plain", errors.New("boom"))
+	if plain.Type != frameworkErrors.ErrorTypeInternal {
+		t.Errorf("Plain error type = %v, want internal", plain.Type)
+	}
+}
+
+func TestFlow_OnError_ByStepName(t *testing.T) {
+	var handled *StepError
+	stepRan := false
+	flow := NewFlow("errors").
+		StepFunc("fetch", failWith(errors.New("unavailable"))).
+		StepFunc("after", func(interfaces.ExecutionContext) error {
+			stepRan = true
+			return nil
+		}).
+		OnError("fetch", func(ctx interfaces.ExecutionContext, stepErr *StepError) error {
+			handled = stepErr
+			ctx.Set("fallback", true)
+			return nil
+		}).
+		OnErrorType(frameworkErrors.ErrorTypeInternal, func(interfaces.ExecutionContext, *StepError) error {
+			t.Error("Step handler should take precedence over the type handler")
+			return nil
+		})
+
+	ctx := newTestContext()
+	result, err := flow.Execute(ctx)
+
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want recovered", err)
+	}
+	if handled == nil || handled.Step != "fetch" {
+		t.Fatalf("Handler got %v, want step fetch", handled)
+	}
+	if !result.Success || !result.Recovered || result.FailedStep != "fetch" {
+		t.Errorf("Result success/recovered/failed = %v/%v/%v", result.Success, result.Recovered, result.FailedStep)
+	}
+	if stepRan {
+		t.Error("Steps after the failing one should not run")
+	}
+	if !ctx.Has("fallback") {
+		t.Error("Handler writes should be visible in the context")
+	}
+}
+
+func TestFlow_OnError_ByErrorType(t *testing.T) {
+	translated := errors.New("please retry later")
+	flow := NewFlow("errors").
+		Step("slow", slowStep("slow", 20*time.Millisecond)).
+		OnErrorType(frameworkErrors.ErrorTypeValidation, func(interfaces.ExecutionContext, *StepError) error {
+			t.Error("Validation handler should not run for a timeout")
+			return nil
+		}).
+		OnErrorType(frameworkErrors.ErrorTypeTimeout, func(_ interfaces.ExecutionContext, stepErr *StepError) error {
+			if stepErr.Step != "slow" {
+				t.Errorf("Step = %v, want slow", stepErr.Step)
+			}
+			return translated
+		})
+
+	result, err := flow.Execute(newTestContext())
+
+	if err != translated {
+		t.Errorf("Execute() error = %v, want the handler's error", err)
+	}
+	if result.Success || result.Recovered {
+		t.Error("Flow should still fail with the translated error")
+	}
+}
+
+func TestFlow_OnError_StepNamedAfterErrorType(t *testing.T) {
+	var handledBy []string
+	flow := func(step string, err error) *Flow {
+		return NewFlow("errors").
+			StepFunc(step, failWith(err)).
+			OnErrorType(frameworkErrors.ErrorTypeTimeout, func(interfaces.ExecutionContext, *StepError) error {
+				handledBy = append(handledBy, "type")
+				return nil
+			}).
+			OnError("timeout", func(interfaces.ExecutionContext, *StepError) error {
+				handledBy = append(handledBy, "step")
+				return nil
+			})
+	}
+
+	if _, err := flow("timeout", errors.New("unavailable")).Execute(newTestContext()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := flow("fetch", context.DeadlineExceeded).Execute(newTestContext()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.Join(handledBy, ",") != "step,type" {
+		t.Errorf("Handlers run = %v, want the step handler then the type handler", handledBy)
+	}
+}
+
+func TestFlow_OnError_KeepsError(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("fetch", failWith(errors.New("unavailable"))).
+		OnError("fetch", func(_ interfaces.ExecutionContext, stepErr *StepError) error {
+			return stepErr
+		})
+
+	_, err := flow.Execute(newTestContext())
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "fetch" {
+		t.Errorf("Execute() error = %v, want the original StepError", err)
+	}
+}
+
+func TestFlow_OnError_FlowTimeout(t *testing.T) {
+	var handled *StepError
+	flow := NewFlow("errors").
+		WithTimeout(30*time.Millisecond).
+		Step("slow", NewDelayStep("slow", 5*time.Second)).
+		OnError("slow", func(_ interfaces.ExecutionContext, stepErr *StepError) error {
+			handled = stepErr
+			return stepErr
+		})
+
+	result, err := flow.Execute(newTestContext())
+
+	if handled == nil || handled.Type != frameworkErrors.ErrorTypeTimeout {
+		t.Fatalf("Handler got %v, want a timeout on step slow", handled)
+	}
+	if !result.TimedOut || result.FailedStep != "slow" {
+		t.Errorf("TimedOut/FailedStep = %v/%v, want true/slow", result.TimedOut, result.FailedStep)
+	}
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Type != frameworkErrors.ErrorTypeTimeout {
+		t.Errorf("Execute() error = %v, want the flow timeout error", err)
+	}
+}
+
+func TestFlow_Catch(t *testing.T) {
+	var order []string
+	flow := NewFlow("errors").
+		StepFunc("reserve", func(interfaces.ExecutionContext) error {
+			order = append(order, "reserve")
+			return nil
+		}).
+		StepFunc("charge", failWith(errors.New("declined"))).
+		Catch("compensate").
+		StepFunc("release", func(ctx interfaces.ExecutionContext) error {
+			stepErr, ok := StepErrorFromContext(ctx)
+			if !ok || stepErr.Step != "charge" {
+				t.Errorf("StepErrorFromContext() = %v/%v, want charge", stepErr, ok)
+			}
+			order = append(order, "release")
+			return nil
+		}).
+		EndCatch()
+
+	result, err := flow.Execute(newTestContext())
+
+	if err == nil || result.Recovered {
+		t.Error("Catch without Swallow should keep the error")
+	}
+	if len(order) != 2 || order[1] != "release" {
+		t.Errorf("Order = %v, want [reserve release]", order)
+	}
+}
+
+func TestFlow_Catch_Swallow(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("charge", failWith(errors.New("declined"))).
+		Catch("compensate").
+		StepFunc("mark", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("compensated", true)
+			return nil
+		}).
+		Swallow().
+		EndCatch()
+
+	ctx := newTestContext()
+	result, err := flow.Execute(ctx)
+
+	if err != nil || !result.Success || !result.Recovered {
+		t.Errorf("Execute() error/success/recovered = %v/%v/%v, want swallowed", err, result.Success, result.Recovered)
+	}
+	if !ctx.Has("compensated") {
+		t.Error("Catch steps should have run")
+	}
+}
+
+func TestFlow_Catch_Fails(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("charge", failWith(errors.New("declined"))).
+		Catch("compensate").
+		StepFunc("release", failWith(errors.New("release failed"))).
+		Swallow().
+		EndCatch()
+
+	_, err := flow.Execute(newTestContext())
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "charge" {
+		t.Errorf("Execute() error = %v, want the original failure", err)
+	}
+}
+
+func TestFlow_Catch_NotRunOnSuccess(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("ok", func(interfaces.ExecutionContext) error { return nil }).
+		Catch("compensate").
+		StepFunc("release", func(interfaces.ExecutionContext) error {
+			t.Error("Catch should not run when the flow succeeds")
+			return nil
+		}).
+		EndCatch()
+
+	if _, err := flow.Execute(newTestContext()); err != nil {
+		t.Errorf("Execute() error = %v", err)
+	}
+}
+
+func TestFlow_Finally(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    func(interfaces.ExecutionContext) error
+		wantErr bool
+	}{
+		{"success", func(interfaces.ExecutionContext) error { return nil }, false},
+		{"failure", failWith(errors.New("boom")), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sawError bool
+			ran := false
+			flow := NewFlow("errors").
+				StepFunc("main", tt.step).
+				Finally("cleanup").
+				StepFunc("analytics", func(ctx interfaces.ExecutionContext) error {
+					ran = true
+					_, sawError = StepErrorFromContext(ctx)
+					return nil
+				}).
+				EndFinally()
+
+			_, err := flow.Execute(newTestContext())
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !ran {
+				t.Error("Finally should always run")
+			}
+			if sawError != tt.wantErr {
+				t.Errorf("StepErrorFromContext() ok = %v, want %v", sawError, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlow_Finally_AfterTimeout(t *testing.T) {
+	ran := false
+	flow := NewFlow("errors").
+		WithTimeout(20*time.Millisecond).
+		Step("slow", NewDelayStep("slow", 5*time.Second)).
+		Finally("cleanup").
+		StepFunc("cleanup", func(ctx interfaces.ExecutionContext) error {
+			if ctx.Context().Err() != nil {
+				t.Error("Finally should not inherit the expired flow deadline")
+			}
+			ran = true
+			return nil
+		}).
+		EndFinally()
+
+	result, _ := flow.Execute(newTestContext())
+
+	if !ran || !result.TimedOut {
+		t.Errorf("Finally ran = %v, timed out = %v, want both", ran, result.TimedOut)
+	}
+}
+
+func TestFlow_Finally_CleanupTimeout(t *testing.T) {
+	var deadline time.Time
+	flow := NewFlow("errors").
+		WithTimeout(20*time.Millisecond).
+		WithCleanupTimeout(50*time.Millisecond).
+		Step("slow", NewDelayStep("slow", 5*time.Second)).
+		Finally("cleanup").
+		StepFunc("cleanup", func(ctx interfaces.ExecutionContext) error {
+			deadline, _ = ctx.Context().Deadline()
+			return nil
+		}).
+		EndFinally()
+
+	start := time.Now()
+	flow.Execute(newTestContext())
+
+	if deadline.IsZero() || deadline.Sub(start) > time.Second {
+		t.Errorf("Finally deadline = %v, want the cleanup budget", deadline)
+	}
+}
+
+func TestFlow_Finally_Fails(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("main", func(interfaces.ExecutionContext) error { return nil }).
+		Finally("cleanup").
+		StepFunc("flush", failWith(errors.New("flush failed"))).
+		EndFinally()
+
+	result, err := flow.Execute(newTestContext())
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "cleanup" {
+		t.Errorf("Execute() error = %v, want the finally failure", err)
+	}
+	if result.Success {
+		t.Error("A failing finally block should fail an otherwise successful flow")
+	}
+}
+
+func TestExecutionResult_GetResponse_FailedStep(t *testing.T) {
+	flow := NewFlow("errors").
+		StepFunc("fetch", failWith(errors.New("unavailable"))).
+		OnError("fetch", func(interfaces.ExecutionContext, *StepError) error { return nil })
+
+	result, _ := flow.Execute(newTestContext())
+	response := result.GetResponse()
+
+	if response["failed_step"] != "fetch" || response["recovered"] != true {
+		t.Errorf("Response failed_step/recovered = %v/%v", response["failed_step"], response["recovered"])
+	}
+}
@@ -1,11 +1,15 @@
 package flow
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 )
 
@@ -16,6 +20,20 @@ type Flow struct {
 	steps       []interfaces.Step
 	middleware  []Middleware
 	timeout     time.Duration
+	cleanup     time.Duration
+
+	stepErrorHandlers map[string]ErrorHandler
+	typeErrorHandlers map[errors.ErrorType]ErrorHandler
+	catchBlocks       []*handlerBlock
+	finallyBlocks     []*handlerBlock
+	saga              bool
+	debug             bool
+
+	writeCheck     WriteCheck
+	allowOverwrite []string
+	history        bool
+
+	tracerProvider trace.TracerProvider
 }
 
 // NewFlow creates a new flow with the given name
@@ -26,6 +44,9 @@ func NewFlow(name string) *Flow {
 		steps:       make([]interfaces.Step, 0),
 		middleware:  make([]Middleware, 0),
 		timeout:     30 * time.Second,
+
+		stepErrorHandlers: make(map[string]ErrorHandler),
+		typeErrorHandlers: make(map[errors.ErrorType]ErrorHandler),
 	}
 }
 
@@ -41,6 +62,20 @@ func (f *Flow) WithTimeout(timeout time.Duration) *Flow {
 	return f
 }
 
+// WithCleanupTimeout sets the time catch, compensation and finally blocks
+// get once the steps are done. They run after the flow deadline, so by
+// default they get a budget of the flow timeout of their own.
+func (f *Flow) WithCleanupTimeout(timeout time.Duration) *Flow {
+	f.cleanup = timeout
+	return f
+}
+
+// WithDebug makes GetResponse include the per-step trace of each execution
+func (f *Flow) WithDebug(debug bool) *Flow {
+	f.debug = debug
+	return f
+}
+
 // Use adds middleware to the flow
 func (f *Flow) Use(middleware Middleware) *Flow {
 	f.middleware = append(f.middleware, middleware)
@@ -103,16 +138,32 @@ func (f *Flow) Retry(maxRetries int, retryDelay time.Duration) *Flow {
 	}
 
 	lastStep := f.steps[len(f.steps)-1]
-	f.steps[len(f.steps)-1] = NewRetryStep(
+
+	// Retry the step itself and keep its compensation attached
+	var compensate interfaces.Step
+	if cs, ok := lastStep.(*compensableStep); ok {
+		lastStep, compensate = cs.Step, cs.compensate
+	}
+
+	retryStep := NewRetryStep(
 		fmt.Sprintf("retry_%s", lastStep.Name()),
 		lastStep,
 		maxRetries,
 		retryDelay,
 	)
+	if compensate != nil {
+		f.steps[len(f.steps)-1] = &compensableStep{Step: retryStep, compensate: compensate}
+		return f
+	}
+	f.steps[len(f.steps)-1] = retryStep
 	return f
 }
 
-// Execute runs the flow with the given context
+// Execute runs the flow with the given context. The flow timeout is applied
+// as a real deadline on the Go context seen by every step, so outstanding
+// HTTP calls and parallel branches are cancelled when it fires. A shorter
+// deadline already present on the incoming context wins. Each execution is
+// recorded as an OpenTelemetry span with a child span per step.
 func (f *Flow) Execute(ctx interfaces.ExecutionContext) (*ExecutionResult, error) {
 	// Apply middleware
 	handler := f.executeSteps
@@ -125,31 +176,110 @@ func (f *Flow) Execute(ctx interfaces.ExecutionContext) (*ExecutionResult, error
 		FlowName:  f.name,
 		StartTime: time.Now(),
 		Context:   ctx,
+		Debug:     f.debug,
 	}
 
-	// Execute with timeout
 	if flowCtx, ok := ctx.(*Context); ok {
 		flowCtx.WithTimeout(f.timeout)
+		if f.history {
+			flowCtx.RecordHistory()
+		}
 	}
 
+	parent := ctx.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	parent, span := f.startFlowSpan(parent, ctx)
+	tracker := &stepTracker{}
+	trace := newExecutionTrace()
+	trace.writes = newWriteCheck(f.writeCheck, f.allowOverwrite)
+	// A flow run as a sub-flow starts its own trace; its steps are nested
+	// into the parent's trace afterwards
+	parent = context.WithValue(parent, traceKey{}, trace)
+	parent = context.WithValue(parent, traceFrameKey{}, (*traceFrame)(nil))
+	goCtx := context.WithValue(parent, stepTrackerKey{}, tracker)
+	cancel := func() {}
+	if f.timeout > 0 {
+		goCtx, cancel = context.WithTimeout(goCtx, f.timeout)
+	}
+	defer cancel()
+
 	ctx.Logger().Info("Starting flow execution",
 		zap.String("flow", f.name),
 		zap.String("execution_id", ctx.ExecutionID()),
 		zap.Int("steps", len(f.steps)))
 
-	// Execute the flow
-	err := handler(ctx)
+	// Execute the flow, returning shortly after the deadline fires even if
+	// a step ignores cancellation. A handler still running then is cut off
+	// from the context the result is built from.
+	handlerCtx, detached := detachedView(ctx, goCtx)
+	done := make(chan error, 1)
+	go func() {
+		done <- handler(handlerCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-goCtx.Done():
+		err = f.abandon(done, detached, goCtx.Err())
+	}
 
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
+
+	if err != nil && goCtx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		result.TimedOutStep = tracker.current()
+		err = f.timeoutError(result, err)
+	}
+
+	// Error handlers, catch and finally blocks run outside the flow
+	// deadline, under a cleanup budget of their own and still bound by the
+	// incoming context
+	cleanupGoCtx, cancelCleanup := f.cleanupContext(parent)
+	defer cancelCleanup()
+	if err != nil {
+		stepErr := stepError(err, result)
+		result.FailedStep = stepErr.Step
+		cleanupGoCtx = context.WithValue(cleanupGoCtx, errorFlowKey{}, stepErr)
+		cleanupCtx := withGoContext(ctx, cleanupGoCtx)
+
+		if completed := tracker.compensable(); len(completed) > 0 {
+			result.Compensations = f.compensate(cleanupCtx, completed)
+		}
+		err = f.handleFailure(cleanupCtx, stepErr, err, result)
+	}
+	if f.saga {
+		f.recordSaga(sagaOutcome(result.FailedStep != "", result.Compensations), result.Compensations)
+	}
+	if len(f.finallyBlocks) > 0 {
+		err = f.runFinally(withGoContext(ctx, cleanupGoCtx), err)
+	}
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	result.Steps = trace.results()
+	result.Overwrites = trace.overwrites()
+	result.History = contextHistory(ctx)
+	for _, o := range result.Overwrites {
+		ctx.Logger().Warn("Context key written by more than one step",
+			zap.String("flow", f.name),
+			zap.String("key", o.Key),
+			zap.String("step", o.Step),
+			zap.String("previous_step", o.PreviousStep))
+	}
+
 	result.Success = err == nil
 	result.Error = err
+	endFlowSpan(span, result)
 
 	if err != nil {
 		ctx.Logger().Error("Flow execution failed",
 			zap.String("flow", f.name),
 			zap.String("execution_id", ctx.ExecutionID()),
 			zap.Duration("duration", result.Duration),
+			zap.Bool("timed_out", result.TimedOut),
 			zap.Error(err))
 	} else {
 		ctx.Logger().Info("Flow execution completed",
@@ -161,10 +291,102 @@ func (f *Flow) Execute(ctx interfaces.ExecutionContext) (*ExecutionResult, error
 	return result, err
 }
 
+// abandonGracePeriod is how long Execute waits for a handler that is still
+// running when the flow deadline fires, giving steps that honour
+// cancellation the time to record their outcome
+const abandonGracePeriod = 50 * time.Millisecond
+
+// abandon waits up to abandonGracePeriod for the handler to return on done.
+// A handler still running then is detached, so that nothing it writes
+// afterwards reaches the flow context, and err is returned in its place.
+func (f *Flow) abandon(done <-chan error, detached *detachment, err error) error {
+	timer := time.NewTimer(abandonGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case handlerErr := <-done:
+		return handlerErr
+	case <-timer.C:
+		detached.detach()
+		return err
+	}
+}
+
+// cleanupContext returns the context catch, compensation and finally
+// blocks run under: parent bounded by the cleanup budget
+func (f *Flow) cleanupContext(parent context.Context) (context.Context, context.CancelFunc) {
+	budget := f.cleanup
+	if budget <= 0 {
+		budget = f.timeout
+	}
+	if budget <= 0 {
+		return parent, func() {}
+	}
+	return context.WithTimeout(parent, budget)
+}
+
+// timeoutError builds the error reported when the flow deadline fires
+func (f *Flow) timeoutError(result *ExecutionResult, cause error) *errors.FrameworkError {
+	message := fmt.Sprintf("Flow '%s' timed out after %s", f.name, result.Duration.Round(time.Millisecond))
+	if result.TimedOutStep != "" {
+		message += fmt.Sprintf(" while running step '%s'", result.TimedOutStep)
+	}
+
+	return errors.NewTimeoutError(errors.ErrCodeExecutionTimeout, message).
+		WithContext("flow", f.name).
+		WithContext("step", result.TimedOutStep).
+		WithContext("timeout", f.timeout.String()).
+		WithCause(cause)
+}
+
+// stepTrackerKey is the Go context key of the execution's stepTracker
+type stepTrackerKey struct{}
+
+// stepTracker records which top-level step of an execution is running and
+// which compensable steps have completed
+type stepTracker struct {
+	mu        sync.Mutex
+	name      string
+	completed []*compensableStep
+}
+
+func (t *stepTracker) set(name string) {
+	t.mu.Lock()
+	t.name = name
+	t.mu.Unlock()
+}
+
+func (t *stepTracker) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.name
+}
+
+func (t *stepTracker) complete(step *compensableStep) {
+	t.mu.Lock()
+	t.completed = append(t.completed, step)
+	t.mu.Unlock()
+}
+
+// compensable returns the completed compensable steps in completion order
+func (t *stepTracker) compensable() []*compensableStep {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*compensableStep(nil), t.completed...)
+}
+
 // executeSteps executes all steps in the flow
 func (f *Flow) executeSteps(ctx interfaces.ExecutionContext) error {
+	var tracker *stepTracker
+	if goCtx := ctx.Context(); goCtx != nil {
+		tracker, _ = goCtx.Value(stepTrackerKey{}).(*stepTracker)
+	}
+
 	for i, step := range f.steps {
 		stepStart := time.Now()
+		if tracker != nil {
+			tracker.set(step.Name())
+		}
 
 		ctx.Logger().Info("Executing step",
 			zap.String("flow", f.name),
@@ -172,7 +394,7 @@ func (f *Flow) executeSteps(ctx interfaces.ExecutionContext) error {
 			zap.Int("step_index", i),
 			zap.String("execution_id", ctx.ExecutionID()))
 
-		err := step.Run(ctx)
+		err := runStep(ctx, step)
 		stepDuration := time.Since(stepStart)
 
 		if err != nil {
@@ -182,7 +404,11 @@ func (f *Flow) executeSteps(ctx interfaces.ExecutionContext) error {
 				zap.Int("step_index", i),
 				zap.Duration("duration", stepDuration),
 				zap.Error(err))
-			return fmt.Errorf("step '%s' failed: %w", step.Name(), err)
+			return newStepError(step.Name(), err)
+		}
+
+		if cs, ok := step.(*compensableStep); ok && tracker != nil {
+			tracker.complete(cs)
 		}
 
 		ctx.Logger().Info("Step execution completed",
@@ -242,7 +468,7 @@ func (cb *ChoiceBuilder) Otherwise() *OtherwiseBuilder {
 func (cb *ChoiceBuilder) EndChoice() *Flow {
 	// Create choice step
 	choiceStep := &choiceStep{
-		BaseStep:  NewBaseStep(cb.name, fmt.Sprintf("Choice: %s", cb.name)),
+		BaseStep:  newCompositeBaseStep(cb.name, fmt.Sprintf("Choice: %s", cb.name)),
 		branches:  cb.branches,
 		otherwise: cb.otherwise,
 	}
@@ -345,9 +571,63 @@ func (ob *OtherwiseBuilder) EndChoice() *Flow {
 
 // ParallelBuilder builds parallel execution blocks
 type ParallelBuilder struct {
-	flow  *Flow
-	name  string
-	steps []interfaces.Step
+	flow        *Flow
+	name        string
+	steps       []interfaces.Step
+	mergePolicy MergePolicy
+	mergeFunc   MergeFunc
+
+	failurePolicy FailurePolicy
+	quorum        int
+
+	maxConcurrency int
+}
+
+// Merge sets how branch writes are merged into the flow context; the
+// default is MergeWritten
+func (pb *ParallelBuilder) Merge(policy MergePolicy) *ParallelBuilder {
+	pb.mergePolicy = policy
+	return pb
+}
+
+// MergeWith merges branch results with a custom function instead of a
+// merge policy
+func (pb *ParallelBuilder) MergeWith(fn MergeFunc) *ParallelBuilder {
+	pb.mergeFunc = fn
+	return pb
+}
+
+// FailFast cancels the remaining branches as soon as one fails
+func (pb *ParallelBuilder) FailFast() *ParallelBuilder {
+	pb.failurePolicy = FailFast
+	return pb
+}
+
+// WaitAll waits for every branch and aggregates their failures. This is
+// the default.
+func (pb *ParallelBuilder) WaitAll() *ParallelBuilder {
+	pb.failurePolicy = FailWaitAll
+	return pb
+}
+
+// Quorum succeeds once n branches have succeeded, cancelling the rest
+func (pb *ParallelBuilder) Quorum(n int) *ParallelBuilder {
+	pb.failurePolicy = FailQuorum
+	pb.quorum = n
+	return pb
+}
+
+// BestEffort never fails the block; branch failures are recorded in the
+// context under FailuresKey(name)
+func (pb *ParallelBuilder) BestEffort() *ParallelBuilder {
+	pb.failurePolicy = FailBestEffort
+	return pb
+}
+
+// MaxConcurrency limits how many branches of the block run at once
+func (pb *ParallelBuilder) MaxConcurrency(n int) *ParallelBuilder {
+	pb.maxConcurrency = n
+	return pb
 }
 
 // Step adds a step to the parallel block
@@ -369,10 +649,45 @@ func (pb *ParallelBuilder) StepFunc(name string, fn func(interfaces.ExecutionCon
 // EndParallel completes the parallel block
 func (pb *ParallelBuilder) EndParallel() *Flow {
 	parallelStep := NewParallelStep(pb.name, pb.steps...)
+	if pb.mergePolicy != "" {
+		parallelStep.WithMergePolicy(pb.mergePolicy)
+	}
+	if pb.mergeFunc != nil {
+		parallelStep.WithMergeFunc(pb.mergeFunc)
+	}
+	if pb.failurePolicy == FailQuorum {
+		parallelStep.WithQuorum(pb.quorum)
+	} else if pb.failurePolicy != "" {
+		parallelStep.WithFailurePolicy(pb.failurePolicy)
+	}
+	parallelStep.WithMaxConcurrency(pb.maxConcurrency)
 	pb.flow.steps = append(pb.flow.steps, parallelStep)
 	return pb.flow
 }
 
+// ChoiceBranch pairs a condition with the steps to run when it holds
+type ChoiceBranch struct {
+	Condition func(interfaces.ExecutionContext) bool
+	Steps     []interfaces.Step
+}
+
+// NewChoiceStep creates a choice step outside the fluent DSL, for example
+// when a flow is assembled from a declarative definition. Branches are
+// evaluated in order; otherwise may be nil.
+func NewChoiceStep(name string, branches []ChoiceBranch, otherwise interfaces.Step) interfaces.Step {
+	cs := &choiceStep{
+		BaseStep:  newCompositeBaseStep(name, fmt.Sprintf("Choice: %s", name)),
+		otherwise: otherwise,
+	}
+	for _, branch := range branches {
+		cs.branches = append(cs.branches, conditionalBranch{
+			condition: branch.Condition,
+			steps:     branch.Steps,
+		})
+	}
+	return cs
+}
+
 // choiceStep implements conditional execution
 type choiceStep struct {
 	*BaseStep
@@ -389,7 +704,7 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 	}
 
 	// Check each branch condition
-	for _, branch := range cs.branches {
+	for i, branch := range cs.branches {
 		// Check for cancellation before evaluating condition
 		select {
 		case <-ctx.Context().Done():
@@ -400,6 +715,7 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 		if branch.condition(ctx) {
 			ctx.Logger().Info("Choice condition met",
 				zap.String("choice", cs.Name()))
+			cs.traceNotTaken(ctx, i)
 
 			// Execute branch steps
 			for _, step := range branch.steps {
@@ -410,7 +726,7 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 				default:
 				}
 
-				if err := step.Run(ctx); err != nil {
+				if err := runStep(ctx, step); err != nil {
 					return err
 				}
 			}
@@ -419,6 +735,7 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 	}
 
 	// Execute otherwise branch if no condition matched
+	cs.traceNotTaken(ctx, -1)
 	if cs.otherwise != nil {
 		// Check for cancellation before otherwise branch
 		select {
@@ -429,7 +746,7 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 
 		ctx.Logger().Info("Choice executing otherwise branch",
 			zap.String("choice", cs.Name()))
-		return cs.otherwise.Run(ctx)
+		return runStep(ctx, cs.otherwise)
 	}
 
 	ctx.Logger().Info("Choice no condition matched, no otherwise branch",
@@ -437,6 +754,19 @@ func (cs *choiceStep) Run(ctx interfaces.ExecutionContext) error {
 	return nil
 }
 
+// traceNotTaken records the steps of every branch other than taken as
+// skipped; taken is -1 when the otherwise branch runs
+func (cs *choiceStep) traceNotTaken(ctx interfaces.ExecutionContext, taken int) {
+	for i, branch := range cs.branches {
+		if i != taken {
+			traceSkipped(ctx, branch.steps...)
+		}
+	}
+	if taken >= 0 && cs.otherwise != nil {
+		traceSkipped(ctx, cs.otherwise)
+	}
+}
+
 // namedStep wraps a step with a custom name
 type namedStep struct {
 	interfaces.Step
@@ -456,6 +786,36 @@ type ExecutionResult struct {
 	Success   bool
 	Error     error
 	Context   interfaces.ExecutionContext
+
+	// TimedOut is set when the flow deadline fired; TimedOutStep names the
+	// step that was running at the time
+	TimedOut     bool
+	TimedOutStep string
+
+	// FailedStep names the step whose failure stopped the flow; Recovered
+	// is set when an OnError handler or a swallowing Catch block handled it
+	FailedStep string
+	Recovered  bool
+
+	// Compensations lists the compensations run after a failure, in the
+	// order they ran (the reverse of step completion)
+	Compensations []CompensationResult
+
+	// Steps is the per-step trace, including nested steps and the steps of
+	// error handling blocks
+	Steps []StepResult
+
+	// Overwrites lists context keys written by more than one step, as found
+	// by the flow's write check
+	Overwrites []Overwrite
+
+	// History is the change history of the context, set when the flow or
+	// the context records one (see Flow.WithHistory)
+	History *ContextHistory
+
+	// Debug makes GetResponse include Steps and History; it defaults to the
+	// flow's WithDebug setting
+	Debug bool
 }
 
 // GetResponse returns the context data as a response
@@ -470,6 +830,36 @@ func (er *ExecutionResult) GetResponse() map[string]interface{} {
 	if er.Error != nil {
 		response["error"] = er.Error.Error()
 	}
+	if er.TimedOut {
+		response["timed_out"] = true
+		response["timed_out_step"] = er.TimedOutStep
+	}
+	if er.FailedStep != "" {
+		response["failed_step"] = er.FailedStep
+		response["recovered"] = er.Recovered
+	}
+	if len(er.Compensations) > 0 {
+		compensations := make([]map[string]interface{}, 0, len(er.Compensations))
+		for _, c := range er.Compensations {
+			entry := map[string]interface{}{
+				"step":         c.Step,
+				"compensation": c.Compensation,
+				"success":      c.Success(),
+				"duration_ms":  c.Duration.Milliseconds(),
+			}
+			if c.Error != nil {
+				entry["error"] = c.Error.Error()
+			}
+			compensations = append(compensations, entry)
+		}
+		response["compensations"] = compensations
+	}
+	if er.Debug {
+		response["steps"] = stepResultsResponse(er.Steps)
+		if er.History != nil {
+			response["context_history"] = er.History
+		}
+	}
 
 	// Add context data
 	if flowCtx, ok := er.Context.(*Context); ok {
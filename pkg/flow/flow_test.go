@@ -1,10 +1,14 @@
 package flow
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/expr"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"go.uber.org/zap"
 )
@@ -500,6 +504,40 @@ func TestChoiceBuilder_NoOtherwise(t *testing.T) {
 	}
 }
 
+func TestChoiceBuilder_Expression(t *testing.T) {
+	flow := NewFlow("test_flow").
+		Choice("route").
+		When(expr.MustCompile(`user.tier == "gold" && len(cart.items) > 1`).Condition()).
+		StepFunc("vip", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("lane", "vip")
+			return nil
+		}).
+		Otherwise().
+		StepFunc("standard", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("lane", "standard")
+			return nil
+		}).
+		EndChoice()
+
+	for _, tt := range []struct {
+		items []interface{}
+		want  string
+	}{
+		{[]interface{}{"a", "b"}, "vip"},
+		{[]interface{}{"a"}, "standard"},
+	} {
+		ctx := NewContext().WithLogger(zap.NewNop())
+		ctx.Set("user", map[string]interface{}{"tier": "gold"})
+		ctx.Set("cart", map[string]interface{}{"items": tt.items})
+		if _, err := flow.Execute(ctx); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if lane, _ := ctx.GetString("lane"); lane != tt.want {
+			t.Errorf("lane = %q, want %q", lane, tt.want)
+		}
+	}
+}
+
 func TestParallelBuilder(t *testing.T) {
 	flow := NewFlow("test_flow")
 	step1Executed := false
@@ -714,3 +752,343 @@ func TestChoiceStep_Run(t *testing.T) {
 		t.Errorf("Executed = %v, want 'otherwise'", executed)
 	}
 }
+
+// slowStep blocks until its deadline fires
+func slowStep(name string, timeout time.Duration) *DelayStep {
+	step := NewDelayStep(name, 5*time.Second)
+	step.WithTimeout(timeout)
+	return step
+}
+
+// assertStepTimeout checks err is a step timeout naming stepName
+func assertStepTimeout(t *testing.T, err error, stepName string) {
+	t.Helper()
+
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) {
+		t.Fatalf("Error = %v, want a FrameworkError", err)
+	}
+	if fwErr.Type != frameworkErrors.ErrorTypeTimeout || fwErr.Code != frameworkErrors.ErrCodeExecutionTimeout {
+		t.Errorf("Error type/code = %v/%v, want timeout/%v", fwErr.Type, fwErr.Code, frameworkErrors.ErrCodeExecutionTimeout)
+	}
+	if fwErr.Context["step"] != stepName {
+		t.Errorf("Timed out step = %v, want %v", fwErr.Context["step"], stepName)
+	}
+}
+
+func TestFlow_Execute_StepTimeout(t *testing.T) {
+	flow := NewFlow("timeout_flow").
+		Step("slow", slowStep("slow", 30*time.Millisecond)).
+		StepFunc("after", func(ctx interfaces.ExecutionContext) error {
+			t.Error("Steps after a timed out step should not run")
+			return nil
+		})
+
+	start := time.Now()
+	_, err := flow.Execute(NewContext().WithLogger(zap.NewNop()))
+
+	if time.Since(start) > time.Second {
+		t.Error("Step timeout was not enforced")
+	}
+	assertStepTimeout(t, err, "slow")
+}
+
+func TestFlow_Execute_DefaultStepTimeout(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Timeouts.StepExecution = 30 * time.Millisecond
+	ctx := NewContextWithConfig(cfg).WithLogger(zap.NewNop())
+
+	// StepFunc declares no timeout, so the configured default applies
+	flow := NewFlow("default_timeout_flow").
+		StepFunc("blocking", func(ctx interfaces.ExecutionContext) error {
+			select {
+			case <-ctx.Context().Done():
+				return ctx.Context().Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		})
+
+	_, err := flow.Execute(ctx)
+	assertStepTimeout(t, err, "blocking")
+
+	if ctx.Context().Err() != nil {
+		t.Error("Flow context should not be cancelled by a step timeout")
+	}
+}
+
+func TestFlow_Execute_StepWithinTimeout(t *testing.T) {
+	step := NewDelayStep("quick", 10*time.Millisecond)
+	step.WithTimeout(time.Second)
+
+	_, err := NewFlow("quick_flow").Step("quick", step).Execute(NewContext().WithLogger(zap.NewNop()))
+	if err != nil {
+		t.Errorf("Execute failed: %v", err)
+	}
+}
+
+func TestParallelStep_BranchTimeout(t *testing.T) {
+	flow := NewFlow("parallel_timeout").
+		Parallel("fanout").
+		StepFunc("fast", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("fast", true)
+			return nil
+		}).
+		Step("slow", slowStep("slow", 30*time.Millisecond)).
+		EndParallel()
+
+	ctx := NewContext().WithLogger(zap.NewNop())
+	_, err := flow.Execute(ctx)
+
+	assertStepTimeout(t, err, "slow")
+	if !ctx.Has("fast") {
+		t.Error("Fast branch result should still be merged")
+	}
+}
+
+func TestParallelStep_ParentDeadline(t *testing.T) {
+	parallel := NewParallelStep("fanout", slowStep("slow", 5*time.Second))
+	parallel.WithTimeout(30 * time.Millisecond)
+
+	start := time.Now()
+	_, err := NewFlow("parent_deadline").Step("fanout", parallel).Execute(NewContext().WithLogger(zap.NewNop()))
+
+	if time.Since(start) > time.Second {
+		t.Error("Branch should not outlive the parallel step's deadline")
+	}
+	assertStepTimeout(t, err, "fanout")
+}
+
+func TestSequentialStep_ChildTimeout(t *testing.T) {
+	sequence := NewSequentialStep("sequence",
+		slowStep("slow", 30*time.Millisecond),
+		StepFunc(func(ctx interfaces.ExecutionContext) error {
+			t.Error("Steps after a timed out step should not run")
+			return nil
+		}),
+	)
+
+	_, err := NewFlow("sequential_timeout").Step("sequence", sequence).Execute(NewContext().WithLogger(zap.NewNop()))
+	assertStepTimeout(t, err, "slow")
+}
+
+func TestChoiceStep_BranchTimeout(t *testing.T) {
+	flow := NewFlow("choice_timeout").
+		Choice("route").
+		When(func(ctx interfaces.ExecutionContext) bool { return true }).
+		Step("slow", slowStep("slow", 30*time.Millisecond)).
+		EndChoice()
+
+	_, err := flow.Execute(NewContext().WithLogger(zap.NewNop()))
+	assertStepTimeout(t, err, "slow")
+}
+
+func TestFlow_Execute_CompositeStepsHaveNoDefaultTimeout(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Timeouts.StepExecution = 40 * time.Millisecond
+
+	attempts := 0
+	flaky := StepFunc(func(ctx interfaces.ExecutionContext) error {
+		if attempts++; attempts < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	item := StepFunc(func(ctx interfaces.ExecutionContext) error {
+		select {
+		case <-time.After(20 * time.Millisecond):
+			return nil
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+	})
+
+	// Each child fits in the default, but the retries and the loop as a
+	// whole do not
+	flow := NewFlow("composites").
+		Step("retry", NewRetryStep("retry", flaky, 2, 30*time.Millisecond)).
+		Step("each", NewForEachStep("each", "items", item)).
+		Step("sequence", NewSequentialStep("sequence",
+			NewDelayStep("first", 25*time.Millisecond),
+			NewDelayStep("second", 25*time.Millisecond)))
+
+	ctx := NewContextWithConfig(cfg).WithLogger(zap.NewNop())
+	ctx.Set("items", []interface{}{1, 2, 3})
+	if _, err := flow.Execute(ctx); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	// A leaf step that declares no timeout still gets the default
+	_, err := NewFlow("leaf").Step("slow", NewDelayStep("slow", 5*time.Second)).Execute(NewContextWithConfig(cfg).WithLogger(zap.NewNop()))
+	assertStepTimeout(t, err, "slow")
+}
+
+func TestFlow_Execute_FlowTimeout(t *testing.T) {
+	var branchErr error
+	branchDone := make(chan struct{})
+
+	flow := NewFlow("flow_timeout").
+		WithTimeout(50*time.Millisecond).
+		StepFunc("first", func(ctx interfaces.ExecutionContext) error { return nil }).
+		Parallel("fanout").
+		StepFunc("hung", func(ctx interfaces.ExecutionContext) error {
+			defer close(branchDone)
+			<-ctx.Context().Done()
+			branchErr = ctx.Context().Err()
+			return branchErr
+		}).
+		EndParallel()
+
+	start := time.Now()
+	result, err := flow.Execute(NewContext().WithLogger(zap.NewNop()))
+
+	if time.Since(start) > time.Second {
+		t.Fatal("Flow timeout was not enforced")
+	}
+	if !result.TimedOut || result.Success {
+		t.Error("Result should be marked as timed out")
+	}
+	if result.TimedOutStep != "fanout" {
+		t.Errorf("TimedOutStep = %v, want 'fanout'", result.TimedOutStep)
+	}
+
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Type != frameworkErrors.ErrorTypeTimeout {
+		t.Fatalf("Error = %v, want a timeout FrameworkError", err)
+	}
+	if fwErr.Context["flow"] != "flow_timeout" || fwErr.Context["step"] != "fanout" {
+		t.Errorf("Error context = %v", fwErr.Context)
+	}
+
+	select {
+	case <-branchDone:
+		if !errors.Is(branchErr, context.DeadlineExceeded) {
+			t.Errorf("Branch error = %v, want deadline exceeded", branchErr)
+		}
+	case <-time.After(time.Second):
+		t.Error("Parallel branch should observe the flow deadline")
+	}
+
+	response := result.GetResponse()
+	if response["timed_out"] != true || response["timed_out_step"] != "fanout" {
+		t.Errorf("Response should report the timeout, got %v", response)
+	}
+}
+
+func TestFlow_Execute_IgnoresCancellation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	flow := NewFlow("stubborn").
+		WithTimeout(30*time.Millisecond).
+		StepFunc("stubborn", func(ctx interfaces.ExecutionContext) error {
+			<-release
+			return nil
+		})
+
+	cfg := config.DefaultConfig()
+	cfg.Timeouts.StepExecution = 0
+	start := time.Now()
+	result, _ := flow.Execute(NewContextWithConfig(cfg).WithLogger(zap.NewNop()))
+
+	if time.Since(start) > time.Second {
+		t.Fatal("Execute should return at the deadline even if a step ignores it")
+	}
+	if !result.TimedOut || result.TimedOutStep != "stubborn" {
+		t.Errorf("TimedOut = %v, TimedOutStep = %v", result.TimedOut, result.TimedOutStep)
+	}
+}
+
+func TestFlow_Execute_AbandonedHandlerCannotWrite(t *testing.T) {
+	release := make(chan struct{})
+	exited := make(chan struct{})
+
+	flow := NewFlow("stubborn").
+		WithTimeout(30*time.Millisecond).
+		StepFunc("stubborn", func(ctx interfaces.ExecutionContext) error {
+			defer close(exited)
+			<-release
+			ctx.Set("late", true)
+			return nil
+		})
+
+	cfg := config.DefaultConfig()
+	cfg.Timeouts.StepExecution = 0
+	ctx := NewContextWithConfig(cfg).WithLogger(zap.NewNop())
+	result, _ := flow.Execute(ctx)
+	if !result.TimedOut {
+		t.Fatal("The flow should have timed out")
+	}
+
+	close(release)
+	<-exited
+	if ctx.Has("late") {
+		t.Error("Writes made after the flow was abandoned should be dropped")
+	}
+}
+
+func TestFlow_Execute_RespectsShorterIncomingDeadline(t *testing.T) {
+	incoming, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	flow := NewFlow("incoming_deadline").
+		WithTimeout(10*time.Second).
+		StepFunc("wait", func(ctx interfaces.ExecutionContext) error {
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		})
+
+	start := time.Now()
+	result, err := flow.Execute(NewContext().WithContext(incoming).WithLogger(zap.NewNop()))
+
+	if time.Since(start) > time.Second {
+		t.Fatal("Incoming deadline should bound the flow")
+	}
+	if err == nil || !result.TimedOut {
+		t.Errorf("Flow should time out, got err=%v timedOut=%v", err, result.TimedOut)
+	}
+}
+
+func TestFlow_Execute_IncomingCancellation(t *testing.T) {
+	incoming, cancel := context.WithCancel(context.Background())
+
+	flow := NewFlow("cancelled").
+		StepFunc("wait", func(ctx interfaces.ExecutionContext) error {
+			cancel()
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		})
+
+	result, err := flow.Execute(NewContext().WithContext(incoming).WithLogger(zap.NewNop()))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Error = %v, want context.Canceled", err)
+	}
+	if result.TimedOut {
+		t.Error("Cancellation should not be reported as a timeout")
+	}
+}
+
+func TestFlow_Execute_NoDeadlineLeak(t *testing.T) {
+	ctx := NewContext().WithLogger(zap.NewNop())
+
+	_, err := NewFlow("no_leak").
+		StepFunc("check", func(stepCtx interfaces.ExecutionContext) error {
+			if _, ok := stepCtx.Context().Deadline(); !ok {
+				t.Error("Steps should see the flow deadline")
+			}
+			stepCtx.Set("written", true)
+			return nil
+		}).
+		Execute(ctx)
+
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := ctx.Context().Deadline(); ok {
+		t.Error("The caller's context should not be modified")
+	}
+	if !ctx.Has("written") {
+		t.Error("Writes during execution should be visible on the caller's context")
+	}
+}
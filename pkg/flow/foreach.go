@@ -0,0 +1,342 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// ItemFailurePolicy decides what a ForEach step does when the steps for an
+// item fail
+type ItemFailurePolicy string
+
+const (
+	// ItemFail fails the ForEach step with the first item error; in parallel
+	// mode the remaining items are cancelled. This is the default.
+	ItemFail ItemFailurePolicy = "fail"
+
+	// ItemSkip leaves failed items out of the collected outputs
+	ItemSkip ItemFailurePolicy = "skip"
+
+	// ItemNull collects nil for failed items, so outputs stay aligned with
+	// the source slice
+	ItemNull ItemFailurePolicy = "null"
+)
+
+// ParseItemFailurePolicy validates an item failure policy name, e.g. from a
+// declarative flow definition
+func ParseItemFailurePolicy(name string) (ItemFailurePolicy, error) {
+	switch policy := ItemFailurePolicy(name); policy {
+	case ItemFail, ItemSkip, ItemNull:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown item failure policy '%s'", name)
+	}
+}
+
+// ForEachStep runs a list of steps once per element of a slice in the
+// context. Each item runs on a clone of the context with the element and its
+// index bound, and the per-item outputs are collected into the target key
+// in source order. Writes of the item steps do not reach the parent context.
+type ForEachStep struct {
+	*BaseStep
+	source   string
+	steps    []interfaces.Step
+	itemKey  string
+	indexKey string
+	collect  string
+	target   string
+	parallel bool
+	limit    int
+	onError  ItemFailurePolicy
+}
+
+// NewForEachStep creates a step iterating over the slice at source, a
+// dot-notation path such as "order.items". Outputs are stored under the
+// step name unless WithTarget is used.
+func NewForEachStep(name, source string, steps ...interfaces.Step) *ForEachStep {
+	return &ForEachStep{
+		BaseStep: newCompositeBaseStep(name, fmt.Sprintf("For each element of %s", source)),
+		source:   source,
+		steps:    steps,
+		itemKey:  "item",
+		indexKey: "index",
+		target:   name,
+		onError:  ItemFail,
+	}
+}
+
+// WithItemKey sets the context keys the item and its index are bound to;
+// they default to "item" and "index"
+func (s *ForEachStep) WithItemKey(itemKey, indexKey string) *ForEachStep {
+	s.itemKey = itemKey
+	s.indexKey = indexKey
+	return s
+}
+
+// WithCollect sets the key read from each item's context as its output.
+// By default the output is a map of every key the item steps wrote.
+func (s *ForEachStep) WithCollect(key string) *ForEachStep {
+	s.collect = key
+	return s
+}
+
+// WithTarget sets the key the collected outputs are stored under
+func (s *ForEachStep) WithTarget(key string) *ForEachStep {
+	s.target = key
+	return s
+}
+
+// WithParallel runs items concurrently, at most limit at a time; a limit of
+// 0 runs every item at once
+func (s *ForEachStep) WithParallel(limit int) *ForEachStep {
+	s.parallel = true
+	s.limit = limit
+	return s
+}
+
+// WithItemFailure sets what happens when the steps for an item fail
+func (s *ForEachStep) WithItemFailure(policy ItemFailurePolicy) *ForEachStep {
+	s.onError = policy
+	return s
+}
+
+// itemResult is the outcome of the steps for one item
+type itemResult struct {
+	output interface{}
+	err    error
+}
+
+func (s *ForEachStep) Run(ctx interfaces.ExecutionContext) error {
+	items, err := s.items(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make([]itemResult, len(items))
+	if s.parallel {
+		if err := s.runParallel(ctx, items, results); err != nil {
+			return err
+		}
+	} else {
+		// A cancelled or expired context fails the step whatever the item
+		// failure policy: the remaining items would only fail with it
+		for i, item := range items {
+			if err := ctx.Context().Err(); err != nil {
+				return err
+			}
+			results[i] = s.runItem(ctx, ctx.Context(), i, item)
+			if results[i].err == nil {
+				continue
+			}
+			if s.onError == ItemFail {
+				return results[i].err
+			}
+			if err := ctx.Context().Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	outputs := make([]interface{}, 0, len(items))
+	failures := make(map[string]interface{})
+	for i, result := range results {
+		if result.err == nil {
+			outputs = append(outputs, result.output)
+			continue
+		}
+		if s.onError == ItemFail {
+			return result.err
+		}
+		failures[strconv.Itoa(i)] = result.err.Error()
+		if s.onError == ItemNull {
+			outputs = append(outputs, nil)
+		}
+	}
+
+	ctx.Set(s.target, outputs)
+	if len(failures) > 0 {
+		ctx.Set(FailuresKey(s.Name()), failures)
+	}
+	return nil
+}
+
+// items resolves the source path to a slice
+func (s *ForEachStep) items(ctx interfaces.ExecutionContext) ([]interface{}, error) {
+	value, ok := values.Lookup(s.source, ctx)
+	if !ok {
+		return nil, errors.NewValidationError(errors.ErrCodeMissingField,
+			fmt.Sprintf("ForEach step '%s': source '%s' not found", s.Name(), s.source)).
+			WithContext("step", s.Name())
+	}
+
+	if items, ok := value.([]interface{}); ok {
+		return items, nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.NewValidationError(errors.ErrCodeInvalidFormat,
+			fmt.Sprintf("ForEach step '%s': source '%s' is a %T, not a slice", s.Name(), s.source, value)).
+			WithContext("step", s.Name())
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// runParallel runs the items concurrently under the step's limit. With
+// ItemFail the first failure cancels the items still queued or running and
+// is returned.
+func (s *ForEachStep) runParallel(ctx interfaces.ExecutionContext, items []interface{}, results []itemResult) error {
+	goCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	limiter := NewLimiter(s.Name(), s.limit)
+	done := make(chan int, len(items))
+
+	go func() {
+		for i, item := range items {
+			if err := limiter.Acquire(goCtx); err != nil {
+				results[i].err = err
+				done <- i
+				continue
+			}
+			go func(i int, item interface{}) {
+				defer func() { done <- i }()
+				itemGoCtx, release := limiter.Hold(goCtx)
+				defer release()
+				results[i] = s.runItem(ctx, itemGoCtx, i, item)
+			}(i, item)
+		}
+	}()
+
+	var first error
+	for range items {
+		i := <-done
+		if results[i].err == nil || s.onError != ItemFail || isCancellation(goCtx, results[i].err) {
+			continue
+		}
+		if first == nil {
+			first = results[i].err
+			cancel()
+		}
+	}
+	if first == nil {
+		// Cancelled by the parent context
+		first = ctx.Context().Err()
+	}
+	return first
+}
+
+// runItem runs the steps for one item on a clone of ctx carrying goCtx
+func (s *ForEachStep) runItem(ctx interfaces.ExecutionContext, goCtx context.Context, index int, item interface{}) itemResult {
+	if err := goCtx.Err(); err != nil {
+		return itemResult{err: err}
+	}
+
+	itemCtx := withGoContext(ctx.Clone(), goCtx)
+	itemCtx = withBranch(itemCtx, fmt.Sprintf("%s[%d]", s.Name(), index))
+
+	// The bindings are inputs, not writes of the item steps or of any
+	// enclosing parallel branch
+	bind := withBranchWrites(untraced(itemCtx), nil)
+	bind.Set(s.itemKey, item)
+	bind.Set(s.indexKey, index)
+
+	writes := &branchWrites{}
+	itemCtx = withBranchWrites(itemCtx, writes)
+
+	for _, step := range s.steps {
+		if err := runStep(itemCtx, step); err != nil {
+			itemCtx.Logger().Error("ForEach item failed",
+				zap.String("parent_step", s.Name()),
+				zap.String("step", step.Name()),
+				zap.Int("index", index),
+				zap.Error(err))
+			return itemResult{err: err}
+		}
+	}
+
+	if s.collect != "" {
+		output, _ := itemCtx.Get(s.collect)
+		return itemResult{output: output}
+	}
+	_, values := writes.result(itemCtx)
+	return itemResult{output: values}
+}
+
+// ForEach starts a block of steps run once per element of the slice at
+// sourcePath, a dot-notation path into the context
+func (f *Flow) ForEach(name, sourcePath string) *ForEachBuilder {
+	return &ForEachBuilder{
+		flow: f,
+		step: NewForEachStep(name, sourcePath),
+	}
+}
+
+// ForEachBuilder builds ForEach blocks
+type ForEachBuilder struct {
+	flow *Flow
+	step *ForEachStep
+}
+
+// As sets the context keys the item and its index are bound to
+func (fb *ForEachBuilder) As(itemKey, indexKey string) *ForEachBuilder {
+	fb.step.WithItemKey(itemKey, indexKey)
+	return fb
+}
+
+// Collect sets the key read from each item's context as its output
+func (fb *ForEachBuilder) Collect(key string) *ForEachBuilder {
+	fb.step.WithCollect(key)
+	return fb
+}
+
+// Into sets the key the collected outputs are stored under
+func (fb *ForEachBuilder) Into(key string) *ForEachBuilder {
+	fb.step.WithTarget(key)
+	return fb
+}
+
+// Parallel runs items concurrently, at most limit at a time (0 for no
+// limit)
+func (fb *ForEachBuilder) Parallel(limit int) *ForEachBuilder {
+	fb.step.WithParallel(limit)
+	return fb
+}
+
+// OnItemError sets what happens when the steps for an item fail
+func (fb *ForEachBuilder) OnItemError(policy ItemFailurePolicy) *ForEachBuilder {
+	fb.step.WithItemFailure(policy)
+	return fb
+}
+
+// Step adds a step run for every item
+func (fb *ForEachBuilder) Step(name string, step interfaces.Step) *ForEachBuilder {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	fb.step.steps = append(fb.step.steps, step)
+	return fb
+}
+
+// StepFunc adds a function step run for every item
+func (fb *ForEachBuilder) StepFunc(name string, fn func(interfaces.ExecutionContext) error) *ForEachBuilder {
+	fb.step.steps = append(fb.step.steps, &namedStep{Step: StepFunc(fn), name: name})
+	return fb
+}
+
+// EndForEach completes the block
+func (fb *ForEachBuilder) EndForEach() *Flow {
+	fb.flow.steps = append(fb.flow.steps, fb.step)
+	return fb.flow
+}
@@ -0,0 +1,267 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// orderContext returns a context holding an order with the given items
+func orderContext(items ...interface{}) *Context {
+	ctx := newTestContext()
+	ctx.Set("order", map[string]interface{}{"items": items})
+	return ctx
+}
+
+// productLookup reads the bound item and stores a product for it
+func productLookup(ctx interfaces.ExecutionContext) error {
+	item, _ := ctx.Get("item")
+	index, _ := ctx.Get("index")
+	id := item.(map[string]interface{})["product_id"]
+	ctx.Set("product", fmt.Sprintf("%v@%v", id, index))
+	return nil
+}
+
+func TestForEach_CollectsInOrder(t *testing.T) {
+	ctx := orderContext(
+		map[string]interface{}{"product_id": "p1"},
+		map[string]interface{}{"product_id": "p2"},
+		map[string]interface{}{"product_id": "p3"},
+	)
+
+	result, err := NewFlow("order_details").
+		ForEach("products", "order.items").
+		StepFunc("lookup", productLookup).
+		Collect("product").
+		EndForEach().
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	products, _ := result.Context.Get("products")
+	want := []interface{}{"p1@0", "p2@1", "p3@2"}
+	if !reflect.DeepEqual(products, want) {
+		t.Errorf("products = %v, want %v", products, want)
+	}
+	if ctx.Has("product") || ctx.Has("item") || ctx.Has("index") {
+		t.Error("Item keys should not leak into the parent context")
+	}
+}
+
+func TestForEach_ParallelPreservesOrder(t *testing.T) {
+	var running, peak int32
+	ctx := newTestContext()
+	ctx.Set("ids", []int{1, 2, 3, 4, 5, 6, 7, 8})
+
+	step := NewForEachStep("squares", "ids",
+		&mockStep{name: "square", runFunc: func(ctx interfaces.ExecutionContext) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+
+			item, _ := ctx.Get("item")
+			// Later items finish first
+			time.Sleep(time.Duration(10-item.(int)) * time.Millisecond)
+			ctx.Set("square", item.(int)*item.(int))
+			return nil
+		}},
+	).WithParallel(3).WithTarget("results")
+
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	results, _ := ctx.Get("results")
+	want := []interface{}{
+		map[string]interface{}{"square": 1}, map[string]interface{}{"square": 4},
+		map[string]interface{}{"square": 9}, map[string]interface{}{"square": 16},
+		map[string]interface{}{"square": 25}, map[string]interface{}{"square": 36},
+		map[string]interface{}{"square": 49}, map[string]interface{}{"square": 64},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %v, want the written keys in source order", results)
+	}
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Errorf("Peak concurrency = %d, want at most 3", p)
+	}
+}
+
+// failOn returns a step failing for the given item and storing the item
+// under "out" otherwise
+func failOn(bad interface{}) interfaces.Step {
+	return &mockStep{name: "process", runFunc: func(ctx interfaces.ExecutionContext) error {
+		item, _ := ctx.Get("item")
+		if item == bad {
+			return fmt.Errorf("item %v failed", item)
+		}
+		ctx.Set("out", item)
+		return nil
+	}}
+}
+
+func TestForEach_ItemFailurePolicies(t *testing.T) {
+	tests := []struct {
+		policy ItemFailurePolicy
+		want   []interface{}
+	}{
+		{ItemSkip, []interface{}{"a", "c"}},
+		{ItemNull, []interface{}{"a", nil, "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			ctx := orderContext("a", "b", "c")
+			step := NewForEachStep("lines", "order.items", failOn("b")).
+				WithCollect("out").
+				WithItemFailure(tt.policy)
+
+			if err := step.Run(ctx); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got, _ := ctx.Get("lines"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %v, want %v", got, tt.want)
+			}
+			failures, _ := ctx.Get(FailuresKey("lines"))
+			if failures.(map[string]interface{})["1"] != "item b failed" {
+				t.Errorf("failures = %v, want index 1 recorded", failures)
+			}
+		})
+	}
+}
+
+func TestForEach_FailCancelsRemainingItems(t *testing.T) {
+	observed := make(chan error, 2)
+	ctx := orderContext("bad", "slow", "slow")
+
+	step := NewForEachStep("lines", "order.items",
+		&mockStep{name: "process", runFunc: func(ctx interfaces.ExecutionContext) error {
+			if item, _ := ctx.Get("item"); item == "bad" {
+				time.Sleep(5 * time.Millisecond)
+				return errors.New("bad item")
+			}
+			return blocking("slow", observed).Run(ctx)
+		}},
+	).WithParallel(0)
+
+	start := time.Now()
+	err := step.Run(ctx)
+	if err == nil || err.Error() != "bad item" {
+		t.Fatalf("Run() error = %v, want the item error", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Remaining items should be cancelled")
+	}
+	for i := 0; i < 2; i++ {
+		if item := <-observed; !errors.Is(item, context.Canceled) {
+			t.Errorf("Item observed %v, want context.Canceled", item)
+		}
+	}
+	if ctx.Has("lines") {
+		t.Error("No outputs should be stored when the step fails")
+	}
+}
+
+func TestForEach_SequentialStopsOnFailure(t *testing.T) {
+	var runs int32
+	ctx := orderContext("a", "b", "c")
+
+	step := NewForEachStep("lines", "order.items",
+		&mockStep{name: "count", runFunc: func(ctx interfaces.ExecutionContext) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}},
+		failOn("b"),
+	)
+	if err := step.Run(ctx); err == nil {
+		t.Fatal("Run() should fail")
+	}
+	if runs != 2 {
+		t.Errorf("Items run = %d, want 2", runs)
+	}
+}
+
+func TestForEach_SequentialCancellation(t *testing.T) {
+	for _, policy := range []ItemFailurePolicy{ItemSkip, ItemNull} {
+		goCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var runs int32
+		ctx := orderContext("a", "b", "c").WithContext(goCtx)
+		step := NewForEachStep("lines", "order.items",
+			&mockStep{name: "cancel_on_b", runFunc: func(ctx interfaces.ExecutionContext) error {
+				atomic.AddInt32(&runs, 1)
+				if item, _ := ctx.Get("item"); item == "b" {
+					cancel()
+					return ctx.Context().Err()
+				}
+				return nil
+			}},
+		).WithItemFailure(policy)
+
+		if err := step.Run(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: Run() error = %v, want context.Canceled", policy, err)
+		}
+		if runs != 2 {
+			t.Errorf("%s: Items run = %d, want 2", policy, runs)
+		}
+		if ctx.Has("lines") {
+			t.Errorf("%s: No results should be stored", policy)
+		}
+	}
+}
+
+func TestForEach_InvalidSource(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("order", map[string]interface{}{"items": "not a list"})
+
+	var fwErr *frameworkErrors.FrameworkError
+	err := NewForEachStep("lines", "order.items").Run(ctx)
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeInvalidFormat {
+		t.Errorf("Run() error = %v, want an invalid format error", err)
+	}
+
+	err = NewForEachStep("lines", "order.missing").Run(ctx)
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeMissingField {
+		t.Errorf("Run() error = %v, want a missing field error", err)
+	}
+}
+
+func TestForEach_InsideParallelBranch(t *testing.T) {
+	ctx := orderContext("a", "b")
+
+	step := NewParallelStep("fanout",
+		NewForEachStep("lines", "order.items", failOn("never")).WithCollect("out").WithItemKey("line", "n"),
+		writer("other", 0, map[string]interface{}{"other": true}),
+	)
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The item binding must not be merged back as a branch write
+	if ctx.Has("line") || ctx.Has("n") {
+		t.Error("Item bindings leaked out of the parallel branch")
+	}
+	if !ctx.Has("lines") || !ctx.Has("other") {
+		t.Error("Branch outputs should be merged")
+	}
+}
+
+func TestForEach_ParentCancellation(t *testing.T) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctx := orderContext("a").WithContext(goCtx)
+	err := NewForEachStep("lines", "order.items", failOn("never")).WithParallel(0).WithItemFailure(ItemSkip).Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
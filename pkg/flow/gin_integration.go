@@ -1,7 +1,7 @@
 package flow
 
 import (
-	"context"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,6 +10,11 @@ import (
 	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
 )
 
+// RequestHeadersKey is the context key under which NewContextFromGin stores
+// the incoming request headers as an http.Header. The header() function of
+// pkg/expr reads them from there.
+const RequestHeadersKey = "request_headers"
+
 // NewContextFromGin creates a flow context from a Gin context
 // This ensures proper context cancellation propagation from HTTP requests
 func NewContextFromGin(c *gin.Context, cfg *config.FrameworkConfig) *Context {
@@ -25,19 +30,24 @@ func NewContextFromGin(c *gin.Context, cfg *config.FrameworkConfig) *Context {
 		}
 	}
 
-	// Create a background context with timeout instead of using request context
-	// The request context gets canceled too early, before flow execution completes
-	baseCtx := context.Background()
+	// Derive from the request context so a client disconnect or a deadline
+	// set by an upstream proxy cancels the flow. Flow.Execute adds the flow
+	// timeout on top of it. The caller's request ID, trace context and
+	// baggage are carried along for upstream calls.
+	baseCtx, requestID := ExtractRequest(c, cfg)
 
 	ctx := &Context{
-		ctx:         baseCtx, // Use background context without timeout for now
+		ctx:         baseCtx,
 		values:      make(map[string]interface{}),
-		executionID: generateExecutionID(),
+		mu:          &sync.RWMutex{},
+		executionID: generateExecutionID(cfg),
 		startTime:   startTime,
 		timeout:     cfg.Timeouts.FlowExecution,
 		config:      cfg,
 	}
 
+	ctx.Set("request_id", requestID)
+
 	// Extract common values from Gin context
 	if userID := c.Param("userId"); userID != "" {
 		ctx.Set("user_id", userID)
@@ -46,7 +56,9 @@ func NewContextFromGin(c *gin.Context, cfg *config.FrameworkConfig) *Context {
 		ctx.Set("user_id", userID)
 	}
 
-	// Extract headers
+	// Extract headers. The full set is kept apart from the "headers" key,
+	// which steps use for response headers.
+	ctx.Set(RequestHeadersKey, c.Request.Header.Clone())
 	ctx.Set("device_type", c.GetHeader("X-Device-Type"))
 	ctx.Set("platform", c.GetHeader("X-Platform"))
 	ctx.Set("app_version", c.GetHeader("X-App-Version"))
@@ -56,6 +68,12 @@ func NewContextFromGin(c *gin.Context, cfg *config.FrameworkConfig) *Context {
 	return ctx
 }
 
+// RequestID returns the ID of the request the context was created for, if
+// any
+func (c *Context) RequestID() string {
+	return RequestIDFromContext(c.ctx)
+}
+
 // NewContextFromGinWithLogger creates a flow context from Gin with logger
 func NewContextFromGinWithLogger(c *gin.Context, cfg *config.FrameworkConfig, logger *zap.Logger) *Context {
 	ctx := NewContextFromGin(c, cfg)
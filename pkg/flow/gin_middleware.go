@@ -2,7 +2,6 @@ package flow
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,15 +21,17 @@ func FlowContextMiddleware(cfg *config.FrameworkConfig, logger *zap.Logger) gin.
 		// Set request metadata
 		flowCtx.Set("request_method", c.Request.Method)
 		flowCtx.Set("request_path", c.Request.URL.Path)
-		flowCtx.Set("request_id", generateRequestID())
 		flowCtx.Set("start_time", time.Now())
 
-		// Store flow context in Gin context for handlers to access
+		// Store flow context in Gin context for handlers to access, and
+		// share the propagated request context with them
 		c.Set("flow_context", flowCtx)
+		c.Request = c.Request.WithContext(flowCtx.Context())
 
 		// Add request ID to response headers
-		requestID, _ := flowCtx.GetString("request_id")
-		c.Header("X-Request-ID", requestID)
+		requestID := flowCtx.RequestID()
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
 
 		logger.Debug("Flow context created",
 			zap.String("request_id", requestID),
@@ -72,16 +73,25 @@ func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	}
 }
 
-// RequestIDMiddleware adds unique request ID to each request
+// RequestIDMiddleware adds unique request ID to each request. The ID is
+// stored in the Gin context and in the request's Go context, where
+// FlowContextMiddleware and NewContextFromGin pick it up.
 func RequestIDMiddleware() gin.HandlerFunc {
+	return RequestIDMiddlewareWithConfig(nil)
+}
+
+// RequestIDMiddlewareWithConfig is like RequestIDMiddleware, generating IDs
+// with the ID settings of cfg
+func RequestIDMiddlewareWithConfig(cfg *config.FrameworkConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(RequestIDHeader)
 		if requestID == "" {
-			requestID = generateRequestID()
+			requestID = generateRequestID(cfg)
 		}
 
 		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
 
 		c.Next()
 	}
@@ -150,6 +160,6 @@ func GetFlowContext(c *gin.Context) (interfaces.ExecutionContext, bool) {
 }
 
 // generateRequestID creates a unique request ID
-func generateRequestID() string {
-	return fmt.Sprintf("req_%d", time.Now().UnixNano())
+func generateRequestID(cfg *config.FrameworkConfig) string {
+	return "req_" + newID(cfg)
 }
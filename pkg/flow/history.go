@@ -0,0 +1,302 @@
+package flow
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// Operations recorded in ContextChange
+const (
+	ChangeSet    = "set"
+	ChangeDelete = "delete"
+)
+
+// Kinds of ContextDiff
+const (
+	DiffAdded   = "added"
+	DiffChanged = "changed"
+	DiffRemoved = "removed"
+)
+
+// maxHistoryChanges bounds the changes kept by a history, so that a long
+// loop cannot grow it without limit; later changes are counted as dropped
+const maxHistoryChanges = 10000
+
+// ContextChange records one Set or Delete on a context recording its
+// history. Values are sanitized copies taken at the time of the change.
+type ContextChange struct {
+	Seq   int         `json:"seq"`
+	Time  time.Time   `json:"time"`
+	Op    string      `json:"op"`
+	Key   string      `json:"key"`
+	Value interface{} `json:"value,omitempty"`
+
+	// Step is the step that made the change and Path the steps enclosing
+	// it, outermost first and ending with Step; both are empty for changes
+	// made outside any step, such as flow inputs and parallel merges
+	Step string   `json:"step,omitempty"`
+	Path []string `json:"path,omitempty"`
+
+	// Flow is the flow name of the context, which sub-flow steps set to
+	// the child flow; Branch is the parallel branch the change was made
+	// in, and Scoped is set for changes that stayed in a scope
+	Flow   string `json:"flow,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Scoped bool   `json:"scoped,omitempty"`
+}
+
+// ContextDiff is the change of one key between two states of a context
+type ContextDiff struct {
+	Key    string      `json:"key"`
+	Kind   string      `json:"kind"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ContextHistory is the change history of a context, enabled with
+// Context.RecordHistory or Flow.WithHistory. It records every Set and
+// Delete with the step that made it, including changes made in parallel
+// branches, scopes and sub-flows, so that a wrong response can be traced
+// back to the step that set or overwrote a key. Values under sensitive keys
+// are redacted as values.Sanitize does.
+type ContextHistory struct {
+	mu      sync.Mutex
+	initial map[string]interface{}
+	changes []ContextChange
+	dropped int
+}
+
+// RecordHistory starts recording the context's changes, taking the current
+// values as the initial state. It does nothing if the context is already
+// recording.
+func (c *Context) RecordHistory() *Context {
+	if c.History() != nil {
+		return c
+	}
+	history := &ContextHistory{
+		initial: values.Sanitize(c.ToMap()).(map[string]interface{}),
+	}
+
+	c.mu.Lock()
+	c.history = history
+	c.mu.Unlock()
+	return c
+}
+
+// History returns the context's change history, or nil when it is not
+// recording
+func (c *Context) History() *ContextHistory {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.history
+}
+
+// contextHistory returns the history ctx records into, if any
+func contextHistory(ctx interfaces.ExecutionContext) *ContextHistory {
+	if c, ok := ctx.(*Context); ok {
+		return c.History()
+	}
+	return nil
+}
+
+// setHistory replaces the history ctx records into and returns the previous
+// one
+func setHistory(ctx interfaces.ExecutionContext, history *ContextHistory) *ContextHistory {
+	c, ok := ctx.(*Context)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.history
+	c.history = history
+	return previous
+}
+
+// record adds a change made through c
+func (h *ContextHistory) record(c *Context, op, key string, value interface{}) {
+	change := ContextChange{
+		Time:   time.Now(),
+		Op:     op,
+		Key:    key,
+		Flow:   c.flowName,
+		Scoped: c.parent != nil,
+	}
+	if op == ChangeSet {
+		if values.IsSensitiveKey(key) {
+			change.Value = "***"
+		} else {
+			change.Value = values.Sanitize(value)
+		}
+	}
+	if goCtx := c.ctx; goCtx != nil {
+		change.Branch, _ = goCtx.Value(traceBranchKey{}).(string)
+		change.Path = tracePath(goCtx)
+		if len(change.Path) > 0 {
+			change.Step = change.Path[len(change.Path)-1]
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.changes) >= maxHistoryChanges {
+		h.dropped++
+		return
+	}
+	change.Seq = len(h.changes) + 1
+	h.changes = append(h.changes, change)
+}
+
+// Initial returns the sanitized values the context held when recording
+// started
+func (h *ContextHistory) Initial() map[string]interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	initial := make(map[string]interface{}, len(h.initial))
+	for k, v := range h.initial {
+		initial[k] = v
+	}
+	return initial
+}
+
+// Changes returns the recorded changes in the order they were made
+func (h *ContextHistory) Changes() []ContextChange {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]ContextChange(nil), h.changes...)
+}
+
+// Dropped returns the number of changes not recorded because the history
+// was full
+func (h *ContextHistory) Dropped() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dropped
+}
+
+// StepChanges returns the changes made by step, including those made by
+// steps nested in it
+func (h *ContextHistory) StepChanges(step string) []ContextChange {
+	var changes []ContextChange
+	for _, change := range h.Changes() {
+		if change.madeBy(step) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Diff compares the keys step changed, including through nested steps,
+// before its first change and after its last one. Keys the step set back
+// to their previous value are left out. Before values are the latest ones
+// recorded anywhere in the execution, so for changes made in parallel
+// branches or scopes they may come from a different branch or scope.
+func (h *ContextHistory) Diff(step string) []ContextDiff {
+	h.mu.Lock()
+	state := make(map[string]interface{}, len(h.initial))
+	for k, v := range h.initial {
+		state[k] = v
+	}
+	changes := h.changes
+	h.mu.Unlock()
+
+	before := make(map[string]interface{})
+	after := make(map[string]interface{})
+	touched := make(map[string]bool)
+	for _, change := range changes {
+		mine := change.madeBy(step)
+		if mine && !touched[change.Key] {
+			touched[change.Key] = true
+			if value, ok := state[change.Key]; ok {
+				before[change.Key] = value
+			}
+		}
+
+		if change.Op == ChangeDelete {
+			delete(state, change.Key)
+		} else {
+			state[change.Key] = change.Value
+		}
+
+		if mine {
+			if change.Op == ChangeDelete {
+				delete(after, change.Key)
+			} else {
+				after[change.Key] = change.Value
+			}
+		}
+	}
+	return DiffValues(before, after)
+}
+
+// madeBy reports whether step or a step nested in it made the change
+func (c ContextChange) madeBy(step string) bool {
+	for _, name := range c.Path {
+		if name == step {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes the history as its initial values, changes and the
+// number of dropped changes
+func (h *ContextHistory) MarshalJSON() ([]byte, error) {
+	dump := struct {
+		Initial map[string]interface{} `json:"initial"`
+		Changes []ContextChange        `json:"changes"`
+		Dropped int                    `json:"dropped,omitempty"`
+	}{
+		Initial: h.Initial(),
+		Changes: h.Changes(),
+		Dropped: h.Dropped(),
+	}
+	if dump.Changes == nil {
+		dump.Changes = []ContextChange{}
+	}
+	return json.Marshal(dump)
+}
+
+// JSON returns the history as indented JSON, for attaching to bug reports
+// and debug logs
+func (h *ContextHistory) JSON() ([]byte, error) {
+	return json.MarshalIndent(h, "", "  ")
+}
+
+// WithHistory makes the flow record the change history of the contexts it
+// runs on, returned in ExecutionResult.History. Recording copies and
+// sanitizes every value written, so it is meant for debugging.
+func (f *Flow) WithHistory(enabled bool) *Flow {
+	f.history = enabled
+	return f
+}
+
+// DiffValues compares two snapshots of context values, such as the results
+// of ToMap before and after running a step, and returns the differences
+// sorted by key
+func DiffValues(before, after map[string]interface{}) []ContextDiff {
+	var diffs []ContextDiff
+	for key, old := range before {
+		value, ok := after[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, ContextDiff{Key: key, Kind: DiffRemoved, Before: old})
+		case !reflect.DeepEqual(old, value):
+			diffs = append(diffs, ContextDiff{Key: key, Kind: DiffChanged, Before: old, After: value})
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			diffs = append(diffs, ContextDiff{Key: key, Kind: DiffAdded, After: value})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
@@ -0,0 +1,177 @@
+package flow
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+func TestHistory_RecordsChanges(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("user_id", "u1")
+
+	profile := map[string]interface{}{"name": "Ada", "password": "hunter2"}
+	result, err := NewFlow("profile").
+		WithHistory(true).
+		Step("fetch", writer("fetch", 0, map[string]interface{}{"profile": profile})).
+		StepFunc("login", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("auth_token", "secret")
+			ctx.Delete("user_id")
+			return nil
+		}).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.History == nil || result.History != ctx.History() {
+		t.Fatal("History should be attached to the result")
+	}
+
+	// Later changes to a stored value do not rewrite the history
+	profile["name"] = "Grace"
+
+	if initial := result.History.Initial(); initial["user_id"] != "u1" {
+		t.Errorf("Initial = %v, want the values before the flow", initial)
+	}
+	changes := result.History.Changes()
+	if len(changes) != 3 {
+		t.Fatalf("Changes = %+v, want 3", changes)
+	}
+
+	want := map[string]interface{}{"name": "Ada", "password": "***"}
+	if c := changes[0]; c.Op != ChangeSet || c.Key != "profile" || c.Step != "fetch" || !reflect.DeepEqual(c.Value, want) {
+		t.Errorf("changes[0] = %+v, want a sanitized copy set by fetch", c)
+	}
+	if c := changes[1]; c.Key != "auth_token" || c.Value != "***" || c.Step != "login" {
+		t.Errorf("changes[1] = %+v, want a redacted value", c)
+	}
+	if c := changes[2]; c.Op != ChangeDelete || c.Key != "user_id" || c.Seq != 3 {
+		t.Errorf("changes[2] = %+v, want the delete", c)
+	}
+}
+
+func TestHistory_Diff(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("status", "new")
+	ctx.Set("stale", true)
+
+	result, err := NewFlow("f").
+		WithHistory(true).
+		StepFunc("update", func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("status", "pending")
+			ctx.Set("status", "done")
+			ctx.Set("count", 1)
+			ctx.Delete("stale")
+			ctx.Set("unchanged", 1)
+			ctx.Delete("unchanged")
+			return nil
+		}).
+		Step("other", setter("other", "status")).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []ContextDiff{
+		{Key: "count", Kind: DiffAdded, After: 1},
+		{Key: "stale", Kind: DiffRemoved, Before: true},
+		{Key: "status", Kind: DiffChanged, Before: "new", After: "done"},
+	}
+	if diff := result.History.Diff("update"); !reflect.DeepEqual(diff, want) {
+		t.Errorf("Diff(update) = %+v, want %+v", diff, want)
+	}
+	want = []ContextDiff{{Key: "status", Kind: DiffChanged, Before: "done", After: "other"}}
+	if diff := result.History.Diff("other"); !reflect.DeepEqual(diff, want) {
+		t.Errorf("Diff(other) = %+v, want %+v", diff, want)
+	}
+}
+
+func TestHistory_NestedSteps(t *testing.T) {
+	child := NewFlow("child").Step("inner", setter("inner", "child_value"))
+
+	ctx := newTestContext().RecordHistory()
+	ctx.Set("input", 1)
+	result, err := NewFlow("parent").
+		Parallel("fanout").
+		Step("a", setter("a", "a")).
+		Step("b", setter("b", "b")).
+		EndParallel().
+		Scope("scoped").
+		Step("local", setter("local", "tmp")).
+		EndScope().
+		Step("sub", NewSubFlowStep("sub", child).WithInputs(map[string]string{"input": "input"})).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var branchWrite, scopedWrite, childWrite *ContextChange
+	for _, change := range result.History.Changes() {
+		change := change
+		switch {
+		case change.Op == ChangeDelete:
+			t.Errorf("Change %+v: clearing the sub-flow context should not be recorded", change)
+		case change.Step == "a":
+			branchWrite = &change
+		case change.Step == "local":
+			scopedWrite = &change
+		case change.Step == "inner":
+			childWrite = &change
+		}
+	}
+
+	if branchWrite == nil || branchWrite.Branch == "" || !reflect.DeepEqual(branchWrite.Path, []string{"fanout", "a"}) {
+		t.Errorf("branch write = %+v, want the branch and path recorded", branchWrite)
+	}
+	if scopedWrite == nil || !scopedWrite.Scoped {
+		t.Errorf("scoped write = %+v, want it marked as scoped", scopedWrite)
+	}
+	if childWrite == nil || childWrite.Flow != "child" {
+		t.Errorf("sub-flow write = %+v, want the child flow recorded", childWrite)
+	}
+	if len(result.History.StepChanges("fanout")) < 2 {
+		t.Error("StepChanges should include changes of nested steps")
+	}
+}
+
+func TestHistory_JSON(t *testing.T) {
+	ctx := newTestContext().RecordHistory()
+	result, err := NewFlow("f").WithDebug(true).Step("set", setter("set", "key")).Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	data, err := result.History.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var dump struct {
+		Initial map[string]interface{} `json:"initial"`
+		Changes []map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &dump); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(dump.Changes) != 1 || dump.Changes[0]["key"] != "key" || dump.Changes[0]["step"] != "set" {
+		t.Errorf("JSON dump = %s", data)
+	}
+
+	if _, ok := result.GetResponse()["context_history"]; !ok {
+		t.Error("Debug responses should include the history")
+	}
+	if _, err := json.Marshal(result.GetResponse()); err != nil {
+		t.Errorf("Marshal(GetResponse()) error = %v", err)
+	}
+}
+
+func TestHistory_Disabled(t *testing.T) {
+	result, err := NewFlow("f").Step("set", setter("set", "key")).Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.History != nil {
+		t.Error("History should only be recorded when enabled")
+	}
+}
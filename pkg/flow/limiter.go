@@ -0,0 +1,173 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// QueueWaitMetric records how long operations waited for a Limiter slot,
+// tagged with the limiter name
+const QueueWaitMetric = "concurrency_queue_wait_seconds"
+
+// Limiter bounds how many operations run at once. A nil Limiter does not
+// limit, so optional limits need no special casing at call sites.
+type Limiter struct {
+	name  string
+	slots chan struct{}
+}
+
+// NewLimiter creates a limiter allowing n concurrent operations. It returns
+// nil, i.e. no limit, when n is not positive.
+func NewLimiter(name string, n int) *Limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &Limiter{name: name, slots: make(chan struct{}, n)}
+}
+
+// Acquire waits for a free slot until ctx is done. Every successful Acquire
+// must be paired with a Release.
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// Skip the metric on the fast path when the ctx is already done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+		metrics.RecordDuration(QueueWaitMetric, time.Since(start), map[string]string{"limiter": l.name})
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *Limiter) Release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// Hold returns ctx together with the function releasing a slot taken by
+// Acquire. The slot is kept until every step run started under the returned
+// context has exited, including runs abandoned at their deadline, so that a
+// step still running past its deadline keeps its slot.
+func (l *Limiter) Hold(ctx context.Context) (context.Context, func()) {
+	if l == nil {
+		return ctx, func() {}
+	}
+	runs := &stepRuns{parent: stepRunsFrom(ctx)}
+	return context.WithValue(ctx, stepRunsKey{}, runs), func() {
+		runs.whenIdle(l.Release)
+	}
+}
+
+// Limit returns the number of concurrent operations allowed, 0 meaning
+// unlimited
+func (l *Limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
+
+// InFlight returns the number of slots currently held
+func (l *Limiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.slots)
+}
+
+// stepRuns counts the step runs started by runWithDeadline under a Go
+// context that have not exited yet. Runs are also counted by the stepRuns
+// of enclosing contexts. A nil stepRuns counts nothing.
+type stepRuns struct {
+	parent *stepRuns
+
+	mu      sync.Mutex
+	running int
+	idle    []func()
+}
+
+type stepRunsKey struct{}
+
+// stepRunsFrom returns the stepRuns of ctx, or nil
+func stepRunsFrom(ctx context.Context) *stepRuns {
+	runs, _ := ctx.Value(stepRunsKey{}).(*stepRuns)
+	return runs
+}
+
+func (r *stepRuns) start() {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.running++
+		r.mu.Unlock()
+	}
+}
+
+func (r *stepRuns) exit() {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.running--
+		var idle []func()
+		if r.running == 0 {
+			idle, r.idle = r.idle, nil
+		}
+		r.mu.Unlock()
+		for _, fn := range idle {
+			fn()
+		}
+	}
+}
+
+// whenIdle calls fn once no counted run is left, right away if none is
+func (r *stepRuns) whenIdle(fn func()) {
+	r.mu.Lock()
+	if r.running > 0 {
+		r.idle = append(r.idle, fn)
+		r.mu.Unlock()
+		return
+	}
+	r.mu.Unlock()
+	fn()
+}
+
+var (
+	upstreamMu         sync.Mutex
+	upstreamConfigured bool
+	upstreamLimiter    *Limiter
+)
+
+// ConfigureUpstreamLimit sets the process-wide cap on in-flight upstream
+// calls from cfg.HTTP.MaxInFlight. Calls already holding a slot of the
+// previous limiter release it normally.
+func ConfigureUpstreamLimit(cfg *config.FrameworkConfig) {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	upstreamLimiter = NewLimiter("upstream", cfg.HTTP.MaxInFlight)
+	upstreamConfigured = true
+}
+
+// UpstreamLimiter returns the process-wide limiter for upstream calls. Until
+// ConfigureUpstreamLimit is called it follows the default configuration,
+// i.e. the HTTP_MAX_IN_FLIGHT environment variable.
+func UpstreamLimiter() *Limiter {
+	upstreamMu.Lock()
+	defer upstreamMu.Unlock()
+	if !upstreamConfigured {
+		upstreamLimiter = NewLimiter("upstream", config.DefaultConfig().HTTP.MaxInFlight)
+		upstreamConfigured = true
+	}
+	return upstreamLimiter
+}
@@ -0,0 +1,252 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// IterationKey returns the context key a Loop step stores its 1-based
+// iteration counter under
+func IterationKey(stepName string) string {
+	return stepName + "_iteration"
+}
+
+// LoopStep runs a list of steps repeatedly on the flow context, waiting an
+// interval between iterations. The loop ends when the Until condition holds
+// after an iteration, when the While condition no longer holds before one,
+// or when MaxIterations is reached. A loop that stops on its bounds while a
+// condition is still unmet fails with an errors.ErrCodeLoopExhausted error,
+// typed as a timeout only when the deadline was reached.
+type LoopStep struct {
+	*BaseStep
+	steps         []interfaces.Step
+	until         func(interfaces.ExecutionContext) bool
+	while         func(interfaces.ExecutionContext) bool
+	maxIterations int
+	interval      time.Duration
+	multiplier    float64
+	maxInterval   time.Duration
+	deadline      time.Duration
+}
+
+// NewLoopStep creates a loop over steps. Loops are bounded by their
+// iteration count and deadline rather than the default step timeout.
+func NewLoopStep(name string, steps ...interfaces.Step) *LoopStep {
+	base := NewBaseStep(name, fmt.Sprintf("Loop %s", name))
+	base.WithTimeout(0)
+	return &LoopStep{
+		BaseStep:   base,
+		steps:      steps,
+		multiplier: 1,
+	}
+}
+
+// WithUntil ends the loop once condition holds after an iteration
+func (s *LoopStep) WithUntil(condition func(interfaces.ExecutionContext) bool) *LoopStep {
+	s.until = condition
+	return s
+}
+
+// WithWhile runs an iteration only while condition holds
+func (s *LoopStep) WithWhile(condition func(interfaces.ExecutionContext) bool) *LoopStep {
+	s.while = condition
+	return s
+}
+
+// WithMaxIterations caps the number of iterations; 0 means no cap
+func (s *LoopStep) WithMaxIterations(n int) *LoopStep {
+	s.maxIterations = n
+	return s
+}
+
+// WithInterval sets the wait between iterations
+func (s *LoopStep) WithInterval(interval time.Duration) *LoopStep {
+	s.interval = interval
+	return s
+}
+
+// WithBackoff multiplies the interval by multiplier after every iteration,
+// up to maxInterval (0 for no cap)
+func (s *LoopStep) WithBackoff(multiplier float64, maxInterval time.Duration) *LoopStep {
+	s.multiplier = multiplier
+	s.maxInterval = maxInterval
+	return s
+}
+
+// WithDeadline bounds the total time spent in the loop, waits included
+func (s *LoopStep) WithDeadline(deadline time.Duration) *LoopStep {
+	s.deadline = deadline
+	return s
+}
+
+func (s *LoopStep) Run(ctx interfaces.ExecutionContext) error {
+	if s.until == nil && s.while == nil && s.maxIterations <= 0 && s.deadline <= 0 {
+		return errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+			fmt.Sprintf("Loop step '%s' has no condition, iteration limit or deadline", s.Name())).
+			WithContext("step", s.Name())
+	}
+
+	parent := ctx.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	goCtx := parent
+	if s.deadline > 0 {
+		var cancel context.CancelFunc
+		goCtx, cancel = context.WithTimeout(parent, s.deadline)
+		defer cancel()
+		ctx = withGoContext(ctx, goCtx)
+	}
+
+	interval := s.interval
+	for iteration := 1; ; iteration++ {
+		if s.while != nil && !s.while(ctx) {
+			return nil
+		}
+
+		ctx.Set(IterationKey(s.Name()), iteration)
+		for _, step := range s.steps {
+			if err := runStep(ctx, step); err != nil {
+				if goCtx.Err() != nil {
+					return s.stopped(parent, goCtx, iteration)
+				}
+				ctx.Logger().Error("Loop iteration failed",
+					zap.String("parent_step", s.Name()),
+					zap.String("step", step.Name()),
+					zap.Int("iteration", iteration),
+					zap.Error(err))
+				return err
+			}
+		}
+
+		if s.until != nil && s.until(ctx) {
+			return nil
+		}
+		if s.maxIterations > 0 && iteration >= s.maxIterations {
+			if s.until == nil && s.while == nil {
+				return nil
+			}
+			// Running out of iterations is not a timeout, so timeout
+			// handlers only see real deadlines
+			return errors.NewInternalError(errors.ErrCodeLoopExhausted,
+				fmt.Sprintf("Loop step '%s' condition not met after %d iterations", s.Name(), iteration)).
+				WithContext("step", s.Name()).
+				WithContext("iterations", iteration)
+		}
+
+		if interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-timer.C:
+			case <-goCtx.Done():
+				timer.Stop()
+				return s.stopped(parent, goCtx, iteration)
+			}
+		} else if goCtx.Err() != nil {
+			return s.stopped(parent, goCtx, iteration)
+		}
+		interval = s.nextInterval(interval)
+	}
+}
+
+// nextInterval grows interval by the backoff multiplier
+func (s *LoopStep) nextInterval(interval time.Duration) time.Duration {
+	if s.multiplier > 1 {
+		interval = time.Duration(float64(interval) * s.multiplier)
+	}
+	if s.maxInterval > 0 && interval > s.maxInterval {
+		interval = s.maxInterval
+	}
+	return interval
+}
+
+// stopped returns the error for a loop interrupted by cancellation of the
+// parent context or by its own deadline
+func (s *LoopStep) stopped(parent, goCtx context.Context, iteration int) error {
+	if err := parent.Err(); err != nil {
+		return err
+	}
+	return errors.NewTimeoutError(errors.ErrCodeLoopExhausted,
+		fmt.Sprintf("Loop step '%s' exceeded its %v deadline after %d iterations", s.Name(), s.deadline, iteration)).
+		WithContext("step", s.Name()).
+		WithContext("iterations", iteration).
+		WithCause(goCtx.Err())
+}
+
+// Loop starts a block of steps run repeatedly on the flow context
+func (f *Flow) Loop(name string) *LoopBuilder {
+	return &LoopBuilder{
+		flow: f,
+		step: NewLoopStep(name),
+	}
+}
+
+// LoopBuilder builds Loop blocks
+type LoopBuilder struct {
+	flow *Flow
+	step *LoopStep
+}
+
+// Until ends the loop once condition holds after an iteration
+func (lb *LoopBuilder) Until(condition func(interfaces.ExecutionContext) bool) *LoopBuilder {
+	lb.step.WithUntil(condition)
+	return lb
+}
+
+// While runs an iteration only while condition holds
+func (lb *LoopBuilder) While(condition func(interfaces.ExecutionContext) bool) *LoopBuilder {
+	lb.step.WithWhile(condition)
+	return lb
+}
+
+// MaxIterations caps the number of iterations
+func (lb *LoopBuilder) MaxIterations(n int) *LoopBuilder {
+	lb.step.WithMaxIterations(n)
+	return lb
+}
+
+// Interval sets the wait between iterations
+func (lb *LoopBuilder) Interval(interval time.Duration) *LoopBuilder {
+	lb.step.WithInterval(interval)
+	return lb
+}
+
+// Backoff grows the interval by multiplier after every iteration, up to
+// maxInterval
+func (lb *LoopBuilder) Backoff(multiplier float64, maxInterval time.Duration) *LoopBuilder {
+	lb.step.WithBackoff(multiplier, maxInterval)
+	return lb
+}
+
+// Deadline bounds the total time spent in the loop
+func (lb *LoopBuilder) Deadline(deadline time.Duration) *LoopBuilder {
+	lb.step.WithDeadline(deadline)
+	return lb
+}
+
+// Step adds a step run on every iteration
+func (lb *LoopBuilder) Step(name string, step interfaces.Step) *LoopBuilder {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	lb.step.steps = append(lb.step.steps, step)
+	return lb
+}
+
+// StepFunc adds a function step run on every iteration
+func (lb *LoopBuilder) StepFunc(name string, fn func(interfaces.ExecutionContext) error) *LoopBuilder {
+	lb.step.steps = append(lb.step.steps, &namedStep{Step: StepFunc(fn), name: name})
+	return lb
+}
+
+// EndLoop completes the block
+func (lb *LoopBuilder) EndLoop() *Flow {
+	lb.flow.steps = append(lb.flow.steps, lb.step)
+	return lb.flow
+}
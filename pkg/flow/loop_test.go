@@ -0,0 +1,165 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// counter returns a step incrementing the "count" key
+func counter() interfaces.Step {
+	return &mockStep{name: "count", runFunc: func(ctx interfaces.ExecutionContext) error {
+		n, _ := ctx.Get("count")
+		count, _ := n.(int)
+		ctx.Set("count", count+1)
+		return nil
+	}}
+}
+
+// countReached returns a condition holding once "count" reaches n
+func countReached(n int) func(interfaces.ExecutionContext) bool {
+	return func(ctx interfaces.ExecutionContext) bool {
+		count, _ := ctx.Get("count")
+		return count == n
+	}
+}
+
+func TestLoop_Until(t *testing.T) {
+	result, err := NewFlow("poll").
+		Loop("wait").
+		Until(countReached(3)).
+		MaxIterations(10).
+		Interval(time.Millisecond).
+		Step("count", counter()).
+		EndLoop().
+		Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if count, _ := result.Context.Get("count"); count != 3 {
+		t.Errorf("count = %v, want 3", count)
+	}
+	if iteration, _ := result.Context.Get(IterationKey("wait")); iteration != 3 {
+		t.Errorf("Iteration counter = %v, want 3", iteration)
+	}
+}
+
+func TestLoop_While(t *testing.T) {
+	ctx := newTestContext()
+	step := NewLoopStep("drain", counter()).WithWhile(func(ctx interfaces.ExecutionContext) bool {
+		return !countReached(2)(ctx)
+	})
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if count, _ := ctx.Get("count"); count != 2 {
+		t.Errorf("count = %v, want 2", count)
+	}
+}
+
+func TestLoop_MaxIterationsWithoutCondition(t *testing.T) {
+	ctx := newTestContext()
+	if err := NewLoopStep("repeat", counter()).WithMaxIterations(4).Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if count, _ := ctx.Get("count"); count != 4 {
+		t.Errorf("count = %v, want 4", count)
+	}
+}
+
+func TestLoop_Exhausted(t *testing.T) {
+	ctx := newTestContext()
+	err := NewLoopStep("wait", counter()).
+		WithUntil(countReached(10)).
+		WithMaxIterations(3).
+		Run(ctx)
+
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeLoopExhausted {
+		t.Fatalf("Run() error = %v, want a loop exhausted error", err)
+	}
+	if fwErr.Type == frameworkErrors.ErrorTypeTimeout {
+		t.Error("Running out of iterations is not a timeout")
+	}
+	if fwErr.Context["iterations"] != 3 {
+		t.Errorf("iterations = %v, want 3", fwErr.Context["iterations"])
+	}
+}
+
+func TestLoop_Deadline(t *testing.T) {
+	start := time.Now()
+	err := NewLoopStep("wait", counter()).
+		WithUntil(countReached(-1)).
+		WithInterval(time.Second).
+		WithDeadline(20 * time.Millisecond).
+		Run(newTestContext())
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("The deadline should interrupt the interval wait")
+	}
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeLoopExhausted {
+		t.Fatalf("Run() error = %v, want a loop exhausted error", err)
+	}
+	if fwErr.Type != frameworkErrors.ErrorTypeTimeout {
+		t.Errorf("Type = %v, want timeout", fwErr.Type)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("The error should wrap context.DeadlineExceeded")
+	}
+}
+
+func TestLoop_ParentCancellation(t *testing.T) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	ctx := newTestContext().WithContext(goCtx)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err := NewLoopStep("wait", counter()).
+		WithUntil(countReached(-1)).
+		WithInterval(time.Second).
+		Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestLoop_StepFailure(t *testing.T) {
+	boom := errors.New("boom")
+	err := NewLoopStep("wait", counter(), failing("broken", 0, boom)).
+		WithMaxIterations(3).
+		Run(newTestContext())
+	if err != boom {
+		t.Errorf("Run() error = %v, want boom", err)
+	}
+}
+
+func TestLoop_Unbounded(t *testing.T) {
+	var fwErr *frameworkErrors.FrameworkError
+	err := NewLoopStep("forever", counter()).Run(newTestContext())
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeInvalidConfiguration {
+		t.Errorf("Run() error = %v, want a configuration error", err)
+	}
+}
+
+func TestLoop_NextInterval(t *testing.T) {
+	step := NewLoopStep("wait").WithBackoff(2, 300*time.Millisecond)
+
+	interval := 100 * time.Millisecond
+	var got []time.Duration
+	for i := 0; i < 3; i++ {
+		interval = step.nextInterval(interval)
+		got = append(got, interval)
+	}
+	want := []time.Duration{200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Intervals = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// MergePolicy decides how the writes of parallel branches are copied back
+// into the parent context. Branches are always merged in declaration order
+// once every branch has finished, so the result does not depend on which
+// goroutine finishes last.
+type MergePolicy string
+
+const (
+	// MergeWritten copies the keys each successful branch wrote; when two
+	// branches write the same key, the later-declared branch wins. This is
+	// the default.
+	MergeWritten MergePolicy = "written"
+
+	// MergeNamespaced stores each successful branch's writes as a map under
+	// the branch step's name instead of at the top level
+	MergeNamespaced MergePolicy = "namespaced"
+
+	// MergeFailOnConflict copies the writes like MergeWritten but fails the
+	// parallel step, merging nothing, when two successful branches wrote
+	// the same key
+	MergeFailOnConflict MergePolicy = "fail_on_conflict"
+)
+
+// BranchResult is the outcome of one parallel branch, passed to a MergeFunc
+type BranchResult struct {
+	Name  string // branch step name
+	Index int    // position in the parallel block
+
+	// Values holds the keys the branch wrote with their final values, and
+	// Keys lists them in the order they were first written
+	Values map[string]interface{}
+	Keys   []string
+
+	// Err is the branch's error. Cancelled is set when the branch failed
+	// because the failure policy cancelled it after a sibling's outcome.
+	Err       error
+	Cancelled bool
+}
+
+// MergeFunc merges the results of a parallel step into the parent context.
+// It receives every branch in declaration order, including failed ones.
+// Returning an error fails the parallel step.
+type MergeFunc func(parent interfaces.ExecutionContext, branches []BranchResult) error
+
+// ParseMergePolicy validates a merge policy name, e.g. from a declarative
+// flow definition
+func ParseMergePolicy(name string) (MergePolicy, error) {
+	switch policy := MergePolicy(name); policy {
+	case MergeWritten, MergeNamespaced, MergeFailOnConflict:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown merge policy '%s'", name)
+	}
+}
+
+// branchWritesKey is the Go context key of a branch's branchWrites
+type branchWritesKey struct{}
+
+// branchWrites records the keys written by one parallel branch
+type branchWrites struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+	keys []string
+}
+
+func (w *branchWrites) add(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.seen[key]; ok {
+		return
+	}
+	if w.seen == nil {
+		w.seen = make(map[string]struct{})
+	}
+	w.seen[key] = struct{}{}
+	w.keys = append(w.keys, key)
+}
+
+// result collects the written keys that are still present in ctx
+func (w *branchWrites) result(ctx interfaces.ExecutionContext) ([]string, map[string]interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keys := make([]string, 0, len(w.keys))
+	values := make(map[string]interface{}, len(w.keys))
+	for _, key := range w.keys {
+		if val, ok := ctx.Get(key); ok {
+			keys = append(keys, key)
+			values[key] = val
+		}
+	}
+	return keys, values
+}
+
+// recordBranchWrite attributes a context write to the branch running on
+// goCtx, if any
+func recordBranchWrite(goCtx context.Context, key string) {
+	if writes, _ := goCtx.Value(branchWritesKey{}).(*branchWrites); writes != nil {
+		writes.add(key)
+	}
+}
+
+// withBranchWrites returns a view of ctx whose writes are recorded in writes
+func withBranchWrites(ctx interfaces.ExecutionContext, writes *branchWrites) interfaces.ExecutionContext {
+	goCtx := ctx.Context()
+	if goCtx == nil {
+		goCtx = context.Background()
+	}
+	return withGoContext(ctx, context.WithValue(goCtx, branchWritesKey{}, writes))
+}
+
+// mergeBranches applies the parallel step's merge policy to the branch
+// results. Failed branches are not merged by the built-in policies.
+func (s *ParallelStep) mergeBranches(ctx interfaces.ExecutionContext, results []BranchResult) error {
+	// The branch steps already own these writes in the trace
+	target := untraced(ctx)
+
+	if s.mergeFunc != nil {
+		return s.mergeFunc(target, results)
+	}
+
+	succeeded := make([]BranchResult, 0, len(results))
+	for _, result := range results {
+		if result.Err == nil {
+			succeeded = append(succeeded, result)
+		}
+	}
+
+	switch s.mergePolicy {
+	case MergeNamespaced:
+		for _, result := range succeeded {
+			target.Set(result.Name, result.Values)
+		}
+		return nil
+	case MergeFailOnConflict:
+		if err := s.checkConflicts(succeeded); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range succeeded {
+		for _, key := range result.Keys {
+			target.Set(key, result.Values[key])
+		}
+	}
+	return nil
+}
+
+// checkConflicts fails when two branches wrote the same key
+func (s *ParallelStep) checkConflicts(results []BranchResult) error {
+	writers := make(map[string][]string)
+	for _, result := range results {
+		for _, key := range result.Keys {
+			writers[key] = append(writers[key], result.Name)
+		}
+	}
+
+	var conflicts []string
+	for key, names := range writers {
+		if len(names) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("'%s' (%s)", key, strings.Join(names, ", ")))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+
+	return errors.NewInternalError(errors.ErrCodeMergeConflict,
+		fmt.Sprintf("Parallel step '%s' branches wrote the same keys: %s", s.Name(), strings.Join(conflicts, "; "))).
+		WithContext("step", s.Name())
+}
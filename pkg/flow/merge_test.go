@@ -0,0 +1,172 @@
+package flow
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// writer returns a step that sleeps for delay and then sets values
+func writer(name string, delay time.Duration, values map[string]interface{}) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+		time.Sleep(delay)
+		for k, v := range values {
+			ctx.Set(k, v)
+		}
+		return nil
+	}}
+}
+
+func TestParallelStep_MergeWritten_DeclarationOrder(t *testing.T) {
+	// The first branch finishes last but the second still wins the shared
+	// key because it is declared later
+	for i := 0; i < 5; i++ {
+		ctx := newTestContext()
+		step := NewParallelStep("fanout",
+			writer("slow", 20*time.Millisecond, map[string]interface{}{"http_response": "slow", "a": 1}),
+			writer("fast", 0, map[string]interface{}{"http_response": "fast", "b": 2}),
+		)
+
+		if err := step.Run(ctx); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if v, _ := ctx.Get("http_response"); v != "fast" {
+			t.Fatalf("http_response = %v, want the later-declared branch's value", v)
+		}
+		if v, _ := ctx.Get("a"); v != 1 {
+			t.Errorf("a = %v, want 1", v)
+		}
+		if v, _ := ctx.Get("b"); v != 2 {
+			t.Errorf("b = %v, want 2", v)
+		}
+	}
+}
+
+func TestParallelStep_MergeWritten_KeepsParentChanges(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("counter", 1)
+
+	step := NewParallelStep("fanout",
+		&mockStep{name: "bump", runFunc: func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("counter", 2)
+			return nil
+		}},
+		writer("other", 10*time.Millisecond, map[string]interface{}{"other": true}),
+	)
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The branch that did not write counter must not copy its stale value
+	if v, _ := ctx.Get("counter"); v != 2 {
+		t.Errorf("counter = %v, want 2", v)
+	}
+}
+
+func TestParallelStep_MergeNamespaced(t *testing.T) {
+	result, err := NewFlow("merge").
+		Parallel("fanout").
+		Merge(MergeNamespaced).
+		Step("profile", writer("profile", 0, map[string]interface{}{"http_response": "profile"})).
+		Step("orders", writer("orders", 0, map[string]interface{}{"http_response": "orders"})).
+		EndParallel().
+		Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	ctx := result.Context
+	if ctx.Has("http_response") {
+		t.Error("Namespaced writes should not reach the top level")
+	}
+	profile, _ := ctx.Get("profile")
+	if !reflect.DeepEqual(profile, map[string]interface{}{"http_response": "profile"}) {
+		t.Errorf("profile = %v", profile)
+	}
+	orders, _ := ctx.Get("orders")
+	if !reflect.DeepEqual(orders, map[string]interface{}{"http_response": "orders"}) {
+		t.Errorf("orders = %v", orders)
+	}
+}
+
+func TestParallelStep_MergeFailOnConflict(t *testing.T) {
+	ctx := newTestContext()
+	step := NewParallelStep("fanout",
+		writer("profile", 0, map[string]interface{}{"http_metadata": 1, "profile": true}),
+		writer("orders", 0, map[string]interface{}{"http_metadata": 2}),
+	).WithMergePolicy(MergeFailOnConflict)
+
+	err := step.Run(ctx)
+
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeMergeConflict {
+		t.Fatalf("Run() error = %v, want a merge conflict", err)
+	}
+	if !strings.Contains(err.Error(), "'http_metadata' (profile, orders)") {
+		t.Errorf("Error %q should name the key and branches", err)
+	}
+	if ctx.Has("profile") {
+		t.Error("Nothing should be merged on conflict")
+	}
+
+	// Distinct keys merge normally
+	ctx = newTestContext()
+	step = NewParallelStep("fanout",
+		writer("profile", 0, map[string]interface{}{"profile": true}),
+		writer("orders", 0, map[string]interface{}{"orders": true}),
+	).WithMergePolicy(MergeFailOnConflict)
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !ctx.Has("profile") || !ctx.Has("orders") {
+		t.Error("Non-conflicting writes should be merged")
+	}
+}
+
+func TestParallelStep_MergeFunc(t *testing.T) {
+	var seen []string
+	ctx := newTestContext()
+	step := NewParallelStep("fanout",
+		writer("profile", 10*time.Millisecond, map[string]interface{}{"score": 3}),
+		&mockStep{name: "broken", runFunc: func(ctx interfaces.ExecutionContext) error {
+			ctx.Set("score", 100)
+			return errors.New("boom")
+		}},
+		writer("orders", 0, map[string]interface{}{"score": 4}),
+	).WithMergeFunc(func(parent interfaces.ExecutionContext, branches []BranchResult) error {
+		total := 0
+		for _, branch := range branches {
+			seen = append(seen, branch.Name)
+			if branch.Err == nil {
+				total += branch.Values["score"].(int)
+			}
+		}
+		parent.Set("score", total)
+		return nil
+	})
+
+	err := step.Run(ctx)
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("Run() error = %v, want boom", err)
+	}
+	if strings.Join(seen, ",") != "profile,broken,orders" {
+		t.Errorf("Merge order = %v, want declaration order", seen)
+	}
+	if v, _ := ctx.Get("score"); v != 7 {
+		t.Errorf("score = %v, want 7", v)
+	}
+}
+
+func TestParseMergePolicy(t *testing.T) {
+	if policy, err := ParseMergePolicy("namespaced"); err != nil || policy != MergeNamespaced {
+		t.Errorf("ParseMergePolicy(namespaced) = %v, %v", policy, err)
+	}
+	if _, err := ParseMergePolicy("last_wins"); err == nil {
+		t.Error("Unknown policies should be rejected")
+	}
+}
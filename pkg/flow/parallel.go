@@ -0,0 +1,172 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// FailurePolicy decides when a parallel step stops waiting for its branches
+// and whether branch failures fail the step
+type FailurePolicy string
+
+const (
+	// FailWaitAll waits for every branch. A single failure is returned as
+	// is; several are aggregated in a *ParallelError. This is the default.
+	FailWaitAll FailurePolicy = "wait_all"
+
+	// FailFast cancels the sibling branches as soon as one fails and
+	// returns that failure
+	FailFast FailurePolicy = "fail_fast"
+
+	// FailQuorum succeeds once the quorum of branches has succeeded and
+	// cancels the rest; it fails with a *ParallelError as soon as the
+	// quorum can no longer be reached
+	FailQuorum FailurePolicy = "quorum"
+
+	// FailBestEffort waits for every branch and never fails the step.
+	// Failures are recorded in the context under "<step>_failures" as a
+	// map from branch name to error message.
+	FailBestEffort FailurePolicy = "best_effort"
+)
+
+// ParseFailurePolicy validates a failure policy name, e.g. from a
+// declarative flow definition
+func ParseFailurePolicy(name string) (FailurePolicy, error) {
+	switch policy := FailurePolicy(name); policy {
+	case FailWaitAll, FailFast, FailQuorum, FailBestEffort:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown failure policy '%s'", name)
+	}
+}
+
+// ParallelError aggregates the branch failures of a parallel step
+type ParallelError struct {
+	Step     string
+	Total    int            // number of branches
+	Required int            // successes needed under FailQuorum, 0 otherwise
+	Failures []BranchResult // failed branches in declaration order
+}
+
+func (e *ParallelError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, fmt.Sprintf("%s: %v", failure.Name, failure.Err))
+	}
+
+	summary := fmt.Sprintf("%d of %d branches failed", len(e.Failures), e.Total)
+	if e.Required > 0 {
+		summary = fmt.Sprintf("quorum of %d not reached, %s", e.Required, summary)
+	}
+	return fmt.Sprintf("parallel step '%s': %s: %s", e.Step, summary, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the branch errors so errors.Is and errors.As see them
+func (e *ParallelError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// FailuresKey returns the context key under which a FailBestEffort
+// parallel step records its branch failures
+func FailuresKey(stepName string) string {
+	return stepName + "_failures"
+}
+
+// required returns the number of successful branches the step needs
+func (s *ParallelStep) required() int {
+	if s.failurePolicy == FailQuorum {
+		return s.quorum
+	}
+	return len(s.steps)
+}
+
+// validateQuorum rejects a FailQuorum step whose quorum is not between 1
+// and the number of branches
+func (s *ParallelStep) validateQuorum() error {
+	if s.failurePolicy != FailQuorum || (s.quorum >= 1 && s.quorum <= len(s.steps)) {
+		return nil
+	}
+	return frameworkErrors.NewConfigurationError(frameworkErrors.ErrCodeInvalidConfiguration,
+		fmt.Sprintf("Parallel step '%s' has a quorum of %d, want 1 to %d", s.Name(), s.quorum, len(s.steps))).
+		WithContext("step", s.Name()).
+		WithContext("quorum", s.quorum).
+		WithContext("branches", len(s.steps))
+}
+
+// settled reports whether the remaining branches can be cancelled given
+// the outcomes so far
+func (s *ParallelStep) settled(succeeded, failed int) bool {
+	switch s.failurePolicy {
+	case FailFast:
+		return failed > 0
+	case FailQuorum:
+		required := s.required()
+		return succeeded >= required || failed > len(s.steps)-required
+	default:
+		return false
+	}
+}
+
+// outcome applies the failure policy to the branch results. first is the
+// index of the first branch to fail, or -1.
+func (s *ParallelStep) outcome(ctx interfaces.ExecutionContext, results []BranchResult, first int) error {
+	var failures []BranchResult
+	for _, result := range results {
+		if result.Err != nil && !result.Cancelled {
+			failures = append(failures, result)
+		}
+	}
+
+	switch s.failurePolicy {
+	case FailBestEffort:
+		if len(failures) > 0 {
+			messages := make(map[string]interface{}, len(failures))
+			for _, failure := range failures {
+				messages[failure.Name] = failure.Err.Error()
+			}
+			ctx.Set(FailuresKey(s.Name()), messages)
+		}
+		return nil
+	case FailFast:
+		if first >= 0 {
+			return results[first].Err
+		}
+		return nil
+	case FailQuorum:
+		if len(results)-len(failures) >= s.required() {
+			return nil
+		}
+		// Cancelled branches count against the quorum as well
+		failures = failures[:0]
+		for _, result := range results {
+			if result.Err != nil {
+				failures = append(failures, result)
+			}
+		}
+		return &ParallelError{Step: s.Name(), Total: len(results), Required: s.required(), Failures: failures}
+	}
+
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0].Err
+	default:
+		return &ParallelError{Step: s.Name(), Total: len(results), Failures: failures}
+	}
+}
+
+// isCancellation reports whether a branch failed because goCtx was
+// cancelled under it
+func isCancellation(goCtx context.Context, err error) bool {
+	return goCtx.Err() == context.Canceled && errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,329 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// failing returns a step that fails with err after delay
+func failing(name string, delay time.Duration, err error) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+		time.Sleep(delay)
+		return err
+	}}
+}
+
+// blocking returns a step that waits for its context to be cancelled and
+// reports the cancellation on observed
+func blocking(name string, observed chan<- error) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+		select {
+		case <-ctx.Context().Done():
+			observed <- ctx.Context().Err()
+			return ctx.Context().Err()
+		case <-time.After(5 * time.Second):
+			observed <- nil
+			return nil
+		}
+	}}
+}
+
+func TestParallelStep_FailFast(t *testing.T) {
+	boom := errors.New("boom")
+	observed := make(chan error, 1)
+
+	step := NewParallelStep("fanout",
+		blocking("slow", observed),
+		failing("broken", 10*time.Millisecond, boom),
+	).WithFailurePolicy(FailFast)
+
+	start := time.Now()
+	err := step.Run(newTestContext())
+
+	if time.Since(start) > time.Second {
+		t.Fatal("Siblings should be cancelled on the first failure")
+	}
+	if err != boom {
+		t.Errorf("Run() error = %v, want boom", err)
+	}
+	if sibling := <-observed; !errors.Is(sibling, context.Canceled) {
+		t.Errorf("Sibling observed %v, want context.Canceled", sibling)
+	}
+}
+
+func TestParallelStep_WaitAllAggregatesFailures(t *testing.T) {
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	ctx := newTestContext()
+
+	step := NewParallelStep("fanout",
+		failing("a", 10*time.Millisecond, errA),
+		writer("ok", 20*time.Millisecond, map[string]interface{}{"ok": true}),
+		failing("b", 0, errB),
+	)
+	err := step.Run(ctx)
+
+	var parallelErr *ParallelError
+	if !errors.As(err, &parallelErr) {
+		t.Fatalf("Run() error = %v, want a *ParallelError", err)
+	}
+	if len(parallelErr.Failures) != 2 || parallelErr.Failures[0].Name != "a" || parallelErr.Failures[1].Name != "b" {
+		t.Errorf("Failures = %+v, want a and b in declaration order", parallelErr.Failures)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("ParallelError should unwrap to every branch error")
+	}
+	if !strings.Contains(err.Error(), "2 of 3 branches failed") {
+		t.Errorf("Error %q should summarise the failures", err)
+	}
+	if !ctx.Has("ok") {
+		t.Error("The successful branch should still be merged")
+	}
+}
+
+func TestParallelStep_Quorum(t *testing.T) {
+	observed := make(chan error, 1)
+	ctx := newTestContext()
+
+	step := NewParallelStep("replicas",
+		writer("a", 0, map[string]interface{}{"a": true}),
+		failing("b", 0, errors.New("b failed")),
+		writer("c", 10*time.Millisecond, map[string]interface{}{"c": true}),
+		blocking("d", observed),
+	).WithQuorum(2)
+
+	start := time.Now()
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Remaining branches should be cancelled once the quorum is reached")
+	}
+	if sibling := <-observed; !errors.Is(sibling, context.Canceled) {
+		t.Errorf("Sibling observed %v, want context.Canceled", sibling)
+	}
+	if !ctx.Has("a") || !ctx.Has("c") {
+		t.Error("The successful branches should be merged")
+	}
+}
+
+func TestParallelStep_QuorumUnreachable(t *testing.T) {
+	observed := make(chan error, 1)
+
+	step := NewParallelStep("replicas",
+		failing("a", 0, errors.New("a failed")),
+		failing("b", 10*time.Millisecond, errors.New("b failed")),
+		blocking("c", observed),
+	).WithQuorum(2)
+
+	err := step.Run(newTestContext())
+
+	var parallelErr *ParallelError
+	if !errors.As(err, &parallelErr) {
+		t.Fatalf("Run() error = %v, want a *ParallelError", err)
+	}
+	if parallelErr.Required != 2 || len(parallelErr.Failures) != 3 {
+		t.Errorf("ParallelError = %+v, want 3 failures against a quorum of 2", parallelErr)
+	}
+	if !parallelErr.Failures[2].Cancelled {
+		t.Error("The cancelled branch should be marked as cancelled")
+	}
+	if sibling := <-observed; !errors.Is(sibling, context.Canceled) {
+		t.Errorf("Sibling observed %v, want context.Canceled", sibling)
+	}
+}
+
+func TestParallelStep_InvalidQuorum(t *testing.T) {
+	for _, quorum := range []int{0, -1, 3} {
+		var ran int32
+		step := NewParallelStep("replicas",
+			tracker("a", new(int32), &ran),
+			tracker("b", new(int32), &ran),
+		).WithQuorum(quorum)
+
+		err := step.Run(newTestContext())
+		var fwErr *frameworkErrors.FrameworkError
+		if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeInvalidConfiguration {
+			t.Errorf("Quorum %d: Run() error = %v, want INVALID_CONFIGURATION", quorum, err)
+		}
+		if atomic.LoadInt32(&ran) != 0 {
+			t.Errorf("Quorum %d: no branch should run", quorum)
+		}
+	}
+}
+
+func TestParallelStep_BestEffort(t *testing.T) {
+	result, err := NewFlow("best_effort").
+		Parallel("fanout").
+		BestEffort().
+		Step("profile", writer("profile", 0, map[string]interface{}{"profile": true})).
+		Step("orders", failing("orders", 0, errors.New("orders unavailable"))).
+		EndParallel().
+		Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	ctx := result.Context
+	if !ctx.Has("profile") {
+		t.Error("The successful branch should be merged")
+	}
+	failures, _ := ctx.Get(FailuresKey("fanout"))
+	if got := failures.(map[string]interface{})["orders"]; got != "orders unavailable" {
+		t.Errorf("Recorded failure = %v, want the branch error", got)
+	}
+}
+
+func TestParseFailurePolicy(t *testing.T) {
+	if policy, err := ParseFailurePolicy("fail_fast"); err != nil || policy != FailFast {
+		t.Errorf("ParseFailurePolicy(fail_fast) = %v, %v", policy, err)
+	}
+	if _, err := ParseFailurePolicy("retry"); err == nil {
+		t.Error("Unknown policies should be rejected")
+	}
+}
+
+// tracker returns a step that records the peak number of concurrently
+// running instances sharing running and peak
+func tracker(name string, running, peak *int32) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+		n := atomic.AddInt32(running, 1)
+		defer atomic.AddInt32(running, -1)
+		for {
+			p := atomic.LoadInt32(peak)
+			if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		ctx.Set(name, true)
+		return nil
+	}}
+}
+
+func TestParallelStep_MaxConcurrency(t *testing.T) {
+	var running, peak int32
+	builder := NewFlow("bounded").Parallel("items").MaxConcurrency(3)
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("item_%d", i)
+		builder.Step(name, tracker(name, &running, &peak))
+	}
+
+	result, err := builder.EndParallel().Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("Peak concurrency = %d, want at most 3", peak)
+	}
+	for i := 0; i < 20; i++ {
+		if !result.Context.Has(fmt.Sprintf("item_%d", i)) {
+			t.Fatalf("item_%d was not merged", i)
+		}
+	}
+}
+
+func TestParallelStep_MaxConcurrency_FailFastCancelsQueued(t *testing.T) {
+	var started int32
+	steps := []interfaces.Step{failing("broken", 0, errors.New("boom"))}
+	for i := 0; i < 10; i++ {
+		steps = append(steps, &mockStep{name: fmt.Sprintf("item_%d", i), runFunc: func(ctx interfaces.ExecutionContext) error {
+			atomic.AddInt32(&started, 1)
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}})
+	}
+
+	step := NewParallelStep("items", steps...).WithMaxConcurrency(1).WithFailurePolicy(FailFast)
+	if err := step.Run(newTestContext()); err == nil || err.Error() != "boom" {
+		t.Fatalf("Run() error = %v, want boom", err)
+	}
+	if n := atomic.LoadInt32(&started); n > 1 {
+		t.Errorf("%d queued branches started after the failure", n)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	var unlimited *Limiter
+	if err := unlimited.Acquire(context.Background()); err != nil || unlimited.Limit() != 0 {
+		t.Error("A nil limiter should not limit")
+	}
+	unlimited.Release()
+
+	limiter := NewLimiter("test", 1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if limiter.InFlight() != 1 {
+		t.Errorf("InFlight() = %d, want 1", limiter.InFlight())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() on a full limiter = %v, want the ctx error", err)
+	}
+
+	limiter.Release()
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire() after Release() error = %v", err)
+	}
+}
+
+func TestLimiter_HoldKeepsSlotForAbandonedRun(t *testing.T) {
+	limiter := NewLimiter("test", 1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	release := make(chan struct{})
+	exited := make(chan struct{})
+	stubborn := &mockStep{name: "stubborn", runFunc: func(ctx interfaces.ExecutionContext) error {
+		defer close(exited)
+		<-release // ignores cancellation
+		return nil
+	}}
+
+	goCtx, releaseSlot := limiter.Hold(context.Background())
+	err := RunStepWithTimeout(NewContext().WithContext(goCtx), stubborn, 10*time.Millisecond)
+	releaseSlot()
+	if err == nil {
+		t.Fatal("The step should have timed out")
+	}
+	if limiter.InFlight() != 1 {
+		t.Error("The slot should be kept while the abandoned step runs")
+	}
+
+	close(release)
+	<-exited
+	for start := time.Now(); limiter.InFlight() != 0; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("The slot should be released once the abandoned step exits")
+		}
+	}
+}
+
+func TestConfigureUpstreamLimit(t *testing.T) {
+	defer ConfigureUpstreamLimit(config.DefaultConfig())
+
+	cfg := config.DefaultConfig()
+	cfg.HTTP.MaxInFlight = 4
+	ConfigureUpstreamLimit(cfg)
+	if limit := UpstreamLimiter().Limit(); limit != 4 {
+		t.Errorf("Upstream limit = %d, want 4", limit)
+	}
+
+	cfg.HTTP.MaxInFlight = 0
+	ConfigureUpstreamLimit(cfg)
+	if UpstreamLimiter() != nil {
+		t.Error("A zero MaxInFlight should remove the limit")
+	}
+}
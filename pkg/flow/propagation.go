@@ -0,0 +1,87 @@
+package flow
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+)
+
+// RequestIDHeader is the header carrying the request ID between the client,
+// the BFF and upstream services
+const RequestIDHeader = "X-Request-ID"
+
+// propagator reads and writes the W3C traceparent, tracestate and baggage
+// headers
+var propagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// Propagator returns the propagator used for incoming and outgoing trace
+// context and baggage
+func Propagator() propagation.TextMapPropagator {
+	return propagator
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a Go context carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// ExtractRequest returns a Go context derived from the request's carrying
+// the request ID, W3C trace context and baggage sent by the caller. The
+// request ID is taken from the X-Request-ID header, then from one already
+// assigned by RequestIDMiddleware, and generated with cfg's ID settings
+// otherwise.
+func ExtractRequest(c *gin.Context, cfg *config.FrameworkConfig) (context.Context, string) {
+	goCtx := context.Background()
+	var header http.Header
+	if c.Request != nil {
+		goCtx = c.Request.Context()
+		header = c.Request.Header
+	}
+
+	requestID := RequestIDFromContext(goCtx)
+	if requestID == "" {
+		requestID = header.Get(RequestIDHeader)
+	}
+	if requestID == "" {
+		requestID = c.GetString("request_id")
+	}
+	if requestID == "" {
+		requestID = generateRequestID(cfg)
+	}
+
+	if header != nil {
+		goCtx = propagator.Extract(goCtx, propagation.HeaderCarrier(header))
+	}
+	return ContextWithRequestID(goCtx, requestID), requestID
+}
+
+// InjectHeaders writes the request ID, trace context and baggage carried by
+// ctx into header for an upstream call. A request ID header already set on
+// the outgoing request is kept.
+func InjectHeaders(ctx context.Context, header http.Header) {
+	if ctx == nil {
+		return
+	}
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+	if requestID := RequestIDFromContext(ctx); requestID != "" && header.Get(RequestIDHeader) == "" {
+		header.Set(RequestIDHeader, requestID)
+	}
+}
@@ -0,0 +1,148 @@
+package flow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// newGinContext returns a Gin context for a GET request with the given
+// headers
+func newGinContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return c, recorder
+}
+
+func TestNewContextFromGin_Propagation(t *testing.T) {
+	c, _ := newGinContext(map[string]string{
+		"X-Request-ID": "req-from-client",
+		"traceparent":  testTraceparent,
+		"baggage":      "tenant=acme",
+	})
+
+	ctx := NewContextFromGin(c, nil)
+
+	if ctx.RequestID() != "req-from-client" {
+		t.Errorf("RequestID() = %q, want req-from-client", ctx.RequestID())
+	}
+	if id, _ := ctx.GetString("request_id"); id != "req-from-client" {
+		t.Errorf("request_id = %q, want req-from-client", id)
+	}
+	sc := trace.SpanContextFromContext(ctx.Context())
+	if !sc.IsRemote() || sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Span context = %+v, want the remote parent from traceparent", sc)
+	}
+	if got := baggage.FromContext(ctx.Context()).Member("tenant").Value(); got != "acme" {
+		t.Errorf("Baggage tenant = %q, want acme", got)
+	}
+}
+
+func TestNewContextFromGin_RequestHeaders(t *testing.T) {
+	c, _ := newGinContext(map[string]string{"X-Platform": "ios"})
+
+	ctx := NewContextFromGin(c, nil)
+
+	headers, ok := ctx.Get(RequestHeadersKey)
+	if !ok {
+		t.Fatal("The request headers should be stored in the context")
+	}
+	if got := headers.(http.Header).Get("x-platform"); got != "ios" {
+		t.Errorf("X-Platform = %q, want ios", got)
+	}
+}
+
+func TestNewContextFromGin_GeneratesRequestID(t *testing.T) {
+	c, _ := newGinContext(nil)
+
+	ctx := NewContextFromGin(c, nil)
+
+	if ctx.RequestID() == "" {
+		t.Error("A request ID should be generated when the client sends none")
+	}
+}
+
+func TestRequestIDMiddlewareWithConfig(t *testing.T) {
+	c, _ := newGinContext(nil)
+	cfg := config.DefaultConfig()
+	cfg.IDs.Generator = func() string { return "fixed" }
+
+	RequestIDMiddlewareWithConfig(cfg)(c)
+
+	if got := c.GetString("request_id"); got != "req_fixed" {
+		t.Errorf("request_id = %q, want req_fixed from the configured generator", got)
+	}
+}
+
+func TestFlowContextMiddleware_HonorsRequestIDMiddleware(t *testing.T) {
+	c, recorder := newGinContext(nil)
+
+	RequestIDMiddleware()(c)
+	assigned := c.GetString("request_id")
+	FlowContextMiddleware(nil, zap.NewNop())(c)
+
+	flowCtx, ok := GetFlowContext(c)
+	if !ok {
+		t.Fatal("Flow context should be stored in the Gin context")
+	}
+	if got := flowCtx.(*Context).RequestID(); got != assigned {
+		t.Errorf("Flow request ID = %q, want %q from RequestIDMiddleware", got, assigned)
+	}
+	if got := recorder.Header().Get(RequestIDHeader); got != assigned {
+		t.Errorf("Response X-Request-ID = %q, want %q", got, assigned)
+	}
+	if got := RequestIDFromContext(c.Request.Context()); got != assigned {
+		t.Errorf("Request context ID = %q, want %q", got, assigned)
+	}
+}
+
+func TestInjectHeaders(t *testing.T) {
+	c, _ := newGinContext(map[string]string{
+		"X-Request-ID": "req-1",
+		"traceparent":  testTraceparent,
+		"baggage":      "tenant=acme",
+	})
+	goCtx, _ := ExtractRequest(c, nil)
+
+	header := http.Header{}
+	InjectHeaders(goCtx, header)
+
+	if header.Get(RequestIDHeader) != "req-1" {
+		t.Errorf("X-Request-ID = %q, want req-1", header.Get(RequestIDHeader))
+	}
+	if header.Get("traceparent") != testTraceparent {
+		t.Errorf("traceparent = %q, want %q", header.Get("traceparent"), testTraceparent)
+	}
+	if header.Get("baggage") != "tenant=acme" {
+		t.Errorf("baggage = %q, want tenant=acme", header.Get("baggage"))
+	}
+
+	// An explicit request ID on the outgoing request wins
+	header = http.Header{}
+	header.Set(RequestIDHeader, "explicit")
+	InjectHeaders(goCtx, header)
+	if header.Get(RequestIDHeader) != "explicit" {
+		t.Errorf("X-Request-ID = %q, want explicit", header.Get(RequestIDHeader))
+	}
+
+	header = http.Header{}
+	InjectHeaders(context.Background(), header)
+	if len(header) != 0 {
+		t.Errorf("Headers = %v, want none without propagated state", header)
+	}
+}
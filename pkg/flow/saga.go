@@ -0,0 +1,122 @@
+package flow
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// Saga outcomes reported in the saga_executions_total metric
+const (
+	SagaCompleted          = "completed"
+	SagaFailed             = "failed"
+	SagaCompensated        = "compensated"
+	SagaCompensationFailed = "compensation_failed"
+)
+
+// StepWithCompensation adds a step together with the step that undoes it.
+// If a later step fails, the compensations of the steps that completed are
+// run in reverse order before any OnError handler or Catch block. A flow
+// with at least one compensated step is treated as a saga and reports its
+// outcome in the saga_executions_total metric.
+func (f *Flow) StepWithCompensation(name string, step, compensate interfaces.Step) *Flow {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	if compensate.Name() == "anonymous" {
+		compensate = &namedStep{Step: compensate, name: "compensate_" + name}
+	}
+	f.steps = append(f.steps, &compensableStep{Step: step, compensate: compensate})
+	f.saga = true
+	return f
+}
+
+// compensableStep pairs a step with the step that undoes it
+type compensableStep struct {
+	interfaces.Step
+	compensate interfaces.Step
+}
+
+// CompensationResult records the outcome of one compensation
+type CompensationResult struct {
+	Step         string
+	Compensation string
+	Duration     time.Duration
+	Error        error
+}
+
+// Success reports whether the compensation succeeded
+func (cr CompensationResult) Success() bool {
+	return cr.Error == nil
+}
+
+// compensate runs the compensations of the completed steps in reverse
+// order. A failing compensation is recorded and the remaining ones still
+// run.
+func (f *Flow) compensate(ctx interfaces.ExecutionContext, completed []*compensableStep) []CompensationResult {
+	results := make([]CompensationResult, 0, len(completed))
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		start := time.Now()
+		err := runStep(ctx, step.compensate)
+
+		result := CompensationResult{
+			Step:         step.Name(),
+			Compensation: step.compensate.Name(),
+			Duration:     time.Since(start),
+			Error:        err,
+		}
+		results = append(results, result)
+
+		if err != nil {
+			ctx.Logger().Error("Compensation failed",
+				zap.String("flow", f.name),
+				zap.String("step", result.Step),
+				zap.String("compensation", result.Compensation),
+				zap.Error(err))
+		} else {
+			ctx.Logger().Info("Step compensated",
+				zap.String("flow", f.name),
+				zap.String("step", result.Step),
+				zap.String("compensation", result.Compensation),
+				zap.Duration("duration", result.Duration))
+		}
+	}
+	return results
+}
+
+// sagaOutcome classifies a saga execution for metrics
+func sagaOutcome(failed bool, compensations []CompensationResult) string {
+	if !failed {
+		return SagaCompleted
+	}
+	if len(compensations) == 0 {
+		return SagaFailed
+	}
+	for _, c := range compensations {
+		if !c.Success() {
+			return SagaCompensationFailed
+		}
+	}
+	return SagaCompensated
+}
+
+// recordSaga reports a saga execution outcome
+func (f *Flow) recordSaga(outcome string, compensations []CompensationResult) {
+	tags := map[string]string{
+		"flow_name": f.name,
+		"outcome":   outcome,
+	}
+	metrics.IncrementCounter("saga_executions_total", tags)
+
+	if len(compensations) > 0 {
+		var total time.Duration
+		for _, c := range compensations {
+			total += c.Duration
+		}
+		metrics.RecordDuration("saga_compensation_duration_seconds", total, tags)
+	}
+}
@@ -0,0 +1,187 @@
+package flow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// recordingStep appends its name to a shared log when it runs
+func recordingStep(log *[]string, name string, err error) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(interfaces.ExecutionContext) error {
+		*log = append(*log, name)
+		return err
+	}}
+}
+
+// useInMemoryMetrics swaps the global metrics collector for the test
+func useInMemoryMetrics(t *testing.T) *metrics.InMemoryMetrics {
+	t.Helper()
+	previous := metrics.GetGlobalMetrics()
+	collector := metrics.NewInMemoryMetrics()
+	metrics.SetGlobalMetrics(collector)
+	t.Cleanup(func() { metrics.SetGlobalMetrics(previous) })
+	return collector
+}
+
+// sagaCount returns the saga_executions_total counter for an outcome
+func sagaCount(collector *metrics.InMemoryMetrics, outcome string) int64 {
+	var total int64
+	for key, value := range collector.GetCounters() {
+		if strings.HasPrefix(key, "saga_executions_total,") && strings.Contains(key, "outcome="+outcome) {
+			total += value
+		}
+	}
+	return total
+}
+
+func TestFlow_StepWithCompensation_ReverseOrder(t *testing.T) {
+	collector := useInMemoryMetrics(t)
+	var log []string
+	flow := NewFlow("onboarding").
+		StepWithCompensation("submit", recordingStep(&log, "submit", nil), recordingStep(&log, "undo_submit", nil)).
+		StepWithCompensation("progress", recordingStep(&log, "progress", nil), recordingStep(&log, "undo_progress", nil)).
+		Step("next_screen", recordingStep(&log, "next_screen", errors.New("upstream down"))).
+		OnError("next_screen", func(_ interfaces.ExecutionContext, stepErr *StepError) error {
+			log = append(log, "on_error")
+			return stepErr
+		})
+
+	result, err := flow.Execute(newTestContext())
+
+	if err == nil {
+		t.Fatal("Execute() should fail")
+	}
+	want := []string{"submit", "progress", "next_screen", "undo_progress", "undo_submit", "on_error"}
+	if strings.Join(log, ",") != strings.Join(want, ",") {
+		t.Errorf("Order = %v, want %v", log, want)
+	}
+
+	if len(result.Compensations) != 2 {
+		t.Fatalf("Compensations = %d, want 2", len(result.Compensations))
+	}
+	first := result.Compensations[0]
+	if first.Step != "progress" || first.Compensation != "undo_progress" || !first.Success() {
+		t.Errorf("First compensation = %+v, want progress/undo_progress succeeded", first)
+	}
+	if sagaCount(collector, SagaCompensated) != 1 {
+		t.Errorf("Counters = %v, want one compensated saga", collector.GetCounters())
+	}
+}
+
+func TestFlow_StepWithCompensation_FailingCompensation(t *testing.T) {
+	collector := useInMemoryMetrics(t)
+	var log []string
+	flow := NewFlow("onboarding").
+		StepWithCompensation("submit", recordingStep(&log, "submit", nil), recordingStep(&log, "undo_submit", nil)).
+		StepWithCompensation("progress", recordingStep(&log, "progress", nil),
+			recordingStep(&log, "undo_progress", errors.New("cannot undo"))).
+		Step("next_screen", recordingStep(&log, "next_screen", errors.New("upstream down")))
+
+	result, _ := flow.Execute(newTestContext())
+
+	if len(result.Compensations) != 2 {
+		t.Fatalf("Compensations = %d, want 2 (a failure should not stop the rollback)", len(result.Compensations))
+	}
+	if result.Compensations[0].Success() || !result.Compensations[1].Success() {
+		t.Errorf("Compensations = %+v, want undo_progress failed and undo_submit succeeded", result.Compensations)
+	}
+	if sagaCount(collector, SagaCompensationFailed) != 1 {
+		t.Errorf("Counters = %v, want one compensation_failed saga", collector.GetCounters())
+	}
+
+	response := result.GetResponse()
+	entries, ok := response["compensations"].([]map[string]interface{})
+	if !ok || len(entries) != 2 || entries[0]["error"] != "cannot undo" {
+		t.Errorf("Response compensations = %v", response["compensations"])
+	}
+}
+
+func TestFlow_StepWithCompensation_Success(t *testing.T) {
+	collector := useInMemoryMetrics(t)
+	var log []string
+	flow := NewFlow("onboarding").
+		StepWithCompensation("submit", recordingStep(&log, "submit", nil), recordingStep(&log, "undo_submit", nil))
+
+	result, err := flow.Execute(newTestContext())
+
+	if err != nil || len(result.Compensations) != 0 {
+		t.Errorf("Execute() error = %v, compensations = %v, want clean success", err, result.Compensations)
+	}
+	if len(log) != 1 {
+		t.Errorf("Ran %v, want only submit", log)
+	}
+	if sagaCount(collector, SagaCompleted) != 1 {
+		t.Errorf("Counters = %v, want one completed saga", collector.GetCounters())
+	}
+}
+
+func TestFlow_StepWithCompensation_FailingStepNotCompensated(t *testing.T) {
+	collector := useInMemoryMetrics(t)
+	var log []string
+	flow := NewFlow("onboarding").
+		StepWithCompensation("submit", recordingStep(&log, "submit", errors.New("rejected")),
+			recordingStep(&log, "undo_submit", nil))
+
+	result, _ := flow.Execute(newTestContext())
+
+	if len(result.Compensations) != 0 || len(log) != 1 {
+		t.Errorf("Compensations = %v, ran %v; a failed step should not be compensated", result.Compensations, log)
+	}
+	if sagaCount(collector, SagaFailed) != 1 {
+		t.Errorf("Counters = %v, want one failed saga", collector.GetCounters())
+	}
+}
+
+func TestFlow_StepWithCompensation_Retry(t *testing.T) {
+	var log []string
+	attempts := 0
+	flaky := &mockStep{name: "submit", runFunc: func(interfaces.ExecutionContext) error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("flaky")
+		}
+		return nil
+	}}
+	flow := NewFlow("onboarding").
+		StepWithCompensation("submit", flaky, recordingStep(&log, "undo_submit", nil)).
+		Retry(2, time.Millisecond).
+		Step("next_screen", recordingStep(&log, "next_screen", errors.New("upstream down")))
+
+	result, _ := flow.Execute(newTestContext())
+
+	if attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", attempts)
+	}
+	if len(result.Compensations) != 1 || result.Compensations[0].Compensation != "undo_submit" {
+		t.Errorf("Compensations = %+v, want undo_submit to survive Retry", result.Compensations)
+	}
+}
+
+func TestFlow_StepWithCompensation_FlowTimeout(t *testing.T) {
+	var log []string
+	flow := NewFlow("onboarding").
+		WithTimeout(30*time.Millisecond).
+		StepWithCompensation("submit", recordingStep(&log, "submit", nil),
+			StepFunc(func(ctx interfaces.ExecutionContext) error {
+				if ctx.Context().Err() != nil {
+					t.Error("Compensation should not inherit the expired flow deadline")
+				}
+				log = append(log, "undo_submit")
+				return nil
+			})).
+		Step("slow", NewDelayStep("slow", 5*time.Second))
+
+	result, _ := flow.Execute(newTestContext())
+
+	if !result.TimedOut || len(result.Compensations) != 1 {
+		t.Fatalf("TimedOut = %v, compensations = %v", result.TimedOut, result.Compensations)
+	}
+	if result.Compensations[0].Compensation != "compensate_submit" {
+		t.Errorf("Compensation name = %v, want compensate_submit", result.Compensations[0].Compensation)
+	}
+}
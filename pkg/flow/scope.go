@@ -0,0 +1,363 @@
+package flow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// childContext returns a view over parent that reads through to it and
+// keeps its own writes and deletions
+func childContext(parent interfaces.ExecutionContext) *Context {
+	child := &Context{
+		ctx:         parent.Context(),
+		values:      make(map[string]interface{}),
+		mu:          &sync.RWMutex{},
+		parent:      parent,
+		deleted:     make(map[string]struct{}),
+		flowName:    parent.FlowName(),
+		executionID: parent.ExecutionID(),
+		branchID:    contextBranchID(parent),
+		startTime:   parent.StartTime(),
+		logger:      parent.Logger(),
+		config:      contextConfig(parent),
+	}
+	if c, ok := parent.(*Context); ok {
+		child.span = c.span
+		child.timeout = c.timeout
+		child.history = c.History()
+	}
+	if child.config == nil {
+		child.config = config.DefaultConfig()
+	}
+	return child
+}
+
+// local returns the values written in a scoped view
+func (c *Context) local() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make(map[string]interface{}, len(c.values))
+	for k, v := range c.values {
+		values[k] = v
+	}
+	return values
+}
+
+// ScopeStep runs steps in their own scope. The steps read everything the
+// enclosing context holds, but their writes and deletions stay in the scope
+// unless exported: WithExports copies chosen values to the parent, and
+// WithNamespace publishes all of the scope's writes as one map. Two scoped
+// HTTP steps can therefore both write "http_response" without clobbering
+// each other.
+//
+// Writes inside the scope are attributed to the inner steps in the trace;
+// only the exports are writes of the scope step itself.
+type ScopeStep struct {
+	*BaseStep
+	steps     []interfaces.Step
+	exports   map[string]string
+	namespace string
+}
+
+// NewScopeStep creates a scope running steps in order. The inner steps
+// enforce their own timeouts, so the scope has none.
+func NewScopeStep(name string, steps ...interfaces.Step) *ScopeStep {
+	base := NewBaseStep(name, fmt.Sprintf("Scope %s", name))
+	base.WithTimeout(0)
+	return &ScopeStep{
+		BaseStep: base,
+		steps:    steps,
+		exports:  make(map[string]string),
+	}
+}
+
+// Export copies the given keys to the parent under the same names once the
+// scope succeeds
+func (s *ScopeStep) Export(keys ...string) *ScopeStep {
+	for _, key := range keys {
+		s.exports[key] = key
+	}
+	return s
+}
+
+// WithExports maps scope values to the parent once the scope succeeds, from
+// a dot-notation path in the scope to a key in the parent. Missing values
+// are not set.
+func (s *ScopeStep) WithExports(exports map[string]string) *ScopeStep {
+	for from, to := range exports {
+		s.exports[from] = to
+	}
+	return s
+}
+
+// WithNamespace sets namespace in the parent to a map of everything the
+// scope wrote, so that e.g. "user.http_response" can be read with
+// dot-notation paths afterwards
+func (s *ScopeStep) WithNamespace(namespace string) *ScopeStep {
+	s.namespace = namespace
+	return s
+}
+
+func (s *ScopeStep) Run(ctx interfaces.ExecutionContext) error {
+	isolateWrites(ctx)
+
+	// The scope's writes are not writes of an enclosing parallel branch
+	scope := childContext(ctx)
+	scoped := withBranchWrites(scope, nil)
+	for _, step := range s.steps {
+		if err := runStep(scoped, step); err != nil {
+			return err
+		}
+	}
+
+	for from, to := range s.exports {
+		if value, ok := values.Lookup(from, scope); ok {
+			ctx.Set(to, value)
+		}
+	}
+	if s.namespace != "" {
+		ctx.Set(s.namespace, scope.local())
+	}
+	return nil
+}
+
+// Scope starts a block of steps run in their own scope
+func (f *Flow) Scope(name string) *ScopeBuilder {
+	return &ScopeBuilder{
+		flow: f,
+		step: NewScopeStep(name),
+	}
+}
+
+// ScopeBuilder builds Scope blocks
+type ScopeBuilder struct {
+	flow *Flow
+	step *ScopeStep
+}
+
+// Step adds a step to the scope
+func (sb *ScopeBuilder) Step(name string, step interfaces.Step) *ScopeBuilder {
+	if step.Name() == "anonymous" {
+		step = &namedStep{Step: step, name: name}
+	}
+	sb.step.steps = append(sb.step.steps, step)
+	return sb
+}
+
+// StepFunc adds a function step to the scope
+func (sb *ScopeBuilder) StepFunc(name string, fn func(interfaces.ExecutionContext) error) *ScopeBuilder {
+	sb.step.steps = append(sb.step.steps, &namedStep{Step: StepFunc(fn), name: name})
+	return sb
+}
+
+// Export copies keys to the parent under the same names
+func (sb *ScopeBuilder) Export(keys ...string) *ScopeBuilder {
+	sb.step.Export(keys...)
+	return sb
+}
+
+// ExportAs copies the value at path in the scope to key in the parent
+func (sb *ScopeBuilder) ExportAs(path, key string) *ScopeBuilder {
+	sb.step.WithExports(map[string]string{path: key})
+	return sb
+}
+
+// Namespace publishes all of the scope's writes under namespace
+func (sb *ScopeBuilder) Namespace(namespace string) *ScopeBuilder {
+	sb.step.WithNamespace(namespace)
+	return sb
+}
+
+// EndScope completes the block
+func (sb *ScopeBuilder) EndScope() *Flow {
+	sb.flow.steps = append(sb.flow.steps, sb.step)
+	return sb.flow
+}
+
+// readOnlyWrites collects the writes rejected by a read-only view
+type readOnlyWrites struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (w *readOnlyWrites) reject(ctx *Context, key string) {
+	ctx.Logger().Warn("Write to read-only context rejected", zap.String("key", key))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.keys == nil {
+		w.keys = make(map[string]struct{})
+	}
+	w.keys[key] = struct{}{}
+}
+
+func (w *readOnlyWrites) rejected() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return sortedKeys(w.keys)
+}
+
+// readOnlyStep runs a step on a view of the context that rejects writes
+type readOnlyStep struct {
+	step interfaces.Step
+}
+
+// ReadOnly wraps step so that it can read the context but not change it.
+// Sets and deletes made by the step are dropped, logged, and fail the step
+// once it returns. The wrapper keeps the step's name and timeout.
+func ReadOnly(step interfaces.Step) interfaces.Step {
+	return &readOnlyStep{step: step}
+}
+
+func (s *readOnlyStep) Name() string {
+	return s.step.Name()
+}
+
+func (s *readOnlyStep) Description() string {
+	return s.step.Description()
+}
+
+func (s *readOnlyStep) Run(ctx interfaces.ExecutionContext) error {
+	view := childContext(ctx)
+	view.readOnly = &readOnlyWrites{}
+
+	if err := s.step.Run(view); err != nil {
+		return err
+	}
+	if keys := view.readOnly.rejected(); len(keys) > 0 {
+		return errors.NewInternalError(errors.ErrCodeReadOnlyContext,
+			fmt.Sprintf("Step '%s' wrote to a read-only context: %s", s.Name(), strings.Join(keys, ", "))).
+			WithContext("step", s.Name()).
+			WithContext("keys", keys)
+	}
+	return nil
+}
+
+// WriteCheck decides what happens when two steps of a flow write the same
+// context key, which usually means one silently overwrote the other's
+// result
+type WriteCheck string
+
+const (
+	// WriteCheckWarn logs the overwrite and records it in
+	// ExecutionResult.Overwrites (the default)
+	WriteCheckWarn WriteCheck = "warn"
+	// WriteCheckFail additionally fails the step that overwrote the key
+	WriteCheckFail WriteCheck = "fail"
+	// WriteCheckOff disables the check
+	WriteCheckOff WriteCheck = "off"
+)
+
+// ParseWriteCheck converts a configuration value to a WriteCheck
+func ParseWriteCheck(name string) (WriteCheck, error) {
+	switch check := WriteCheck(name); check {
+	case WriteCheckWarn, WriteCheckFail, WriteCheckOff:
+		return check, nil
+	default:
+		return "", fmt.Errorf("unknown write check '%s'", name)
+	}
+}
+
+// Overwrite records a context key written by a step after another step had
+// written it
+type Overwrite struct {
+	Key          string
+	Step         string
+	PreviousStep string
+}
+
+// BookkeepingKeys are context keys the framework's steps rewrite on every
+// run, such as the metadata of the last HTTP response or the result of the
+// last condition. They are exempt from the write check.
+var BookkeepingKeys = []string{
+	"http_metadata", "http_response", "cache_hit", "cache_created_at", "condition_result",
+}
+
+// writeCheck tracks which step first wrote each key during an execution.
+// Writes inside parallel branches are left to the branch merge policy and
+// writes inside scopes stay local, so neither is checked.
+type writeCheck struct {
+	mode       WriteCheck
+	allowed    map[string]bool
+	writers    map[string]string
+	overwrites []Overwrite
+}
+
+func newWriteCheck(mode WriteCheck, allowed []string) *writeCheck {
+	if mode == WriteCheckOff {
+		return nil
+	}
+	if mode == "" {
+		mode = WriteCheckWarn
+	}
+	check := &writeCheck{
+		mode:    mode,
+		allowed: make(map[string]bool, len(BookkeepingKeys)+len(allowed)),
+		writers: make(map[string]string),
+	}
+	for _, key := range BookkeepingKeys {
+		check.allowed[key] = true
+	}
+	for _, key := range allowed {
+		check.allowed[key] = true
+	}
+	return check
+}
+
+// record notes that step wrote key and reports whether that overwrote
+// another step's write
+func (w *writeCheck) record(step, key string) (Overwrite, bool) {
+	if w.allowed[key] {
+		return Overwrite{}, false
+	}
+	previous, ok := w.writers[key]
+	if !ok {
+		w.writers[key] = step
+		return Overwrite{}, false
+	}
+	if previous == step {
+		return Overwrite{}, false
+	}
+	overwrite := Overwrite{Key: key, Step: step, PreviousStep: previous}
+	for _, o := range w.overwrites {
+		if o == overwrite {
+			return overwrite, true
+		}
+	}
+	w.overwrites = append(w.overwrites, overwrite)
+	return overwrite, true
+}
+
+// overwriteError reports the first overwrite made by a step when the check
+// fails steps
+func overwriteError(step string, overwrites []Overwrite) error {
+	sort.SliceStable(overwrites, func(i, j int) bool { return overwrites[i].Key < overwrites[j].Key })
+	o := overwrites[0]
+	return errors.NewInternalError(errors.ErrCodeDuplicateWrite,
+		fmt.Sprintf("Step '%s' overwrote context key '%s' written by step '%s'", step, o.Key, o.PreviousStep)).
+		WithContext("step", step).
+		WithContext("key", o.Key).
+		WithContext("previous_step", o.PreviousStep)
+}
+
+// AllowOverwrite exempts keys from the write check, for keys that steps
+// refine in place on purpose
+func (f *Flow) AllowOverwrite(keys ...string) *Flow {
+	f.allowOverwrite = append(f.allowOverwrite, keys...)
+	return f
+}
+
+// WithWriteCheck sets how the flow reacts to two steps writing the same key
+func (f *Flow) WithWriteCheck(check WriteCheck) *Flow {
+	f.writeCheck = check
+	return f
+}
@@ -0,0 +1,217 @@
+package flow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// responder writes http_response like an HTTP step without SaveAs
+func responder(name string, body interface{}) interfaces.Step {
+	return writer(name, 0, map[string]interface{}{"http_response": body})
+}
+
+func TestScope_Namespace(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("user_id", "u1")
+
+	result, err := NewFlow("handler").
+		Scope("user").
+		Step("fetch_user", responder("fetch_user", "user body")).
+		Namespace("user").
+		EndScope().
+		Scope("orders").
+		Step("fetch_orders", responder("fetch_orders", "orders body")).
+		StepFunc("count", func(ctx interfaces.ExecutionContext) error {
+			// The scope reads the parent's data and its own writes
+			if id, _ := ctx.GetString("user_id"); id != "u1" {
+				return errors.New("parent data not visible in the scope")
+			}
+			response, _ := ctx.Get("http_response")
+			ctx.Set("count", len(response.(string)))
+			return nil
+		}).
+		Namespace("orders").
+		EndScope().
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if ctx.Has("http_response") || ctx.Has("count") {
+		t.Error("Scoped writes should not reach the parent")
+	}
+	if body, _ := GetPath[string](ctx, "user.http_response"); body != "user body" {
+		t.Errorf("user.http_response = %q", body)
+	}
+	if count, _ := GetPath[int](ctx, "orders.count"); count != 11 {
+		t.Errorf("orders.count = %d, want 11", count)
+	}
+	if len(result.Overwrites) != 0 {
+		t.Errorf("Overwrites = %+v, scoped writes should not be checked", result.Overwrites)
+	}
+
+	// Inner writes belong to the inner steps; the scope only wrote its export
+	if keys := findStep(result.Steps, "fetch_user")[0].KeysWritten; strings.Join(keys, ",") != "http_response" {
+		t.Errorf("fetch_user KeysWritten = %v", keys)
+	}
+	if keys := findStep(result.Steps, "orders")[0].KeysWritten; strings.Join(keys, ",") != "orders" {
+		t.Errorf("orders KeysWritten = %v, want only the namespace", keys)
+	}
+}
+
+func TestScope_ExportsAndDeletes(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("token", "secret")
+
+	step := NewScopeStep("load",
+		writer("fetch", 0, map[string]interface{}{
+			"http_response": map[string]interface{}{"body": map[string]interface{}{"name": "Ada"}},
+			"scratch":       true,
+		}),
+		StepFunc(func(ctx interfaces.ExecutionContext) error {
+			ctx.Delete("token")
+			if ctx.Has("token") {
+				return errors.New("deleted key still visible in the scope")
+			}
+			return nil
+		}),
+	).Export("scratch").WithExports(map[string]string{"http_response.body.name": "name"})
+
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if name, _ := ctx.GetString("name"); name != "Ada" {
+		t.Errorf("name = %q, want the exported path", name)
+	}
+	if !ctx.Has("scratch") || ctx.Has("http_response") {
+		t.Errorf("Keys = %v, want only the exports", ctx.Keys())
+	}
+	if !ctx.Has("token") {
+		t.Error("Deleting in a scope should not delete from the parent")
+	}
+}
+
+func TestScope_ParallelInsideScope(t *testing.T) {
+	ctx := newTestContext()
+
+	step := NewScopeStep("fanout",
+		NewParallelStep("parallel",
+			writer("a", 0, map[string]interface{}{"a": 1}),
+			writer("b", 0, map[string]interface{}{"b": 2}),
+		),
+	).WithNamespace("results")
+
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	results, _ := ctx.GetMap("results")
+	if results["a"] != 1 || results["b"] != 2 || ctx.Has("a") {
+		t.Errorf("results = %v, want the merged branch writes in the namespace", results)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("user", "Ada")
+
+	var seen interface{}
+	reader := StepFunc(func(ctx interfaces.ExecutionContext) error {
+		seen, _ = ctx.Get("user")
+		return nil
+	})
+	if err := ReadOnly(reader).Run(ctx); err != nil || seen != "Ada" {
+		t.Errorf("Run() = %v, seen %v; want reads to work", err, seen)
+	}
+
+	mutator := writer("mutator", 0, map[string]interface{}{"user": "Grace"})
+	result, err := NewFlow("f").Step("mutator", ReadOnly(mutator)).Execute(ctx)
+
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeReadOnlyContext {
+		t.Fatalf("Execute() error = %v, want READ_ONLY_CONTEXT", err)
+	}
+	if result.FailedStep != "mutator" {
+		t.Errorf("FailedStep = %q, want the wrapped step's name", result.FailedStep)
+	}
+	if user, _ := ctx.GetString("user"); user != "Ada" {
+		t.Errorf("user = %q, the write should have been rejected", user)
+	}
+}
+
+func TestWriteCheck(t *testing.T) {
+	build := func() *Flow {
+		return NewFlow("profile").
+			Step("fetch_user", writer("fetch_user", 0, map[string]interface{}{"response": "user"})).
+			Step("fetch_orders", writer("fetch_orders", 0, map[string]interface{}{"response": "orders"})).
+			Step("render", setter("render", "view"))
+	}
+
+	result, err := build().Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := Overwrite{Key: "response", Step: "fetch_orders", PreviousStep: "fetch_user"}
+	if len(result.Overwrites) != 1 || result.Overwrites[0] != want {
+		t.Errorf("Overwrites = %+v, want %+v", result.Overwrites, want)
+	}
+
+	result, err = build().WithWriteCheck(WriteCheckFail).Execute(newTestContext())
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeDuplicateWrite {
+		t.Fatalf("Execute() error = %v, want DUPLICATE_WRITE", err)
+	}
+	if result.FailedStep != "fetch_orders" || len(findStep(result.Steps, "render")) != 0 {
+		t.Errorf("FailedStep = %q, want the overwriting step to stop the flow", result.FailedStep)
+	}
+
+	result, _ = build().AllowOverwrite("response").Execute(newTestContext())
+	if len(result.Overwrites) != 0 {
+		t.Errorf("Overwrites = %+v, want allowed keys ignored", result.Overwrites)
+	}
+	result, _ = build().WithWriteCheck(WriteCheckOff).Execute(newTestContext())
+	if len(result.Overwrites) != 0 {
+		t.Errorf("Overwrites = %+v, want the check disabled", result.Overwrites)
+	}
+
+	// Bookkeeping keys rewritten by every step of a kind are not checked
+	result, err = NewFlow("profile").
+		WithWriteCheck(WriteCheckFail).
+		Step("fetch_user", responder("fetch_user", "user")).
+		Step("fetch_orders", responder("fetch_orders", "orders")).
+		Execute(newTestContext())
+	if err != nil || len(result.Overwrites) != 0 {
+		t.Errorf("Execute() = %+v, %v, want bookkeeping keys ignored", result.Overwrites, err)
+	}
+}
+
+func TestWriteCheck_RepeatedAndBranchWrites(t *testing.T) {
+	// A retried or looped step rewriting its own key and parallel branches
+	// governed by a merge policy are not overwrites
+	result, err := NewFlow("f").
+		Loop("poll").
+		MaxIterations(3).
+		Step("check", setter("check", "status")).
+		EndLoop().
+		Parallel("fanout").
+		Step("a", setter("a", "shared")).
+		Step("b", setter("b", "shared")).
+		EndParallel().
+		Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(result.Overwrites) != 0 {
+		t.Errorf("Overwrites = %+v, want none", result.Overwrites)
+	}
+
+	if _, err := ParseWriteCheck("fail"); err != nil {
+		t.Errorf("ParseWriteCheck(fail) error = %v", err)
+	}
+	if _, err := ParseWriteCheck("loud"); err == nil {
+		t.Error("ParseWriteCheck should reject unknown values")
+	}
+}
@@ -1,11 +1,14 @@
 package flow
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
 )
@@ -81,6 +84,94 @@ func (w *StepWrapper) Description() string {
 	return w.step.Description()
 }
 
+// ContextStep is a step written against *Context instead of
+// interfaces.ExecutionContext, as older and third-party steps often are
+// (base.Step has the same shape)
+type ContextStep interface {
+	Run(ctx *Context) error
+	Name() string
+	Description() string
+}
+
+// ContextStepAdapter adapts a ContextStep to interfaces.Step so it can be
+// used with Flow.Step, composite steps and the step registry
+type ContextStepAdapter struct {
+	step ContextStep
+}
+
+// AdaptContextStep wraps a *Context based step as an interfaces.Step
+func AdaptContextStep(step ContextStep) *ContextStepAdapter {
+	return &ContextStepAdapter{step: step}
+}
+
+// Run executes the wrapped step. A *Context (or a view of one) is passed
+// through unchanged; any other ExecutionContext is copied into a *Context
+// and the step's writes and deletes are applied back afterwards.
+func (a *ContextStepAdapter) Run(ctx interfaces.ExecutionContext) error {
+	flowCtx, sync := asContext(ctx)
+	err := a.step.Run(flowCtx)
+	sync()
+	return err
+}
+
+func (a *ContextStepAdapter) Name() string {
+	return a.step.Name()
+}
+
+func (a *ContextStepAdapter) Description() string {
+	return a.step.Description()
+}
+
+// Unwrap returns the adapted step
+func (a *ContextStepAdapter) Unwrap() ContextStep {
+	return a.step
+}
+
+// asContext returns ctx as a *Context together with a function that
+// propagates changes back to ctx when a copy had to be made
+func asContext(ctx interfaces.ExecutionContext) (*Context, func()) {
+	switch c := ctx.(type) {
+	case *Context:
+		return c, func() {}
+	case *contextView:
+		if inner, ok := c.ExecutionContext.(*Context); ok {
+			view := inner.withGoContext(c.ctx)
+			view.detached = c.detached
+			return view, func() {}
+		}
+	}
+
+	flowCtx := NewContext().
+		WithContext(ctx.Context()).
+		WithFlowName(ctx.FlowName()).
+		WithLogger(ctx.Logger())
+	flowCtx.executionID = ctx.ExecutionID()
+	flowCtx.startTime = ctx.StartTime()
+	if cfg := contextConfig(ctx); cfg != nil {
+		flowCtx.config = cfg
+	}
+
+	original := ctx.Keys()
+	for _, key := range original {
+		if val, ok := ctx.Get(key); ok {
+			flowCtx.Set(key, val)
+		}
+	}
+
+	return flowCtx, func() {
+		for _, key := range original {
+			if !flowCtx.Has(key) {
+				ctx.Delete(key)
+			}
+		}
+		for _, key := range flowCtx.Keys() {
+			if val, ok := flowCtx.Get(key); ok {
+				ctx.Set(key, val)
+			}
+		}
+	}
+}
+
 // StepFunc is a function type that implements interfaces.Step
 type StepFunc func(ctx interfaces.ExecutionContext) error
 
@@ -96,11 +187,22 @@ func (f StepFunc) Description() string {
 	return "Anonymous step function"
 }
 
-// BaseStep provides common functionality for steps
+// NewNamedStep gives a step a different name without changing its behavior
+func NewNamedStep(name string, step interfaces.Step) interfaces.Step {
+	if step.Name() == name {
+		return step
+	}
+	return &namedStep{Step: step, name: name}
+}
+
+// BaseStep provides common functionality for steps. Until WithTimeout is
+// called, the step runs under the configured step execution timeout when
+// there is one, and under its default timeout otherwise.
 type BaseStep struct {
 	name        string
 	description string
 	timeout     time.Duration
+	timeoutSet  bool
 }
 
 // NewBaseStep creates a new base step
@@ -112,6 +214,12 @@ func NewBaseStep(name, description string) *BaseStep {
 	}
 }
 
+// newCompositeBaseStep creates the base of a step that only runs other
+// steps. Each child runs under its own deadline, so the step has none.
+func newCompositeBaseStep(name, description string) *BaseStep {
+	return NewBaseStep(name, description).WithTimeout(0)
+}
+
 func (s *BaseStep) Name() string {
 	return s.name
 }
@@ -120,11 +228,23 @@ func (s *BaseStep) Description() string {
 	return s.description
 }
 
+// WithTimeout sets the step's own deadline. Zero runs the step without a
+// deadline of its own, bounded only by its parent's.
 func (s *BaseStep) WithTimeout(timeout time.Duration) *BaseStep {
 	s.timeout = timeout
+	s.timeoutSet = true
 	return s
 }
 
+func (s *BaseStep) Timeout() time.Duration {
+	return s.timeout
+}
+
+// TimeoutSet reports whether the timeout was set with WithTimeout
+func (s *BaseStep) TimeoutSet() bool {
+	return s.timeoutSet
+}
+
 // ConditionalStep represents a step that executes based on a condition
 type ConditionalStep struct {
 	*BaseStep
@@ -135,7 +255,7 @@ type ConditionalStep struct {
 // NewConditionalStep creates a conditional step
 func NewConditionalStep(name string, condition func(interfaces.ExecutionContext) bool, step interfaces.Step) *ConditionalStep {
 	return &ConditionalStep{
-		BaseStep:  NewBaseStep(name, fmt.Sprintf("Conditional: %s", step.Description())),
+		BaseStep:  newCompositeBaseStep(name, fmt.Sprintf("Conditional: %s", step.Description())),
 		condition: condition,
 		step:      step,
 	}
@@ -146,37 +266,84 @@ func (s *ConditionalStep) Run(ctx interfaces.ExecutionContext) error {
 		ctx.Logger().Info("Condition met, executing step",
 			zap.String("step", s.Name()),
 			zap.String("conditional_step", s.step.Name()))
-		return s.step.Run(ctx)
+		return runStep(ctx, s.step)
 	}
 
 	ctx.Logger().Info("Condition not met, skipping step",
 		zap.String("step", s.Name()),
 		zap.String("conditional_step", s.step.Name()))
+	traceSkipped(ctx, s.step)
 	return nil
 }
 
-// ParallelStep executes multiple steps concurrently
+// ParallelStep executes multiple steps concurrently. Each branch runs on a
+// clone of the context; once all branches have finished, their writes are
+// merged back in declaration order according to the merge policy. The
+// failure policy decides whether a failing branch cancels its siblings and
+// whether the step fails.
 type ParallelStep struct {
 	*BaseStep
-	steps []interfaces.Step
+	steps         []interfaces.Step
+	mergePolicy   MergePolicy
+	mergeFunc     MergeFunc
+	failurePolicy FailurePolicy
+	quorum        int
+
+	maxConcurrency int
 }
 
 // NewParallelStep creates a parallel step
 func NewParallelStep(name string, steps ...interfaces.Step) *ParallelStep {
 	return &ParallelStep{
-		BaseStep: NewBaseStep(name, fmt.Sprintf("Parallel execution of %d steps", len(steps))),
-		steps:    steps,
+		BaseStep:      newCompositeBaseStep(name, fmt.Sprintf("Parallel execution of %d steps", len(steps))),
+		steps:         steps,
+		mergePolicy:   MergeWritten,
+		failurePolicy: FailWaitAll,
 	}
 }
 
+// WithMergePolicy sets how branch writes are merged into the parent context
+func (s *ParallelStep) WithMergePolicy(policy MergePolicy) *ParallelStep {
+	s.mergePolicy = policy
+	return s
+}
+
+// WithMergeFunc replaces the merge policy with a custom merge function
+func (s *ParallelStep) WithMergeFunc(fn MergeFunc) *ParallelStep {
+	s.mergeFunc = fn
+	return s
+}
+
+// WithFailurePolicy sets how branch failures are handled
+func (s *ParallelStep) WithFailurePolicy(policy FailurePolicy) *ParallelStep {
+	s.failurePolicy = policy
+	return s
+}
+
+// WithQuorum makes the step succeed once n branches have succeeded,
+// cancelling the rest. Run fails with INVALID_CONFIGURATION unless n is
+// between 1 and the number of branches.
+func (s *ParallelStep) WithQuorum(n int) *ParallelStep {
+	s.failurePolicy = FailQuorum
+	s.quorum = n
+	return s
+}
+
+// WithMaxConcurrency limits how many branches run at once; the others wait
+// for a free slot in declaration order. n <= 0 removes the limit.
+func (s *ParallelStep) WithMaxConcurrency(n int) *ParallelStep {
+	s.maxConcurrency = n
+	return s
+}
+
 func (s *ParallelStep) Run(ctx interfaces.ExecutionContext) error {
+	if err := s.validateQuorum(); err != nil {
+		return err
+	}
 	if len(s.steps) == 0 {
 		return nil
 	}
 
-	// Create error channel
-	errChan := make(chan error, len(s.steps))
-
 	// Check if context is already cancelled
 	select {
 	case <-ctx.Context().Done():
@@ -184,51 +351,50 @@ func (s *ParallelStep) Run(ctx interfaces.ExecutionContext) error {
 	default:
 	}
 
-	// Execute steps concurrently
-	for _, step := range s.steps {
-		go func(step interfaces.Step) {
-			// Clone context for each parallel step to avoid race conditions
-			clonedCtx := ctx.Clone()
-
-			clonedCtx.Logger().Info("Starting parallel step",
-				zap.String("parent_step", s.Name()),
-				zap.String("step", step.Name()))
-
-			// Check for cancellation before starting step
-			select {
-			case <-clonedCtx.Context().Done():
-				errChan <- clonedCtx.Context().Err()
-				return
-			default:
+	// Branches share a cancellable Go context so the failure policy can
+	// stop the siblings of a failed branch
+	branchCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+
+	results := make([]BranchResult, len(s.steps))
+	done := make(chan int, len(s.steps))
+	limiter := NewLimiter(s.Name(), s.maxConcurrency)
+
+	// Execute steps concurrently. Branches wait for a slot before their
+	// goroutine starts so they start in declaration order, and the
+	// dispatcher runs alongside the collector so the failure policy can
+	// cancel queued branches.
+	go func() {
+		for i, step := range s.steps {
+			results[i] = BranchResult{Name: step.Name(), Index: i}
+			if err := limiter.Acquire(branchCtx); err != nil {
+				results[i].Err = err
+				done <- i
+				continue
 			}
+			go s.runBranch(ctx, branchCtx, i, step, limiter, results, done)
+		}
+	}()
 
-			err := step.Run(clonedCtx)
-			if err != nil {
-				clonedCtx.Logger().Error("Parallel step failed",
-					zap.String("parent_step", s.Name()),
-					zap.String("step", step.Name()),
-					zap.Error(err))
-			} else {
-				// Merge successful results back to main context
-				// Use a mutex to avoid race conditions when writing to main context
-				for _, key := range clonedCtx.Keys() {
-					if val, ok := clonedCtx.Get(key); ok {
-						ctx.Set(key, val)
-					}
+	// Wait for all steps to complete or context cancellation. Cancelled
+	// branches are still awaited so none writes after the merge.
+	succeeded, failed, first := 0, 0, -1
+	for n := 0; n < len(s.steps); n++ {
+		select {
+		case i := <-done:
+			switch {
+			case results[i].Err == nil:
+				succeeded++
+			case isCancellation(branchCtx, results[i].Err):
+				results[i].Cancelled = true
+			default:
+				failed++
+				if first < 0 {
+					first = i
 				}
 			}
-
-			errChan <- err
-		}(step)
-	}
-
-	// Wait for all steps to complete or context cancellation
-	var firstError error
-	for i := 0; i < len(s.steps); i++ {
-		select {
-		case err := <-errChan:
-			if err != nil && firstError == nil {
-				firstError = err
+			if s.settled(succeeded, failed) {
+				cancel()
 			}
 		case <-ctx.Context().Done():
 			// Context was cancelled, return immediately
@@ -236,7 +402,49 @@ func (s *ParallelStep) Run(ctx interfaces.ExecutionContext) error {
 		}
 	}
 
-	return firstError
+	mergeErr := s.mergeBranches(ctx, results)
+	if err := s.outcome(ctx, results, first); err != nil {
+		return err
+	}
+	return mergeErr
+}
+
+// runBranch runs one branch on a clone of ctx carrying branchCtx and
+// reports its index on done
+func (s *ParallelStep) runBranch(ctx interfaces.ExecutionContext, branchCtx context.Context, i int, step interfaces.Step,
+	limiter *Limiter, results []BranchResult, done chan<- int) {
+	defer func() { done <- i }()
+	branchCtx, release := limiter.Hold(branchCtx)
+	defer release()
+
+	// Clone context for each parallel step to avoid race conditions, and
+	// record which keys the branch writes
+	writes := &branchWrites{}
+	clonedCtx := withGoContext(ctx.Clone(), branchCtx)
+	clonedCtx = withBranch(clonedCtx, fmt.Sprintf("%s[%d]", s.Name(), i))
+	clonedCtx = withBranchWrites(clonedCtx, writes)
+
+	clonedCtx.Logger().Info("Starting parallel step",
+		zap.String("parent_step", s.Name()),
+		zap.String("step", step.Name()))
+
+	// Check for cancellation before starting step
+	select {
+	case <-clonedCtx.Context().Done():
+		results[i].Err = clonedCtx.Context().Err()
+		return
+	default:
+	}
+
+	err := runStep(clonedCtx, step)
+	if err != nil {
+		clonedCtx.Logger().Error("Parallel step failed",
+			zap.String("parent_step", s.Name()),
+			zap.String("step", step.Name()),
+			zap.Error(err))
+	}
+	results[i].Keys, results[i].Values = writes.result(clonedCtx)
+	results[i].Err = err
 }
 
 // SequentialStep executes steps in sequence
@@ -248,7 +456,7 @@ type SequentialStep struct {
 // NewSequentialStep creates a sequential step
 func NewSequentialStep(name string, steps ...interfaces.Step) *SequentialStep {
 	return &SequentialStep{
-		BaseStep: NewBaseStep(name, fmt.Sprintf("Sequential execution of %d steps", len(steps))),
+		BaseStep: newCompositeBaseStep(name, fmt.Sprintf("Sequential execution of %d steps", len(steps))),
 		steps:    steps,
 	}
 }
@@ -259,7 +467,7 @@ func (s *SequentialStep) Run(ctx interfaces.ExecutionContext) error {
 			zap.String("parent_step", s.Name()),
 			zap.String("step", step.Name()))
 
-		if err := step.Run(ctx); err != nil {
+		if err := runStep(ctx, step); err != nil {
 			ctx.Logger().Error("Sequential step failed",
 				zap.String("parent_step", s.Name()),
 				zap.String("step", step.Name()),
@@ -301,7 +509,7 @@ type RetryStep struct {
 // NewRetryStep creates a retry step
 func NewRetryStep(name string, step interfaces.Step, maxRetries int, retryDelay time.Duration) *RetryStep {
 	return &RetryStep{
-		BaseStep:    NewBaseStep(name, fmt.Sprintf("Retry wrapper for: %s", step.Description())),
+		BaseStep:    newCompositeBaseStep(name, fmt.Sprintf("Retry wrapper for: %s", step.Description())),
 		step:        step,
 		maxRetries:  maxRetries,
 		retryDelay:  retryDelay,
@@ -318,6 +526,7 @@ func (s *RetryStep) Run(ctx interfaces.ExecutionContext) error {
 	var lastErr error
 
 	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		traceAttempts(ctx, attempt+1)
 		if attempt > 0 {
 			ctx.Logger().Info("Retrying step",
 				zap.String("step", s.Name()),
@@ -325,10 +534,12 @@ func (s *RetryStep) Run(ctx interfaces.ExecutionContext) error {
 				zap.Int("attempt", attempt),
 				zap.Int("max_retries", s.maxRetries))
 
-			time.Sleep(s.retryDelay)
+			if err := s.wait(ctx); err != nil {
+				return fmt.Errorf("step cancelled before retry %d: %w", attempt, err)
+			}
 		}
 
-		err := s.step.Run(ctx)
+		err := runStep(ctx, s.step)
 		if err == nil {
 			if attempt > 0 {
 				ctx.Logger().Info("Step succeeded after retry",
@@ -359,6 +570,20 @@ func (s *RetryStep) Run(ctx interfaces.ExecutionContext) error {
 	return fmt.Errorf("step failed after %d retries: %w", s.maxRetries, lastErr)
 }
 
+// wait sleeps for the retry delay, returning early with the context error
+// if ctx is cancelled first
+func (s *RetryStep) wait(ctx interfaces.ExecutionContext) error {
+	timer := time.NewTimer(s.retryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Context().Done():
+		return ctx.Context().Err()
+	}
+}
+
 // DelayStep introduces a delay in the flow
 type DelayStep struct {
 	*BaseStep
@@ -385,3 +610,166 @@ func (s *DelayStep) Run(ctx interfaces.ExecutionContext) error {
 		return ctx.Context().Err()
 	}
 }
+
+// TimeoutStep runs another step under its own deadline
+type TimeoutStep struct {
+	*BaseStep
+	step interfaces.Step
+}
+
+// NewTimeoutStep creates a timeout step
+func NewTimeoutStep(name string, step interfaces.Step, timeout time.Duration) *TimeoutStep {
+	s := &TimeoutStep{
+		BaseStep: NewBaseStep(name, fmt.Sprintf("Timeout wrapper for: %s", step.Description())),
+		step:     step,
+	}
+	s.WithTimeout(timeout)
+	return s
+}
+
+func (s *TimeoutStep) Run(ctx interfaces.ExecutionContext) error {
+	return runWithTimeout(ctx, s.step, s.timeout)
+}
+
+// timeoutProvider is implemented by steps that declare their own deadline,
+// such as those embedding BaseStep
+type timeoutProvider interface {
+	Timeout() time.Duration
+}
+
+// timeoutDefault is implemented by steps whose Timeout() is only a default
+// until it is set explicitly, such as those embedding BaseStep
+type timeoutDefault interface {
+	TimeoutSet() bool
+}
+
+// StepTimeout returns the deadline that applies to a step: its own Timeout()
+// when it declares one, otherwise the configured step execution timeout,
+// falling back to the step's default. A zero result means the step runs
+// without its own deadline.
+func StepTimeout(ctx interfaces.ExecutionContext, step interface{}) time.Duration {
+	for unwrapped := false; !unwrapped; {
+		switch wrapped := step.(type) {
+		case *namedStep:
+			step = wrapped.Step
+		case *compensableStep:
+			step = wrapped.Step
+		case *ContextStepAdapter:
+			step = wrapped.step
+		case *readOnlyStep:
+			step = wrapped.step
+		default:
+			unwrapped = true
+		}
+	}
+	tp, declares := step.(timeoutProvider)
+	if td, ok := step.(timeoutDefault); ok && !td.TimeoutSet() {
+		declares = false
+	}
+	if declares {
+		return tp.Timeout()
+	}
+	if cfg := contextConfig(ctx); cfg != nil {
+		return cfg.Timeouts.StepExecution
+	}
+	if tp != nil {
+		return tp.Timeout()
+	}
+	return 0
+}
+
+// RunWithTimeout runs a *Context based step under its own deadline. It is
+// the counterpart of the executor's timeout handling for step packages that
+// do not implement interfaces.Step.
+func RunWithTimeout(ctx *Context, name string, timeout time.Duration, run func(*Context) error) error {
+	return runWithDeadline(ctx, name, timeout, func(stepCtx interfaces.ExecutionContext) error {
+		return run(stepCtx.(*Context))
+	})
+}
+
+// RunStepWithTimeout runs a step with a child Go context bounded by timeout
+// and returns a timeout error naming the step if the deadline fires first.
+// A timeout of zero runs the step without its own deadline.
+func RunStepWithTimeout(ctx interfaces.ExecutionContext, step interfaces.Step, timeout time.Duration) error {
+	return tracedStep(ctx, step.Name(), func(ctx interfaces.ExecutionContext) error {
+		return runWithTimeout(ctx, step, timeout)
+	})
+}
+
+// runStep executes a step under the deadline returned by StepTimeout.
+// Deadlines nest: the child Go context derives from ctx, so a step never
+// outlives its parent's deadline either.
+func runStep(ctx interfaces.ExecutionContext, step interfaces.Step) error {
+	return tracedStep(ctx, step.Name(), func(ctx interfaces.ExecutionContext) error {
+		if _, ok := step.(*TimeoutStep); ok {
+			// Already enforces its own deadline
+			return step.Run(ctx)
+		}
+		return runWithTimeout(ctx, step, StepTimeout(ctx, step))
+	})
+}
+
+// runWithTimeout executes step with a child Go context bounded by timeout
+func runWithTimeout(ctx interfaces.ExecutionContext, step interfaces.Step, timeout time.Duration) error {
+	return runWithDeadline(ctx, step.Name(), timeout, step.Run)
+}
+
+// runWithDeadline executes run with a child Go context bounded by timeout.
+// The step sees the deadline through a view that shares ctx's data. If the
+// deadline fires first, a timeout error naming the step is returned without
+// waiting for steps that ignore cancellation; the view is detached first,
+// so the abandoned run can no longer change the context. Resources held
+// with Limiter.Hold are kept until the abandoned run exits.
+func runWithDeadline(ctx interfaces.ExecutionContext, name string, timeout time.Duration, run func(interfaces.ExecutionContext) error) error {
+	if timeout <= 0 {
+		return run(ctx)
+	}
+
+	parent := ctx.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	goCtx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	view, detached := detachedView(ctx, goCtx)
+	runs := stepRunsFrom(parent)
+	runs.start()
+	done := make(chan error, 1)
+	go func() {
+		defer runs.exit()
+		done <- run(view)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && goCtx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+			return stepTimeoutError(name, timeout, timeoutCause(err, goCtx.Err()))
+		}
+		return err
+	case <-goCtx.Done():
+		detached.detach()
+		if parent.Err() != nil {
+			return parent.Err()
+		}
+		return stepTimeoutError(name, timeout, goCtx.Err())
+	}
+}
+
+// timeoutCause returns the step error err so that it also matches the
+// context error ctxErr with errors.Is, whether or not the step wrapped it
+func timeoutCause(err, ctxErr error) error {
+	if stderrors.Is(err, ctxErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", err, ctxErr)
+}
+
+// stepTimeoutError builds the error reported when a step exceeds its deadline
+func stepTimeoutError(stepName string, timeout time.Duration, cause error) *errors.FrameworkError {
+	return errors.NewTimeoutError(errors.ErrCodeExecutionTimeout,
+		fmt.Sprintf("Step '%s' timed out after %s", stepName, timeout)).
+		WithContext("step", stepName).
+		WithContext("timeout", timeout.String()).
+		WithCause(cause)
+}
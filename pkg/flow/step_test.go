@@ -3,6 +3,7 @@ package flow
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -548,6 +549,29 @@ func TestRetryStep_MaxRetriesExceeded(t *testing.T) {
 	}
 }
 
+func TestRetryStep_DelayHonoursCancellation(t *testing.T) {
+	mockStep := &mockStep{
+		name: "retry_inner",
+		runFunc: func(ctx interfaces.ExecutionContext) error {
+			return errors.New("persistent error")
+		},
+	}
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	retryStep := NewRetryStep("retry_cancelled", mockStep, 3, 5*time.Second)
+
+	start := time.Now()
+	err := retryStep.Run(NewContext().WithLogger(zap.NewNop()).WithContext(goCtx))
+
+	if time.Since(start) > time.Second {
+		t.Fatal("The retry delay should end when the context is cancelled")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want the context error", err)
+	}
+}
+
 func TestRetryStep_WithRetryCondition(t *testing.T) {
 	attempts := 0
 	nonRetryableError := errors.New("non-retryable error")
@@ -622,7 +646,7 @@ func TestDelayStep_ContextCancellation(t *testing.T) {
 	ctx := &Context{
 		ctx:         baseCtx,
 		values:      make(map[string]interface{}),
-		executionID: generateExecutionID(),
+		executionID: generateExecutionID(nil),
 		startTime:   time.Now(),
 		timeout:     0, // No timeout, we'll cancel manually
 		config:      config.DefaultConfig(),
@@ -679,3 +703,181 @@ func TestNamedStep(t *testing.T) {
 		t.Errorf("Run() failed: %v", err)
 	}
 }
+
+func TestNewNamedStep(t *testing.T) {
+	inner := &mockStep{name: "inner"}
+
+	if step := NewNamedStep("inner", inner); step != interfaces.Step(inner) {
+		t.Error("NewNamedStep should return the step unchanged when the name matches")
+	}
+
+	if step := NewNamedStep("renamed", inner); step.Name() != "renamed" {
+		t.Errorf("Name() = %v, want 'renamed'", step.Name())
+	}
+}
+
+func TestTimeoutStep(t *testing.T) {
+	fast := &mockStep{name: "fast", runFunc: func(ctx interfaces.ExecutionContext) error {
+		ctx.Set("done", true)
+		return nil
+	}}
+
+	ctx := NewContext().WithLogger(zap.NewNop())
+	if err := NewTimeoutStep("fast", fast, time.Second).Run(ctx); err != nil {
+		t.Errorf("Run() failed: %v", err)
+	}
+	if !ctx.Has("done") {
+		t.Error("Inner step should have run")
+	}
+}
+
+func TestTimeoutStep_Exceeded(t *testing.T) {
+	slow := &mockStep{name: "slow", runFunc: func(ctx interfaces.ExecutionContext) error {
+		<-ctx.Context().Done()
+		return ctx.Context().Err()
+	}}
+
+	ctx := NewContext().WithLogger(zap.NewNop())
+	start := time.Now()
+	err := NewTimeoutStep("slow", slow, 30*time.Millisecond).Run(ctx)
+
+	if err == nil {
+		t.Fatal("Run() should have timed out")
+	}
+	if !strings.Contains(err.Error(), "Step 'slow' timed out") {
+		t.Errorf("Error = %v, want step timeout error", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Timeout was not enforced")
+	}
+	if ctx.Context().Err() != nil {
+		t.Error("Parent context should not be cancelled by a step timeout")
+	}
+}
+
+func TestTimeoutStep_ErrorWrapsDeadline(t *testing.T) {
+	opaque := &mockStep{name: "opaque", runFunc: func(ctx interfaces.ExecutionContext) error {
+		<-ctx.Context().Done()
+		return fmt.Errorf("upstream call failed: %v", ctx.Context().Err())
+	}}
+
+	err := NewTimeoutStep("opaque", opaque, 20*time.Millisecond).Run(NewContext().WithLogger(zap.NewNop()))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Error = %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "Step 'opaque' timed out") {
+		t.Errorf("Error = %v, want step timeout error", err)
+	}
+}
+
+func TestTimeoutStep_AbandonedRunCannotWrite(t *testing.T) {
+	release := make(chan struct{})
+	exited := make(chan struct{})
+	stubborn := &mockStep{name: "stubborn", runFunc: func(ctx interfaces.ExecutionContext) error {
+		defer close(exited)
+		ctx.Set("early", true)
+		<-release // ignores cancellation
+		ctx.Set("late", true)
+		ctx.Delete("early")
+		return nil
+	}}
+
+	ctx := NewContext().WithLogger(zap.NewNop())
+	if err := NewTimeoutStep("stubborn", stubborn, 20*time.Millisecond).Run(ctx); err == nil {
+		t.Fatal("Run() should have timed out")
+	}
+	close(release)
+	<-exited
+
+	if !ctx.Has("early") {
+		t.Error("Writes made before the deadline should be kept")
+	}
+	if ctx.Has("late") {
+		t.Error("Writes made after the step was abandoned should be dropped")
+	}
+}
+
+// legacyStep is written against *Context rather than interfaces.ExecutionContext
+type legacyStep struct {
+	name    string
+	timeout time.Duration
+	run     func(ctx *Context) error
+}
+
+func (s *legacyStep) Run(ctx *Context) error { return s.run(ctx) }
+func (s *legacyStep) Name() string           { return s.name }
+func (s *legacyStep) Description() string    { return "legacy step" }
+func (s *legacyStep) Timeout() time.Duration { return s.timeout }
+
+// foreignContext hides the concrete *Context type so the adapter has to copy
+type foreignContext struct {
+	interfaces.ExecutionContext
+}
+
+func TestContextStepAdapter_Context(t *testing.T) {
+	var seen *Context
+	step := AdaptContextStep(&legacyStep{name: "legacy", run: func(ctx *Context) error {
+		seen = ctx
+		ctx.Set("written", true)
+		return nil
+	}})
+
+	if step.Name() != "legacy" || step.Description() != "legacy step" {
+		t.Errorf("Name/Description = %v/%v, want legacy/legacy step", step.Name(), step.Description())
+	}
+
+	ctx := NewContext()
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if seen != ctx {
+		t.Error("A *Context should be passed through unchanged")
+	}
+	if !ctx.Has("written") {
+		t.Error("Write should be visible in the context")
+	}
+}
+
+func TestContextStepAdapter_ForeignContext(t *testing.T) {
+	step := AdaptContextStep(&legacyStep{name: "legacy", run: func(ctx *Context) error {
+		if v, _ := ctx.Get("input"); v != "in" {
+			t.Errorf("input = %v, want 'in'", v)
+		}
+		ctx.Set("output", "out")
+		ctx.Delete("stale")
+		return errors.New("boom")
+	}})
+
+	inner := NewContext().WithFlowName("foreign")
+	inner.Set("input", "in")
+	inner.Set("stale", true)
+
+	err := step.Run(&foreignContext{inner})
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("Run() error = %v, want boom", err)
+	}
+	if v, _ := inner.Get("output"); v != "out" {
+		t.Errorf("output = %v, want 'out'", v)
+	}
+	if inner.Has("stale") {
+		t.Error("Delete should be applied back to the original context")
+	}
+}
+
+func TestContextStepAdapter_InFlow(t *testing.T) {
+	legacy := &legacyStep{name: "legacy", timeout: 20 * time.Millisecond, run: func(ctx *Context) error {
+		<-ctx.Context().Done()
+		return ctx.Context().Err()
+	}}
+
+	if got := StepTimeout(NewContext(), AdaptContextStep(legacy)); got != legacy.timeout {
+		t.Errorf("StepTimeout() = %v, want %v", got, legacy.timeout)
+	}
+
+	flow := NewFlow("adapter").Step("legacy", AdaptContextStep(legacy))
+	_, err := flow.Execute(NewContext().WithLogger(zap.NewNop()))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Execute() error = %v, want the adapted step's timeout", err)
+	}
+}
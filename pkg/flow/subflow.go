@@ -0,0 +1,104 @@
+package flow
+
+import (
+	"fmt"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// SubFlowError reports the failure of a flow run as a step of another flow.
+// Result is the child's execution result, with its failed step, timeout
+// and compensation details.
+type SubFlowError struct {
+	Step   string
+	Flow   string
+	Result *ExecutionResult
+	Err    error
+}
+
+func (e *SubFlowError) Error() string {
+	return fmt.Sprintf("sub-flow '%s' failed: %v", e.Flow, e.Err)
+}
+
+func (e *SubFlowError) Unwrap() error {
+	return e.Err
+}
+
+// SubFlowStep runs another flow as a step. The child runs on a clone of the
+// context holding only the mapped inputs, and only the mapped outputs are
+// copied back. Its steps are nested under the SubFlow step in the parent's
+// trace.
+type SubFlowStep struct {
+	*BaseStep
+	flow    *Flow
+	inputs  map[string]string
+	outputs map[string]string
+}
+
+// SubFlow creates a step running child, named after the child flow
+func SubFlow(child *Flow) *SubFlowStep {
+	return NewSubFlowStep(child.Name(), child)
+}
+
+// NewSubFlowStep creates a step running child. The child flow enforces its
+// own timeout, so the step has none.
+func NewSubFlowStep(name string, child *Flow) *SubFlowStep {
+	base := NewBaseStep(name, fmt.Sprintf("Sub-flow %s", child.Name()))
+	base.WithTimeout(0)
+	return &SubFlowStep{
+		BaseStep: base,
+		flow:     child,
+	}
+}
+
+// WithInputs maps parent values into the child context, from a dot-notation
+// path in the parent to a key in the child. Missing parent values are not
+// set.
+func (s *SubFlowStep) WithInputs(inputs map[string]string) *SubFlowStep {
+	s.inputs = inputs
+	return s
+}
+
+// WithOutputs maps child values back into the parent context once the child
+// succeeds, from a dot-notation path in the child to a key in the parent.
+// Missing child values are not set.
+func (s *SubFlowStep) WithOutputs(outputs map[string]string) *SubFlowStep {
+	s.outputs = outputs
+	return s
+}
+
+func (s *SubFlowStep) Run(ctx interfaces.ExecutionContext) error {
+	// Clearing the clone is not a change worth recording
+	child := ctx.Clone()
+	history := setHistory(child, nil)
+	for _, key := range child.Keys() {
+		child.Delete(key)
+	}
+	setHistory(child, history)
+	if c, ok := child.(*Context); ok {
+		c.WithFlowName(s.flow.Name())
+	}
+
+	// The inputs and the child's own writes are not writes of this step or
+	// of an enclosing parallel branch
+	child = withBranchWrites(untraced(child), nil)
+	for from, to := range s.inputs {
+		if value, ok := values.Lookup(from, ctx); ok {
+			child.Set(to, value)
+		}
+	}
+
+	result, err := s.flow.Execute(child)
+	traceSubFlow(ctx, result)
+	if err != nil {
+		return &SubFlowError{Step: s.Name(), Flow: s.flow.Name(), Result: result, Err: err}
+	}
+
+	for from, to := range s.outputs {
+		if value, ok := values.Lookup(from, child); ok {
+			ctx.Set(to, value)
+		}
+	}
+	return nil
+}
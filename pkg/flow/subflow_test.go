@@ -0,0 +1,146 @@
+package flow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// profileFlow reads the "user" input and writes a profile and a scratch key
+func profileFlow() *Flow {
+	return NewFlow("profile").
+		StepFunc("load", func(ctx interfaces.ExecutionContext) error {
+			if ctx.Has("secret") {
+				return errors.New("parent data leaked into the sub-flow")
+			}
+			user, _ := ctx.Get("user")
+			ctx.Set("scratch", true)
+			ctx.Set("profile", map[string]interface{}{"name": user, "tier": "gold"})
+			return nil
+		})
+}
+
+func TestSubFlow_MapsInputsAndOutputs(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("auth", map[string]interface{}{"user_id": "u1"})
+	ctx.Set("secret", "s3cr3t")
+
+	result, err := NewFlow("handler").
+		Step("profile", SubFlow(profileFlow()).
+			WithInputs(map[string]string{"auth.user_id": "user"}).
+			WithOutputs(map[string]string{"profile.tier": "tier", "profile": "user_profile"})).
+		Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if tier, _ := ctx.Get("tier"); tier != "gold" {
+		t.Errorf("tier = %v, want gold", tier)
+	}
+	profile, _ := ctx.Get("user_profile")
+	if profile.(map[string]interface{})["name"] != "u1" {
+		t.Errorf("user_profile = %v, want the mapped user", profile)
+	}
+	if ctx.Has("scratch") || ctx.Has("user") {
+		t.Error("Unmapped child keys should not reach the parent")
+	}
+
+	// Only the mapped outputs are writes of the SubFlow step
+	entry := findStep(result.Steps, "profile")[0]
+	if strings.Join(entry.KeysWritten, ",") != "tier,user_profile" {
+		t.Errorf("KeysWritten = %v, want the mapped outputs", entry.KeysWritten)
+	}
+}
+
+func TestSubFlow_NestsTrace(t *testing.T) {
+	child := NewFlow("child").
+		Step("a", setter("a", "a")).
+		Parallel("fanout").
+		Step("b", setter("b", "b")).
+		EndParallel()
+
+	result, err := NewFlow("parent").
+		Step("before", setter("before", "x")).
+		Step("sub", NewSubFlowStep("sub", child)).
+		Step("after", setter("after", "y")).
+		Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var names []string
+	for _, step := range result.Steps {
+		names = append(names, step.Name)
+	}
+	if got := strings.Join(names, ","); got != "before,sub,a,fanout,b,after" {
+		t.Errorf("Trace order = %s", got)
+	}
+
+	sub := findStep(result.Steps, "sub")[0]
+	if sub.SubFlow != "child" || sub.Depth != 0 {
+		t.Errorf("SubFlow entry = %+v", sub)
+	}
+	a := findStep(result.Steps, "a")[0]
+	if a.Parent != "sub" || a.Depth != 1 {
+		t.Errorf("Child step = %+v, want nested under sub", a)
+	}
+	b := findStep(result.Steps, "b")[0]
+	if b.Parent != "fanout" || b.Depth != 2 {
+		t.Errorf("Nested child step = %+v, want depth 2 under fanout", b)
+	}
+}
+
+func TestSubFlow_Failure(t *testing.T) {
+	boom := errors.New("boom")
+	child := NewFlow("child").
+		Step("ok", setter("ok", "ok")).
+		Step("broken", failing("broken", 0, boom))
+
+	result, err := NewFlow("parent").
+		Step("sub", NewSubFlowStep("sub", child).WithOutputs(map[string]string{"ok": "ok"})).
+		Execute(newTestContext())
+
+	if result.FailedStep != "sub" {
+		t.Errorf("FailedStep = %q, want sub", result.FailedStep)
+	}
+	var subErr *SubFlowError
+	if !errors.As(err, &subErr) {
+		t.Fatalf("Execute() error = %v, want a SubFlowError", err)
+	}
+	if subErr.Flow != "child" || subErr.Result.FailedStep != "broken" {
+		t.Errorf("SubFlowError = %+v, want the child's failed step", subErr)
+	}
+	if !errors.Is(err, boom) {
+		t.Error("The error should unwrap to the child step error")
+	}
+	if result.Context.Has("ok") {
+		t.Error("Outputs should not be mapped when the child fails")
+	}
+	if broken := findStep(result.Steps, "broken"); len(broken) != 1 || broken[0].Outcome != StepFailed {
+		t.Errorf("Child failure = %+v, want it in the parent trace", broken)
+	}
+}
+
+func TestSubFlow_InsideParallelBranch(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("auth", map[string]interface{}{"user_id": "u1"})
+
+	step := NewParallelStep("fanout",
+		SubFlow(profileFlow()).
+			WithInputs(map[string]string{"auth.user_id": "user"}).
+			WithOutputs(map[string]string{"profile": "profile"}),
+		writer("other", 0, map[string]interface{}{"other": true}),
+	).WithMergePolicy(MergeFailOnConflict)
+	if err := step.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !ctx.Has("profile") || !ctx.Has("other") {
+		t.Error("Branch outputs should be merged")
+	}
+	if ctx.Has("scratch") || ctx.Has("user") {
+		t.Error("Child writes should not be merged as branch writes")
+	}
+}
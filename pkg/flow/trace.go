@@ -0,0 +1,410 @@
+package flow
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// Step outcomes recorded in StepResult
+const (
+	StepSucceeded  = "success"
+	StepFailed     = "failed"
+	StepSkipped    = "skipped"
+	StepIncomplete = "incomplete" // still running when the flow returned
+)
+
+// StepResult records one step execution in a flow's trace. Nested steps
+// (inside parallel, sequential, retry, conditional and choice steps) get
+// their own entries, ordered by start time.
+type StepResult struct {
+	Name      string
+	Parent    string // enclosing step, empty at the top level
+	Depth     int
+	StartTime time.Time
+	Duration  time.Duration
+	Outcome   string
+	Error     error
+
+	// Attempts is the number of attempts made by a RetryStep, 1 otherwise
+	Attempts int
+
+	// Skipped is set for steps in a choice branch or conditional step that
+	// was not taken
+	Skipped bool
+
+	// Branch identifies the parallel branch the step ran in, e.g.
+	// "fanout[1]", and BranchID is the ID logged by that branch; both are
+	// empty outside parallel steps
+	Branch   string
+	BranchID string
+
+	// KeysWritten lists the context keys the step (or its children) set
+	KeysWritten []string
+
+	// SubFlow names the flow run by a SubFlow step; the child's steps follow
+	// as entries nested under it
+	SubFlow string
+}
+
+// executionTrace collects the StepResults of one execution
+type executionTrace struct {
+	mu     sync.Mutex
+	steps  []StepResult
+	frames []*traceFrame
+	writes *writeCheck // nil when the flow disabled the write check
+}
+
+// traceFrame tracks a running step; writes are attributed to the frame and
+// all of its ancestors
+type traceFrame struct {
+	trace    *executionTrace
+	index    int
+	parent   *traceFrame
+	keys     map[string]struct{}
+	attempts int
+	done     bool
+
+	// isolated frames belong to scopes; writes of their children are not
+	// attributed to them or their ancestors
+	isolated   bool
+	overwrites []Overwrite
+}
+
+type traceKey struct{}
+type traceFrameKey struct{}
+type traceBranchKey struct{}
+
+func newExecutionTrace() *executionTrace {
+	return &executionTrace{}
+}
+
+// results returns a snapshot of the trace. Steps still running are
+// reported as incomplete with their duration so far.
+func (t *executionTrace) results() []StepResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	results := make([]StepResult, len(t.steps))
+	copy(results, t.steps)
+	for i, frame := range t.frames {
+		if frame != nil && !frame.done {
+			results[i].Outcome = StepIncomplete
+			results[i].Duration = time.Since(results[i].StartTime)
+			results[i].KeysWritten = sortedKeys(frame.keys)
+		}
+	}
+	// Sub-flow steps are added once the child completes
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].StartTime.Before(results[j].StartTime)
+	})
+	return results
+}
+
+// traceFromContext returns the trace and current frame carried by ctx
+func traceFromContext(ctx interfaces.ExecutionContext) (*executionTrace, *traceFrame) {
+	goCtx := ctx.Context()
+	if goCtx == nil {
+		return nil, nil
+	}
+	trace, _ := goCtx.Value(traceKey{}).(*executionTrace)
+	frame, _ := goCtx.Value(traceFrameKey{}).(*traceFrame)
+	return trace, frame
+}
+
+// tracedStep runs fn as a traced step named name. Without a trace on ctx
+// it simply calls fn. The step also gets an OpenTelemetry span when its
+// parent span is recording.
+func tracedStep(ctx interfaces.ExecutionContext, name string, fn func(interfaces.ExecutionContext) error) error {
+	ctx, span := startStepSpan(ctx, name)
+	trace, parent := traceFromContext(ctx)
+	if trace == nil {
+		err := fn(ctx)
+		endStepSpan(span, err)
+		return err
+	}
+
+	frame := trace.start(ctx, name, parent, false)
+	err := fn(withGoContext(ctx, context.WithValue(ctx.Context(), traceFrameKey{}, frame)))
+	if err == nil {
+		err = trace.overwriteFailure(name, frame)
+	}
+	trace.finish(frame, err)
+	endStepSpan(span, err)
+	return err
+}
+
+// traceSkipped records steps that were not run because their branch was
+// not taken
+func traceSkipped(ctx interfaces.ExecutionContext, steps ...interfaces.Step) {
+	trace, parent := traceFromContext(ctx)
+	if trace == nil {
+		return
+	}
+	for _, step := range steps {
+		trace.start(ctx, step.Name(), parent, true)
+	}
+}
+
+// traceAttempts records the number of attempts made by the current step
+func traceAttempts(ctx interfaces.ExecutionContext, attempts int) {
+	trace, frame := traceFromContext(ctx)
+	if trace == nil || frame == nil {
+		return
+	}
+	trace.mu.Lock()
+	frame.attempts = attempts
+	trace.mu.Unlock()
+}
+
+// traceSubFlow nests the trace of a child flow under the current step
+func traceSubFlow(ctx interfaces.ExecutionContext, result *ExecutionResult) {
+	trace, frame := traceFromContext(ctx)
+	if trace == nil || frame == nil || result == nil {
+		return
+	}
+
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+
+	trace.steps[frame.index].SubFlow = result.FlowName
+	parent := trace.steps[frame.index]
+	for _, step := range result.Steps {
+		if step.Parent == "" {
+			step.Parent = parent.Name
+		}
+		step.Depth += parent.Depth + 1
+		trace.steps = append(trace.steps, step)
+		trace.frames = append(trace.frames, nil)
+	}
+}
+
+// withBranch marks ctx, a clone, as running in the given parallel branch
+// and gives it its own branch ID
+func withBranch(ctx interfaces.ExecutionContext, branch string) interfaces.ExecutionContext {
+	if c, ok := ctx.(*Context); ok {
+		c.startBranch(branch)
+	}
+	goCtx := ctx.Context()
+	if goCtx == nil {
+		return ctx
+	}
+	return withGoContext(ctx, context.WithValue(goCtx, traceBranchKey{}, branch))
+}
+
+// contextBranchID returns the branch ID of ctx, if it has one
+func contextBranchID(ctx interfaces.ExecutionContext) string {
+	switch c := ctx.(type) {
+	case *Context:
+		return c.branchID
+	case *contextView:
+		return contextBranchID(c.ExecutionContext)
+	default:
+		return ""
+	}
+}
+
+// untraced returns a view of ctx whose writes are not attributed to any
+// step, used when a composite step copies its children's results back
+func untraced(ctx interfaces.ExecutionContext) interfaces.ExecutionContext {
+	goCtx := ctx.Context()
+	if goCtx == nil || goCtx.Value(traceKey{}) == nil {
+		return ctx
+	}
+	return withGoContext(ctx, context.WithValue(goCtx, traceFrameKey{}, (*traceFrame)(nil)))
+}
+
+// isolateWrites stops the writes of the current step's children from being
+// attributed to it, for steps whose children write into a scope
+func isolateWrites(ctx interfaces.ExecutionContext) {
+	trace, frame := traceFromContext(ctx)
+	if trace == nil || frame == nil {
+		return
+	}
+	trace.mu.Lock()
+	frame.isolated = true
+	trace.mu.Unlock()
+}
+
+// recordWrite attributes a context write to the step and parallel branch
+// running on goCtx, and checks it against earlier writes of other steps
+func recordWrite(goCtx context.Context, key string) {
+	if goCtx == nil {
+		return
+	}
+	recordBranchWrite(goCtx, key)
+	frame, _ := goCtx.Value(traceFrameKey{}).(*traceFrame)
+	if frame == nil {
+		return
+	}
+
+	t := frame.trace
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	scoped := false
+	for f := frame; f != nil; f = f.parent {
+		f.keys[key] = struct{}{}
+		if f.parent != nil && f.parent.isolated {
+			scoped = true
+			break
+		}
+	}
+
+	_, inBranch := goCtx.Value(traceBranchKey{}).(string)
+	if t.writes == nil || scoped || inBranch {
+		return
+	}
+	if overwrite, ok := t.writes.record(t.steps[frame.index].Name, key); ok {
+		frame.overwrites = append(frame.overwrites, overwrite)
+	}
+}
+
+// tracePath returns the names of the step running on goCtx and the steps
+// enclosing it, outermost first
+func tracePath(goCtx context.Context) []string {
+	frame, _ := goCtx.Value(traceFrameKey{}).(*traceFrame)
+	if frame == nil {
+		return nil
+	}
+
+	t := frame.trace
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var path []string
+	for f := frame; f != nil; f = f.parent {
+		path = append([]string{t.steps[f.index].Name}, path...)
+	}
+	return path
+}
+
+// overwriteFailure returns the error failing a step that overwrote another
+// step's keys, when the flow's write check fails steps
+func (t *executionTrace) overwriteFailure(name string, frame *traceFrame) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.writes == nil || t.writes.mode != WriteCheckFail || len(frame.overwrites) == 0 {
+		return nil
+	}
+	return overwriteError(name, frame.overwrites)
+}
+
+// overwrites returns the overwrites found by the write check
+func (t *executionTrace) overwrites() []Overwrite {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.writes == nil || len(t.writes.overwrites) == 0 {
+		return nil
+	}
+	return append([]Overwrite(nil), t.writes.overwrites...)
+}
+
+func (t *executionTrace) start(ctx interfaces.ExecutionContext, name string, parent *traceFrame, skipped bool) *traceFrame {
+	branch, _ := ctx.Context().Value(traceBranchKey{}).(string)
+	branchID := contextBranchID(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := StepResult{
+		Name:      name,
+		StartTime: time.Now(),
+		Attempts:  1,
+		Branch:    branch,
+		BranchID:  branchID,
+	}
+	if parent != nil {
+		result.Parent = t.steps[parent.index].Name
+		result.Depth = t.steps[parent.index].Depth + 1
+	}
+	if skipped {
+		result.Outcome = StepSkipped
+		result.Skipped = true
+		result.Attempts = 0
+		t.steps = append(t.steps, result)
+		t.frames = append(t.frames, nil)
+		return nil
+	}
+
+	frame := &traceFrame{
+		trace:  t,
+		index:  len(t.steps),
+		parent: parent,
+		keys:   make(map[string]struct{}),
+	}
+	t.steps = append(t.steps, result)
+	t.frames = append(t.frames, frame)
+	return frame
+}
+
+func (t *executionTrace) finish(frame *traceFrame, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := &t.steps[frame.index]
+	result.Duration = time.Since(result.StartTime)
+	result.Outcome = StepSucceeded
+	if err != nil {
+		result.Outcome = StepFailed
+		result.Error = err
+	}
+	if frame.attempts > 0 {
+		result.Attempts = frame.attempts
+	}
+	result.KeysWritten = sortedKeys(frame.keys)
+	frame.done = true
+}
+
+func sortedKeys(keys map[string]struct{}) []string {
+	if len(keys) == 0 {
+		return nil
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// stepResultsResponse renders a trace for GetResponse
+func stepResultsResponse(steps []StepResult) []map[string]interface{} {
+	entries := make([]map[string]interface{}, 0, len(steps))
+	for _, step := range steps {
+		entry := map[string]interface{}{
+			"name":        step.Name,
+			"start":       step.StartTime,
+			"duration_ms": float64(step.Duration.Microseconds()) / 1000,
+			"outcome":     step.Outcome,
+			"attempts":    step.Attempts,
+			"depth":       step.Depth,
+		}
+		if step.Parent != "" {
+			entry["parent"] = step.Parent
+		}
+		if step.Error != nil {
+			entry["error"] = step.Error.Error()
+		}
+		if step.Skipped {
+			entry["skipped"] = true
+		}
+		if step.Branch != "" {
+			entry["branch"] = step.Branch
+		}
+		if step.BranchID != "" {
+			entry["branch_id"] = step.BranchID
+		}
+		if len(step.KeysWritten) > 0 {
+			entry["keys_written"] = step.KeysWritten
+		}
+		if step.SubFlow != "" {
+			entry["sub_flow"] = step.SubFlow
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,228 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// findStep returns the trace entries for a step name
+func findStep(steps []StepResult, name string) []StepResult {
+	var found []StepResult
+	for _, step := range steps {
+		if step.Name == name {
+			found = append(found, step)
+		}
+	}
+	return found
+}
+
+// setter returns a step that sets key
+func setter(name, key string) interfaces.Step {
+	return &mockStep{name: name, runFunc: func(ctx interfaces.ExecutionContext) error {
+		ctx.Set(key, name)
+		return nil
+	}}
+}
+
+func TestFlow_Trace_TopLevel(t *testing.T) {
+	flow := NewFlow("trace").
+		Step("first", setter("first", "a")).
+		StepFunc("second", func(ctx interfaces.ExecutionContext) error {
+			time.Sleep(10 * time.Millisecond)
+			ctx.Set("b", 1)
+			ctx.Set("c", 2)
+			return errors.New("boom")
+		})
+
+	result, _ := flow.Execute(newTestContext())
+
+	if len(result.Steps) != 2 {
+		t.Fatalf("Steps = %+v, want 2 entries", result.Steps)
+	}
+	first, second := result.Steps[0], result.Steps[1]
+	if first.Name != "first" || first.Outcome != StepSucceeded || first.Attempts != 1 || first.Depth != 0 {
+		t.Errorf("First = %+v", first)
+	}
+	if strings.Join(first.KeysWritten, ",") != "a" {
+		t.Errorf("First keys = %v, want [a]", first.KeysWritten)
+	}
+	if second.Outcome != StepFailed || second.Error == nil || second.Error.Error() != "boom" {
+		t.Errorf("Second outcome/error = %v/%v, want failed/boom", second.Outcome, second.Error)
+	}
+	if second.Duration < 10*time.Millisecond {
+		t.Errorf("Second duration = %v, want >= 10ms", second.Duration)
+	}
+	if strings.Join(second.KeysWritten, ",") != "b,c" {
+		t.Errorf("Second keys = %v, want [b c]", second.KeysWritten)
+	}
+	if second.StartTime.Before(first.StartTime) {
+		t.Error("Steps should be ordered by start time")
+	}
+}
+
+func TestFlow_Trace_Parallel(t *testing.T) {
+	flow := NewFlow("trace").
+		Parallel("fanout").
+		Step("profile", setter("profile", "profile")).
+		Step("orders", setter("orders", "orders")).
+		EndParallel()
+
+	result, err := flow.Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	fanout := findStep(result.Steps, "fanout")
+	if len(fanout) != 1 || strings.Join(fanout[0].KeysWritten, ",") != "orders,profile" {
+		t.Errorf("Fanout = %+v, want keys of both branches", fanout)
+	}
+
+	branches := map[string]bool{}
+	for _, name := range []string{"profile", "orders"} {
+		entries := findStep(result.Steps, name)
+		if len(entries) != 1 {
+			t.Fatalf("%s entries = %d, want 1", name, len(entries))
+		}
+		entry := entries[0]
+		if entry.Parent != "fanout" || entry.Depth != 1 || !strings.HasPrefix(entry.Branch, "fanout[") {
+			t.Errorf("%s = %+v, want nested in a fanout branch", name, entry)
+		}
+		if strings.Join(entry.KeysWritten, ",") != name {
+			t.Errorf("%s keys = %v, want only its own write", name, entry.KeysWritten)
+		}
+		branches[entry.Branch] = true
+	}
+	if len(branches) != 2 {
+		t.Errorf("Branch IDs = %v, want distinct IDs", branches)
+	}
+}
+
+func TestFlow_Trace_Retry(t *testing.T) {
+	attempts := 0
+	flaky := &mockStep{name: "flaky", runFunc: func(interfaces.ExecutionContext) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	}}
+	flow := NewFlow("trace").Step("flaky", flaky).Retry(3, time.Millisecond)
+
+	result, err := flow.Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	retry := findStep(result.Steps, "retry_flaky")
+	if len(retry) != 1 || retry[0].Attempts != 3 || retry[0].Outcome != StepSucceeded {
+		t.Errorf("Retry = %+v, want 3 attempts and success", retry)
+	}
+	inner := findStep(result.Steps, "flaky")
+	if len(inner) != 3 || inner[0].Outcome != StepFailed || inner[2].Outcome != StepSucceeded {
+		t.Errorf("Inner attempts = %+v, want two failures then success", inner)
+	}
+	if inner[0].Parent != "retry_flaky" {
+		t.Errorf("Inner parent = %v, want retry_flaky", inner[0].Parent)
+	}
+}
+
+func TestFlow_Trace_ChoiceSkipped(t *testing.T) {
+	flow := NewFlow("trace").
+		Choice("tier").
+		When(func(interfaces.ExecutionContext) bool { return false }).
+		Step("gold", setter("gold", "gold")).
+		When(func(interfaces.ExecutionContext) bool { return true }).
+		Step("silver", setter("silver", "silver")).
+		Otherwise().
+		Step("basic", setter("basic", "basic")).
+		EndChoice()
+
+	result, err := flow.Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	gold := findStep(result.Steps, "gold")
+	if len(gold) != 1 || !gold[0].Skipped || gold[0].Outcome != StepSkipped || gold[0].Parent != "tier" {
+		t.Errorf("Gold = %+v, want skipped inside tier", gold)
+	}
+	silver := findStep(result.Steps, "silver")
+	if len(silver) != 1 || silver[0].Skipped || silver[0].Outcome != StepSucceeded {
+		t.Errorf("Silver = %+v, want run", silver)
+	}
+	otherwise := findStep(result.Steps, "otherwise")
+	if len(otherwise) != 1 || !otherwise[0].Skipped {
+		t.Errorf("Otherwise = %+v, want skipped", otherwise)
+	}
+}
+
+func TestFlow_Trace_SequentialAndConditional(t *testing.T) {
+	flow := NewFlow("trace").
+		Step("group", NewSequentialStep("group",
+			setter("inner", "inner"),
+			NewConditionalStep("maybe", func(interfaces.ExecutionContext) bool { return false }, setter("never", "never")),
+		))
+
+	result, err := flow.Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	inner := findStep(result.Steps, "inner")
+	if len(inner) != 1 || inner[0].Parent != "group" || inner[0].Depth != 1 {
+		t.Errorf("Inner = %+v, want nested in group", inner)
+	}
+	never := findStep(result.Steps, "never")
+	if len(never) != 1 || !never[0].Skipped || never[0].Parent != "maybe" || never[0].Depth != 2 {
+		t.Errorf("Never = %+v, want skipped inside maybe", never)
+	}
+	group := findStep(result.Steps, "group")
+	if strings.Join(group[0].KeysWritten, ",") != "inner" {
+		t.Errorf("Group keys = %v, want the keys of its children", group[0].KeysWritten)
+	}
+}
+
+func TestFlow_Trace_Timeout(t *testing.T) {
+	flow := NewFlow("trace").
+		WithTimeout(20*time.Millisecond).
+		StepFunc("slow", func(interfaces.ExecutionContext) error {
+			time.Sleep(200 * time.Millisecond) // ignores cancellation
+			return nil
+		})
+
+	result, _ := flow.Execute(newTestContext())
+
+	// The step is abandoned when the deadline fires
+	slow := findStep(result.Steps, "slow")
+	if len(slow) != 1 || slow[0].Outcome != StepFailed || !errors.Is(slow[0].Error, context.DeadlineExceeded) {
+		t.Errorf("Slow = %+v, want failed with the flow deadline", slow)
+	}
+}
+
+func TestExecutionResult_GetResponse_Debug(t *testing.T) {
+	flow := NewFlow("trace").Step("first", setter("first", "a"))
+
+	result, _ := flow.Execute(newTestContext())
+	if _, ok := result.GetResponse()["steps"]; ok {
+		t.Error("Steps should only be included in debug mode")
+	}
+
+	result.Debug = true
+	steps, ok := result.GetResponse()["steps"].([]map[string]interface{})
+	if !ok || len(steps) != 1 {
+		t.Fatalf("Response steps = %v", result.GetResponse()["steps"])
+	}
+	if steps[0]["name"] != "first" || steps[0]["outcome"] != StepSucceeded {
+		t.Errorf("Step entry = %v", steps[0])
+	}
+
+	debugResult, _ := NewFlow("trace").WithDebug(true).Step("first", setter("first", "a")).Execute(newTestContext())
+	if _, ok := debugResult.GetResponse()["steps"]; !ok {
+		t.Error("WithDebug should include steps in the response")
+	}
+}
@@ -0,0 +1,111 @@
+package flow
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// tracerName identifies the spans created by the flow executor
+const tracerName = "github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+
+// Span attribute keys set on flow and step spans
+const (
+	AttrFlowName    = attribute.Key("flow.name")
+	AttrExecutionID = attribute.Key("flow.execution_id")
+	AttrStepName    = attribute.Key("flow.step.name")
+	AttrStepBranch  = attribute.Key("flow.step.branch")
+	AttrBranchID    = attribute.Key("flow.branch_id")
+	AttrTimedOut    = attribute.Key("flow.timed_out")
+	AttrFailedStep  = attribute.Key("flow.failed_step")
+	AttrRecovered   = attribute.Key("flow.recovered")
+)
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used for the
+// flow's spans. It defaults to the global provider, which records nothing
+// until one is installed with otel.SetTracerProvider.
+func (f *Flow) WithTracerProvider(provider trace.TracerProvider) *Flow {
+	f.tracerProvider = provider
+	return f
+}
+
+// tracer returns the tracer for the flow's root span
+func (f *Flow) tracer() trace.Tracer {
+	if f.tracerProvider != nil {
+		return f.tracerProvider.Tracer(tracerName)
+	}
+	return otel.GetTracerProvider().Tracer(tracerName)
+}
+
+// startFlowSpan starts the root span of an execution as a child of any span
+// already on goCtx, e.g. one started for the incoming HTTP request
+func (f *Flow) startFlowSpan(goCtx context.Context, ctx interfaces.ExecutionContext) (context.Context, trace.Span) {
+	return f.tracer().Start(goCtx, "flow "+f.name,
+		trace.WithAttributes(
+			AttrFlowName.String(f.name),
+			AttrExecutionID.String(ctx.ExecutionID()),
+		))
+}
+
+// endFlowSpan records the outcome of an execution on its root span
+func endFlowSpan(span trace.Span, result *ExecutionResult) {
+	if result.TimedOut {
+		span.SetAttributes(AttrTimedOut.Bool(true))
+	}
+	if result.FailedStep != "" {
+		span.SetAttributes(
+			AttrFailedStep.String(result.FailedStep),
+			AttrRecovered.Bool(result.Recovered))
+	}
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+	}
+	span.End()
+}
+
+// startStepSpan starts a span for a step as a child of the span on ctx.
+// Steps only get spans when a parent span is recording, so steps run
+// outside a traced flow cost nothing.
+func startStepSpan(ctx interfaces.ExecutionContext, name string) (interfaces.ExecutionContext, trace.Span) {
+	goCtx := ctx.Context()
+	parent := trace.SpanFromContext(goCtx)
+	if !parent.IsRecording() {
+		return ctx, parent
+	}
+
+	attrs := []attribute.KeyValue{
+		AttrStepName.String(name),
+		AttrExecutionID.String(ctx.ExecutionID()),
+	}
+	if flowName := ctx.FlowName(); flowName != "" {
+		attrs = append(attrs, AttrFlowName.String(flowName))
+	}
+	if branch, ok := goCtx.Value(traceBranchKey{}).(string); ok {
+		attrs = append(attrs, AttrStepBranch.String(branch))
+	}
+	if branchID := contextBranchID(ctx); branchID != "" {
+		attrs = append(attrs, AttrBranchID.String(branchID))
+	}
+
+	goCtx, span := parent.TracerProvider().Tracer(tracerName).Start(goCtx, "step "+name,
+		trace.WithAttributes(attrs...))
+	return withGoContext(ctx, goCtx), span
+}
+
+// endStepSpan records the outcome of a step on its span
+func endStepSpan(span trace.Span, err error) {
+	if !span.IsRecording() {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,161 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// newTestTracer returns a tracer provider recording into an in-memory exporter
+func newTestTracer() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}
+
+// findSpan returns the exported span with the given name
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not found in %d spans", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+// spanAttr returns the value of an attribute on a span
+func spanAttr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestFlow_Tracing_Spans(t *testing.T) {
+	provider, exporter := newTestTracer()
+	flow := NewFlow("traced").
+		WithTracerProvider(provider).
+		Step("first", setter("first", "a")).
+		Parallel("fanout").
+		Step("profile", setter("profile", "profile")).
+		Step("orders", setter("orders", "orders")).
+		EndParallel()
+
+	ctx := newTestContext()
+	if _, err := flow.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 5 {
+		t.Fatalf("got %d spans, want 5", len(spans))
+	}
+
+	root := findSpan(t, spans, "flow traced")
+	if root.Parent.IsValid() {
+		t.Error("Flow span should be a root span")
+	}
+	if v, _ := spanAttr(root, AttrExecutionID); v.AsString() != ctx.ExecutionID() {
+		t.Errorf("Flow execution ID = %q, want %q", v.AsString(), ctx.ExecutionID())
+	}
+
+	first := findSpan(t, spans, "step first")
+	fanout := findSpan(t, spans, "step fanout")
+	for _, span := range []tracetest.SpanStub{first, fanout} {
+		if span.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Errorf("%s parent = %v, want the flow span", span.Name, span.Parent.SpanID())
+		}
+		if span.SpanContext.TraceID() != root.SpanContext.TraceID() {
+			t.Errorf("%s should share the flow's trace ID", span.Name)
+		}
+	}
+	if v, _ := spanAttr(first, AttrStepName); v.AsString() != "first" {
+		t.Errorf("Step name attribute = %q, want first", v.AsString())
+	}
+
+	for i, name := range []string{"step profile", "step orders"} {
+		branch := findSpan(t, spans, name)
+		if branch.Parent.SpanID() != fanout.SpanContext.SpanID() {
+			t.Errorf("%s should be a child of the parallel step", name)
+		}
+		want := []string{"fanout[0]", "fanout[1]"}[i]
+		if v, _ := spanAttr(branch, AttrStepBranch); v.AsString() != want {
+			t.Errorf("%s branch = %q, want %q", name, v.AsString(), want)
+		}
+	}
+}
+
+func TestFlow_Tracing_Failure(t *testing.T) {
+	provider, exporter := newTestTracer()
+	flow := NewFlow("traced").
+		WithTracerProvider(provider).
+		StepFunc("broken", func(ctx interfaces.ExecutionContext) error {
+			return errors.New("boom")
+		})
+
+	if _, err := flow.Execute(newTestContext()); err == nil {
+		t.Fatal("Execute() should fail")
+	}
+
+	spans := exporter.GetSpans()
+	for _, name := range []string{"flow traced", "step broken"} {
+		span := findSpan(t, spans, name)
+		if span.Status.Code != codes.Error {
+			t.Errorf("%s status = %v, want error", name, span.Status.Code)
+		}
+	}
+	root := findSpan(t, spans, "flow traced")
+	if v, _ := spanAttr(root, AttrFailedStep); v.AsString() != "broken" {
+		t.Errorf("Failed step attribute = %q, want broken", v.AsString())
+	}
+}
+
+func TestFlow_Tracing_ParentSpanAndContextSpan(t *testing.T) {
+	provider, exporter := newTestTracer()
+	goCtx, parent := provider.Tracer("test").Start(newTestContext().Context(), "request")
+
+	var stepSpan trace.Span
+	flow := NewFlow("traced").
+		WithTracerProvider(provider).
+		StepFunc("inspect", func(ctx interfaces.ExecutionContext) error {
+			stepSpan = ctx.(*Context).Span()
+			return nil
+		})
+
+	if _, err := flow.Execute(newTestContext().WithContext(goCtx)); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	parent.End()
+
+	spans := exporter.GetSpans()
+	root := findSpan(t, spans, "flow traced")
+	if root.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Flow span should be a child of the span on the incoming context")
+	}
+	step := findSpan(t, spans, "step inspect")
+	if stepSpan == nil || stepSpan.SpanContext().SpanID() != step.SpanContext.SpanID() {
+		t.Error("Context.Span() should return the running step's span")
+	}
+}
+
+func TestFlow_Tracing_NoProvider(t *testing.T) {
+	flow := NewFlow("untraced").Step("first", setter("first", "a"))
+
+	result, err := flow.Execute(newTestContext())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(result.Steps) != 1 {
+		t.Errorf("Steps = %+v, want 1 entry", result.Steps)
+	}
+}
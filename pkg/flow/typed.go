@@ -0,0 +1,125 @@
+package flow
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// Get reads key from the context as a T, converting leniently with
+// values.Convert: a float64 decoded from JSON reads as an int when it is
+// whole, numeric strings read as numbers, and maps read as structs through
+// their json tags. A missing key is a MissingField error; a value that does
+// not convert is an InvalidFormat validation error.
+func Get[T any](ctx interfaces.ExecutionContext, key string) (T, error) {
+	value, ok := ctx.Get(key)
+	if !ok {
+		var zero T
+		return zero, errors.MissingField(key)
+	}
+	return convertValue[T](key, value)
+}
+
+// GetOr is like Get but returns fallback when the key is missing or does not
+// convert
+func GetOr[T any](ctx interfaces.ExecutionContext, key string, fallback T) T {
+	value, err := Get[T](ctx, key)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetPath is like Get for a dot-notation path such as "user.profile.age"
+func GetPath[T any](ctx interfaces.ExecutionContext, path string) (T, error) {
+	value, ok := values.Lookup(path, ctx)
+	if !ok {
+		var zero T
+		return zero, errors.MissingField(path)
+	}
+	return convertValue[T](path, value)
+}
+
+// GetPathOr is like GetPath but returns fallback when the path is missing or
+// does not convert
+func GetPathOr[T any](ctx interfaces.ExecutionContext, path string, fallback T) T {
+	value, err := GetPath[T](ctx, path)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func convertValue[T any](key string, value interface{}) (T, error) {
+	converted, err := values.Convert[T](value)
+	if err != nil {
+		return converted, errors.NewValidationError(errors.ErrCodeInvalidFormat,
+			fmt.Sprintf("Context value '%s' is not a %s", key, typeName[T]())).
+			WithContext("field", key).
+			WithCause(err)
+	}
+	return converted, nil
+}
+
+func typeName[T any]() string {
+	var zero T
+	return reflect.TypeOf(&zero).Elem().String()
+}
+
+// TypedKey is a context key declared together with the type of its value,
+// so that every step reading or writing it agrees on the shape:
+//
+//	var UserProfile = flow.Key[Profile]("user_profile")
+//
+//	UserProfile.Set(ctx, profile)
+//	profile, err := UserProfile.Get(ctx)
+//
+// Reads convert leniently as Get does, so a key populated from a decoded
+// JSON document reads back as the declared struct.
+type TypedKey[T any] struct {
+	name string
+}
+
+// Key declares a typed context key
+func Key[T any](name string) TypedKey[T] {
+	return TypedKey[T]{name: name}
+}
+
+// Name returns the context key
+func (k TypedKey[T]) Name() string {
+	return k.name
+}
+
+// String returns the key and its type, e.g. "user_profile (flow.Profile)"
+func (k TypedKey[T]) String() string {
+	return fmt.Sprintf("%s (%s)", k.name, typeName[T]())
+}
+
+// Get reads the key as a T
+func (k TypedKey[T]) Get(ctx interfaces.ExecutionContext) (T, error) {
+	return Get[T](ctx, k.name)
+}
+
+// GetOr reads the key as a T, returning fallback when it is missing or does
+// not convert
+func (k TypedKey[T]) GetOr(ctx interfaces.ExecutionContext, fallback T) T {
+	return GetOr(ctx, k.name, fallback)
+}
+
+// Set stores value under the key
+func (k TypedKey[T]) Set(ctx interfaces.ExecutionContext, value T) {
+	ctx.Set(k.name, value)
+}
+
+// Has reports whether the key is set
+func (k TypedKey[T]) Has(ctx interfaces.ExecutionContext) bool {
+	return ctx.Has(k.name)
+}
+
+// Delete removes the key
+func (k TypedKey[T]) Delete(ctx interfaces.ExecutionContext) {
+	ctx.Delete(k.name)
+}
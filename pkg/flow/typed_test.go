@@ -0,0 +1,132 @@
+package flow
+
+import (
+	"errors"
+	"testing"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+)
+
+type typedProfile struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestGet_LenientConversion(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("count", 3.0)
+	ctx.Set("page", "2")
+	ctx.Set("profile", map[string]interface{}{"name": "Ada", "age": 36.0})
+
+	if count, err := Get[int](ctx, "count"); err != nil || count != 3 {
+		t.Errorf("Get[int](count) = %v, %v; want 3", count, err)
+	}
+	if page, err := Get[int64](ctx, "page"); err != nil || page != 2 {
+		t.Errorf("Get[int64](page) = %v, %v; want 2", page, err)
+	}
+	profile, err := Get[typedProfile](ctx, "profile")
+	if err != nil || profile != (typedProfile{Name: "Ada", Age: 36}) {
+		t.Errorf("Get[typedProfile] = %+v, %v", profile, err)
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("name", "Ada")
+
+	_, err := Get[string](ctx, "missing")
+	var fwErr *frameworkErrors.FrameworkError
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeMissingField {
+		t.Errorf("Missing key error = %v, want MISSING_FIELD", err)
+	}
+
+	_, err = Get[int](ctx, "name")
+	if !errors.As(err, &fwErr) || fwErr.Code != frameworkErrors.ErrCodeInvalidFormat {
+		t.Errorf("Conversion error = %v, want INVALID_FORMAT", err)
+	}
+	if fwErr.Context["field"] != "name" || errors.Unwrap(err) == nil {
+		t.Errorf("Conversion error = %+v, want the key and a cause", fwErr)
+	}
+
+	if got := GetOr(ctx, "name", 7); got != 7 {
+		t.Errorf("GetOr = %d, want the fallback", got)
+	}
+	if got := GetOr(ctx, "name", "x"); got != "Ada" {
+		t.Errorf("GetOr = %q, want the stored value", got)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("user", map[string]interface{}{
+		"profile": map[string]interface{}{"name": "Ada", "age": "36"},
+	})
+
+	if age, err := GetPath[int](ctx, "user.profile.age"); err != nil || age != 36 {
+		t.Errorf("GetPath[int] = %v, %v; want 36", age, err)
+	}
+	// Struct fields follow encoding/json, which does not read "36" as an int
+	if _, err := GetPath[typedProfile](ctx, "user.profile"); err == nil {
+		t.Error("GetPath[typedProfile] should fail for a string age")
+	}
+	if _, err := GetPath[int](ctx, "user.missing.age"); err == nil {
+		t.Error("GetPath should fail for a missing path")
+	}
+	if got := GetPathOr(ctx, "user.profile.nickname", "none"); got != "none" {
+		t.Errorf("GetPathOr = %q, want the fallback", got)
+	}
+}
+
+func TestTypedKey(t *testing.T) {
+	userProfile := Key[typedProfile]("user_profile")
+	ctx := newTestContext()
+
+	if userProfile.Has(ctx) {
+		t.Error("Key should not be set yet")
+	}
+	if _, err := userProfile.Get(ctx); err == nil {
+		t.Error("Get should fail for an unset key")
+	}
+
+	userProfile.Set(ctx, typedProfile{Name: "Ada", Age: 36})
+	if got, err := userProfile.Get(ctx); err != nil || got.Name != "Ada" {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+
+	// A value decoded from JSON by another step reads back as the struct
+	ctx.Set(userProfile.Name(), map[string]interface{}{"name": "Grace", "age": 45.0})
+	if got := userProfile.GetOr(ctx, typedProfile{}); got.Name != "Grace" || got.Age != 45 {
+		t.Errorf("GetOr = %+v, want the converted profile", got)
+	}
+
+	if userProfile.String() != "user_profile (flow.typedProfile)" {
+		t.Errorf("String() = %q", userProfile.String())
+	}
+
+	userProfile.Delete(ctx)
+	if userProfile.Has(ctx) {
+		t.Error("Delete should remove the key")
+	}
+}
+
+func TestContext_LenientGetters(t *testing.T) {
+	ctx := newTestContext()
+	ctx.Set("count", 42.0)
+	ctx.Set("fraction", 4.5)
+	ctx.Set("enabled", "true")
+
+	if n, err := ctx.GetInt("count"); err != nil || n != 42 {
+		t.Errorf("GetInt(count) = %v, %v; want 42", n, err)
+	}
+	if _, err := ctx.GetInt("fraction"); err == nil {
+		t.Error("GetInt should reject fractions")
+	}
+	if b, err := ctx.GetBool("enabled"); err != nil || !b {
+		t.Errorf("GetBool(enabled) = %v, %v; want true", b, err)
+	}
+
+	var n int64
+	if err := ctx.GetTyped("count", &n); err != nil || n != 42 {
+		t.Errorf("GetTyped = %v, %v; want 42", n, err)
+	}
+}
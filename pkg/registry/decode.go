@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// DecodeConfig fills the struct pointed to by target from a step config map.
+// Keys are matched the same way ValidateConfigSpec matches them, fields that
+// are absent take their `default` tag, and durations may be given as
+// strings such as "5s" or as nanoseconds. Step factories use it to turn the
+// map they receive into their typed config.
+func DecodeConfig(config map[string]interface{}, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config target must be a pointer to a struct, got %T", target)
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := field.Name
+		if jsonTag := field.Tag.Get("json"); jsonTag != "" {
+			name := strings.Split(jsonTag, ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				key = name
+			}
+		}
+
+		value, present := config[key]
+		if !present {
+			defaultTag, hasDefault := field.Tag.Lookup("default")
+			if !hasDefault {
+				continue
+			}
+			if err := setDefault(v.Field(i), defaultTag); err != nil {
+				return fmt.Errorf("invalid default for field '%s': %w", key, err)
+			}
+			continue
+		}
+
+		if err := setConfigValue(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid value for field '%s': %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setConfigValue assigns a decoded config value to a struct field
+func setConfigValue(field reflect.Value, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if field.Type() == durationType {
+		switch d := value.(type) {
+		case string:
+			parsed, err := time.ParseDuration(d)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(parsed))
+			return nil
+		case time.Duration:
+			field.SetInt(int64(d))
+			return nil
+		}
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return nil
+	}
+	if isNumber(rv) && isNumber(reflect.New(field.Type()).Elem()) {
+		if !isIntegral(rv) && field.Kind() != reflect.Float32 && field.Kind() != reflect.Float64 {
+			return fmt.Errorf("expected an integer, got %v", value)
+		}
+		field.Set(rv.Convert(field.Type()))
+		return nil
+	}
+
+	// Lists, maps and nested structs decoded from YAML/JSON arrive as
+	// []interface{} / map[string]interface{}; round-trip them through JSON
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(field.Type())
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return fmt.Errorf("expected %s, got %T", field.Type(), value)
+	}
+	field.Set(ptr.Elem())
+	return nil
+}
+
+// setDefault parses a `default` tag into a struct field
+func setDefault(field reflect.Value, tag string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(tag)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(tag)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(tag)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(tag, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(tag, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(tag, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		if tag == "" {
+			return nil
+		}
+		ptr := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(tag), ptr.Interface()); err != nil {
+			return err
+		}
+		field.Set(ptr.Elem())
+	}
+	return nil
+}
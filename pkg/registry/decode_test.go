@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeSampleConfig struct {
+	URL      string            `json:"url"`
+	Method   string            `json:"method,omitempty" default:"GET"`
+	Retries  int               `json:"retries,omitempty" default:"2"`
+	Ratio    float64           `json:"ratio,omitempty" default:"0.5"`
+	Enabled  bool              `json:"enabled,omitempty" default:"true"`
+	Timeout  time.Duration     `json:"timeout,omitempty" default:"5s"`
+	Headers  map[string]string `json:"headers,omitempty" default:"{}"`
+	Fields   []string          `json:"fields,omitempty" default:"[]"`
+	Value    interface{}       `json:"value,omitempty" default:""`
+	Ignored  string            `json:"-"`
+	NoTag    string
+	internal string
+}
+
+func TestDecodeConfig_Defaults(t *testing.T) {
+	var cfg decodeSampleConfig
+	err := DecodeConfig(map[string]interface{}{"url": "http://example.com"}, &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://example.com", cfg.URL)
+	assert.Equal(t, "GET", cfg.Method)
+	assert.Equal(t, 2, cfg.Retries)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, map[string]string{}, cfg.Headers)
+	assert.Equal(t, []string{}, cfg.Fields)
+	assert.Nil(t, cfg.Value)
+}
+
+func TestDecodeConfig_Values(t *testing.T) {
+	var cfg decodeSampleConfig
+	err := DecodeConfig(map[string]interface{}{
+		"url":     "http://example.com",
+		"method":  "POST",
+		"retries": float64(3), // JSON numbers decode as float64
+		"ratio":   1,
+		"enabled": false,
+		"timeout": "250ms",
+		"headers": map[string]interface{}{"X-Test": "yes"},
+		"fields":  []interface{}{"id", "name"},
+		"value":   map[string]interface{}{"a": 1},
+		"NoTag":   "plain",
+	}, &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", cfg.Method)
+	assert.Equal(t, 3, cfg.Retries)
+	assert.Equal(t, 1.0, cfg.Ratio)
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, 250*time.Millisecond, cfg.Timeout)
+	assert.Equal(t, map[string]string{"X-Test": "yes"}, cfg.Headers)
+	assert.Equal(t, []string{"id", "name"}, cfg.Fields)
+	assert.Equal(t, map[string]interface{}{"a": 1}, cfg.Value)
+	assert.Equal(t, "plain", cfg.NoTag)
+}
+
+func TestDecodeConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		field  string
+	}{
+		{"fractional int", map[string]interface{}{"retries": 1.5}, "retries"},
+		{"bad duration", map[string]interface{}{"timeout": "soon"}, "timeout"},
+		{"wrong list type", map[string]interface{}{"fields": "id"}, "fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg decodeSampleConfig
+			err := DecodeConfig(tt.config, &cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.field)
+		})
+	}
+}
+
+func TestDecodeConfig_InvalidTarget(t *testing.T) {
+	var cfg decodeSampleConfig
+	assert.Error(t, DecodeConfig(map[string]interface{}{}, cfg))
+
+	var s string
+	assert.Error(t, DecodeConfig(map[string]interface{}{}, &s))
+}
+
+func TestGenerateConfigSpec_EmptyDefaultIsOptional(t *testing.T) {
+	spec := generateConfigSpec(decodeSampleConfig{})
+
+	value := spec["Value"].(map[string]interface{})
+	assert.Equal(t, false, value["required"])
+	url := spec["URL"].(map[string]interface{})
+	assert.Equal(t, true, url["required"])
+}
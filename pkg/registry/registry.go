@@ -155,6 +155,11 @@ func (r *StepRegistry) Exists(name string) bool {
 // Global registry instance
 var globalRegistry = NewStepRegistry()
 
+// GetGlobalRegistry returns the global step registry
+func GetGlobalRegistry() *StepRegistry {
+	return globalRegistry
+}
+
 // Global registry functions
 func Register(info *StepInfo) error {
 	return globalRegistry.Register(info)
@@ -184,8 +189,9 @@ func Exists(name string) bool {
 	return globalRegistry.Exists(name)
 }
 
-// Helper function to register a step with reflection-based config spec
-func RegisterWithReflection(name, description, category, version string, factory StepFactory, configType interface{}) error {
+// RegisterWithReflection registers a step with a config spec generated from
+// configType's struct fields and tags
+func (r *StepRegistry) RegisterWithReflection(name, description, category, version string, factory StepFactory, configType interface{}) error {
 	configSpec := generateConfigSpec(configType)
 
 	info := &StepInfo{
@@ -197,7 +203,12 @@ func RegisterWithReflection(name, description, category, version string, factory
 		Factory:     factory,
 	}
 
-	return Register(info)
+	return r.Register(info)
+}
+
+// Helper function to register a step with reflection-based config spec
+func RegisterWithReflection(name, description, category, version string, factory StepFactory, configType interface{}) error {
+	return globalRegistry.RegisterWithReflection(name, description, category, version, factory, configType)
 }
 
 // generateConfigSpec uses reflection to generate a config specification
@@ -242,8 +253,8 @@ func generateConfigSpec(configType interface{}) map[string]interface{} {
 			fieldSpec["description"] = descTag
 		}
 
-		// Check for default tags
-		if defaultTag := field.Tag.Get("default"); defaultTag != "" {
+		// Check for default tags; an empty default still makes the field optional
+		if defaultTag, ok := field.Tag.Lookup("default"); ok {
 			fieldSpec["default"] = defaultTag
 			fieldSpec["required"] = false
 		}
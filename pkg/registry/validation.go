@@ -0,0 +1,190 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigError describes a step config value that does not satisfy the
+// step's ConfigSpec
+type ConfigError struct {
+	Step    string `json:"step"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config for step '%s': field '%s' %s", e.Step, e.Field, e.Message)
+}
+
+// ValidateConfig checks a config map against the ConfigSpec of a registered
+// step. Steps registered without a ConfigSpec accept any config.
+func (r *StepRegistry) ValidateConfig(name string, config map[string]interface{}) error {
+	r.mu.RLock()
+	info, exists := r.steps[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("step '%s' is not registered", name)
+	}
+
+	return ValidateConfigSpec(name, info.ConfigSpec, config)
+}
+
+// ValidateConfig checks a config map against a step in the global registry
+func ValidateConfig(name string, config map[string]interface{}) error {
+	return globalRegistry.ValidateConfig(name, config)
+}
+
+// ValidateConfigSpec checks a config map against a spec produced by
+// generateConfigSpec. Config keys are matched by json name when the field
+// has a json tag and by Go field name otherwise. Fields are checked in
+// sorted order so the first reported error is deterministic.
+func ValidateConfigSpec(stepName string, spec map[string]interface{}, config map[string]interface{}) error {
+	if spec == nil {
+		return nil
+	}
+
+	known := make(map[string]bool, len(spec))
+	fieldNames := make([]string, 0, len(spec))
+	for fieldName := range spec {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+
+	for _, fieldName := range fieldNames {
+		fieldSpec, ok := spec[fieldName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		key := configKey(fieldName, fieldSpec)
+		if key == "" {
+			continue
+		}
+		known[key] = true
+
+		value, present := config[key]
+		if !present {
+			if required, _ := fieldSpec["required"].(bool); required {
+				return &ConfigError{Step: stepName, Field: key, Message: "is required"}
+			}
+			continue
+		}
+
+		typeName, _ := fieldSpec["type"].(string)
+		if msg := checkConfigType(typeName, value); msg != "" {
+			return &ConfigError{Step: stepName, Field: key, Message: msg}
+		}
+	}
+
+	unknown := make([]string, 0)
+	for key := range config {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &ConfigError{Step: stepName, Field: unknown[0], Message: "is not a known config field"}
+	}
+
+	return nil
+}
+
+// configKey returns the config map key for a spec field, or "" if the field
+// is excluded from serialization
+func configKey(fieldName string, fieldSpec map[string]interface{}) string {
+	jsonName, _ := fieldSpec["json_name"].(string)
+	if jsonName == "" {
+		return fieldName
+	}
+
+	name := strings.Split(jsonName, ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return fieldName
+	default:
+		return name
+	}
+}
+
+// checkConfigType returns a description of the mismatch between value and
+// the Go type named in a ConfigSpec, or "" if the value is acceptable
+func checkConfigType(typeName string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case typeName == "string":
+		if v.Kind() != reflect.String {
+			return fmt.Sprintf("must be a string, got %T", value)
+		}
+	case typeName == "bool":
+		if v.Kind() != reflect.Bool {
+			return fmt.Sprintf("must be a bool, got %T", value)
+		}
+	case isIntegerType(typeName):
+		if !isIntegral(v) {
+			return fmt.Sprintf("must be an integer, got %v", value)
+		}
+	case typeName == "float32" || typeName == "float64":
+		if !isNumber(v) {
+			return fmt.Sprintf("must be a number, got %T", value)
+		}
+	case typeName == "time.Duration":
+		if v.Kind() == reflect.String {
+			if _, err := time.ParseDuration(v.String()); err != nil {
+				return fmt.Sprintf("must be a duration, got %q", v.String())
+			}
+		} else if !isIntegral(v) {
+			return fmt.Sprintf("must be a duration, got %T", value)
+		}
+	case strings.HasPrefix(typeName, "[]"):
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fmt.Sprintf("must be a list, got %T", value)
+		}
+	case strings.HasPrefix(typeName, "map["):
+		if v.Kind() != reflect.Map {
+			return fmt.Sprintf("must be a map, got %T", value)
+		}
+	}
+	return ""
+}
+
+func isIntegerType(typeName string) bool {
+	switch typeName {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isIntegral(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return f == math.Trunc(f)
+	default:
+		return isNumber(v)
+	}
+}
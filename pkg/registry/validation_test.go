@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidationSampleConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty" default:"GET"`
+	Retries int               `json:"retries,omitempty" default:"0"`
+	Timeout time.Duration     `json:"timeout,omitempty" default:"5s"`
+	Headers map[string]string `json:"headers,omitempty" default:"{}"`
+	Fields  []string          `json:"fields,omitempty" default:"[]"`
+	Ignored string            `json:"-"`
+}
+
+func TestValidateConfigSpec(t *testing.T) {
+	spec := generateConfigSpec(ValidationSampleConfig{})
+
+	t.Run("nil spec accepts anything", func(t *testing.T) {
+		assert.NoError(t, ValidateConfigSpec("step", nil, map[string]interface{}{"anything": 1}))
+	})
+
+	t.Run("valid config", func(t *testing.T) {
+		err := ValidateConfigSpec("step", spec, map[string]interface{}{
+			"url":     "http://example.com",
+			"retries": float64(3),
+			"timeout": "2s",
+			"headers": map[string]interface{}{"X-Test": "1"},
+			"fields":  []interface{}{"id", "name"},
+		})
+		assert.NoError(t, err)
+	})
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		field   string
+		message string
+	}{
+		{"missing required", map[string]interface{}{}, "url", "is required"},
+		{"wrong string type", map[string]interface{}{"url": 42}, "url", "must be a string, got int"},
+		{"fractional integer", map[string]interface{}{"url": "u", "retries": 1.5}, "retries", "must be an integer, got 1.5"},
+		{"bad duration", map[string]interface{}{"url": "u", "timeout": "soon"}, "timeout", `must be a duration, got "soon"`},
+		{"wrong map type", map[string]interface{}{"url": "u", "headers": "x"}, "headers", "must be a map, got string"},
+		{"wrong list type", map[string]interface{}{"url": "u", "fields": "id"}, "fields", "must be a list, got string"},
+		{"unknown field", map[string]interface{}{"url": "u", "uri": "typo"}, "uri", "is not a known config field"},
+		{"excluded field", map[string]interface{}{"url": "u", "Ignored": "x"}, "Ignored", "is not a known config field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfigSpec("step", spec, tt.config)
+			var configErr *ConfigError
+			if assert.True(t, errors.As(err, &configErr)) {
+				assert.Equal(t, "step", configErr.Step)
+				assert.Equal(t, tt.field, configErr.Field)
+				assert.Equal(t, tt.message, configErr.Message)
+			}
+		})
+	}
+}
+
+func TestStepRegistry_ValidateConfig(t *testing.T) {
+	r := NewStepRegistry()
+	factory := mockStepFactory("validated", "Validated", false)
+	r.Register(&StepInfo{Name: "validated", Factory: factory, ConfigSpec: generateConfigSpec(ValidationSampleConfig{})})
+	r.Register(&StepInfo{Name: "unvalidated", Factory: factory})
+
+	assert.NoError(t, r.ValidateConfig("validated", map[string]interface{}{"url": "u"}))
+	assert.EqualError(t, r.ValidateConfig("validated", map[string]interface{}{}),
+		"invalid config for step 'validated': field 'url' is required")
+	assert.NoError(t, r.ValidateConfig("unvalidated", map[string]interface{}{"anything": true}))
+	assert.EqualError(t, r.ValidateConfig("missing", nil), "step 'missing' is not registered")
+}
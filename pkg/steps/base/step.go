@@ -19,11 +19,14 @@ type Step interface {
 	Description() string
 }
 
-// BaseStep provides common functionality for steps
+// BaseStep provides common functionality for steps. Until WithTimeout is
+// called, the step runs under the configured step execution timeout when
+// there is one, and under its default timeout otherwise.
 type BaseStep struct {
 	name        string
 	description string
 	timeout     time.Duration
+	timeoutSet  bool
 }
 
 // NewBaseStep creates a new base step
@@ -35,6 +38,12 @@ func NewBaseStep(name, description string) *BaseStep {
 	}
 }
 
+// newCompositeBaseStep creates the base of a step that only runs other
+// steps. Each child runs under its own deadline, so the step has none.
+func newCompositeBaseStep(name, description string) *BaseStep {
+	return NewBaseStep(name, description).WithTimeout(0)
+}
+
 func (s *BaseStep) Name() string {
 	return s.name
 }
@@ -43,8 +52,11 @@ func (s *BaseStep) Description() string {
 	return s.description
 }
 
+// WithTimeout sets the step's own deadline. Zero runs the step without a
+// deadline of its own, bounded only by its parent's.
 func (s *BaseStep) WithTimeout(timeout time.Duration) *BaseStep {
 	s.timeout = timeout
+	s.timeoutSet = true
 	return s
 }
 
@@ -52,6 +64,17 @@ func (s *BaseStep) Timeout() time.Duration {
 	return s.timeout
 }
 
+// TimeoutSet reports whether the timeout was set with WithTimeout
+func (s *BaseStep) TimeoutSet() bool {
+	return s.timeoutSet
+}
+
+// runStep executes a step under its own deadline, or the configured step
+// execution timeout when it does not declare one
+func runStep(ctx *flow.Context, step Step) error {
+	return flow.RunWithTimeout(ctx, step.Name(), flow.StepTimeout(ctx, step), step.Run)
+}
+
 // StepFunc is a function type that implements Step
 type StepFunc func(ctx *flow.Context) error
 
@@ -77,7 +100,7 @@ type ConditionalStep struct {
 // NewConditionalStep creates a conditional step
 func NewConditionalStep(name string, condition func(*flow.Context) bool, step Step) *ConditionalStep {
 	return &ConditionalStep{
-		BaseStep:  NewBaseStep(name, "Conditional: "+step.Description()),
+		BaseStep:  newCompositeBaseStep(name, "Conditional: "+step.Description()),
 		condition: condition,
 		step:      step,
 	}
@@ -88,7 +111,7 @@ func (s *ConditionalStep) Run(ctx *flow.Context) error {
 		ctx.Logger().Info("Condition met, executing step",
 			zap.String("step", s.Name()),
 			zap.String("conditional_step", s.step.Name()))
-		return s.step.Run(ctx)
+		return runStep(ctx, s.step)
 	}
 
 	ctx.Logger().Info("Condition not met, skipping step",
@@ -106,7 +129,7 @@ type SequentialStep struct {
 // NewSequentialStep creates a sequential step
 func NewSequentialStep(name string, steps ...Step) *SequentialStep {
 	return &SequentialStep{
-		BaseStep: NewBaseStep(name, "Sequential execution"),
+		BaseStep: newCompositeBaseStep(name, "Sequential execution"),
 		steps:    steps,
 	}
 }
@@ -118,7 +141,7 @@ func (s *SequentialStep) Run(ctx *flow.Context) error {
 			zap.String("step", step.Name()),
 			zap.Int("index", i))
 
-		if err := step.Run(ctx); err != nil {
+		if err := runStep(ctx, step); err != nil {
 			ctx.Logger().Error("Sequential step failed",
 				zap.String("parent_step", s.Name()),
 				zap.String("step", step.Name()),
@@ -133,17 +156,25 @@ func (s *SequentialStep) Run(ctx *flow.Context) error {
 // ParallelStep executes multiple steps concurrently
 type ParallelStep struct {
 	*BaseStep
-	steps []Step
+	steps          []Step
+	maxConcurrency int
 }
 
 // NewParallelStep creates a parallel step
 func NewParallelStep(name string, steps ...Step) *ParallelStep {
 	return &ParallelStep{
-		BaseStep: NewBaseStep(name, "Parallel execution"),
+		BaseStep: newCompositeBaseStep(name, "Parallel execution"),
 		steps:    steps,
 	}
 }
 
+// WithMaxConcurrency limits how many steps run at once. n <= 0 removes the
+// limit.
+func (s *ParallelStep) WithMaxConcurrency(n int) *ParallelStep {
+	s.maxConcurrency = n
+	return s
+}
+
 func (s *ParallelStep) Run(ctx *flow.Context) error {
 	if len(s.steps) == 0 {
 		return nil
@@ -151,10 +182,19 @@ func (s *ParallelStep) Run(ctx *flow.Context) error {
 
 	// Create error channel
 	errChan := make(chan error, len(s.steps))
+	limiter := flow.NewLimiter(s.Name(), s.maxConcurrency)
 
-	// Execute steps concurrently
+	// Execute steps concurrently, waiting for a slot when limited
 	for _, step := range s.steps {
+		if err := limiter.Acquire(ctx.Context()); err != nil {
+			errChan <- err
+			continue
+		}
+
 		go func(step Step) {
+			goCtx, release := limiter.Hold(ctx.Context())
+			defer release()
+
 			// Clone context for each parallel step to avoid race conditions
 			clonedCtx := ctx.Clone()
 			stepCtx, ok := clonedCtx.(*flow.Context)
@@ -168,12 +208,13 @@ func (s *ParallelStep) Run(ctx *flow.Context) error {
 				}
 				stepCtx.WithFlowName(ctx.FlowName()).WithLogger(ctx.Logger())
 			}
+			stepCtx.WithContext(goCtx)
 
 			stepCtx.Logger().Info("Starting parallel step",
 				zap.String("parent_step", s.Name()),
 				zap.String("step", step.Name()))
 
-			err := step.Run(stepCtx)
+			err := runStep(stepCtx, step)
 			if err != nil {
 				stepCtx.Logger().Error("Parallel step failed",
 					zap.String("parent_step", s.Name()),
@@ -215,7 +256,7 @@ type RetryStep struct {
 // NewRetryStep creates a retry step
 func NewRetryStep(name string, step Step, maxRetries int, retryDelay time.Duration) *RetryStep {
 	return &RetryStep{
-		BaseStep:    NewBaseStep(name, "Retry wrapper for: "+step.Description()),
+		BaseStep:    newCompositeBaseStep(name, "Retry wrapper for: "+step.Description()),
 		step:        step,
 		maxRetries:  maxRetries,
 		retryDelay:  retryDelay,
@@ -228,6 +269,20 @@ func (s *RetryStep) WithRetryCondition(shouldRetry func(error) bool) *RetryStep
 	return s
 }
 
+// wait sleeps for the retry delay, returning early with the context error
+// if ctx is cancelled first
+func (s *RetryStep) wait(ctx *flow.Context) error {
+	timer := time.NewTimer(s.retryDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Context().Done():
+		return ctx.Context().Err()
+	}
+}
+
 func (s *RetryStep) Run(ctx *flow.Context) error {
 	var lastErr error
 
@@ -239,7 +294,9 @@ func (s *RetryStep) Run(ctx *flow.Context) error {
 				zap.Int("attempt", attempt),
 				zap.Int("max_retries", s.maxRetries))
 
-			time.Sleep(s.retryDelay)
+			if err := s.wait(ctx); err != nil {
+				return err
+			}
 		}
 
 		err := s.step.Run(ctx)
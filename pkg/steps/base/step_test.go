@@ -3,6 +3,9 @@ package base
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -338,6 +341,29 @@ func TestParallelStep(t *testing.T) {
 	}
 }
 
+func TestParallelStep_MaxConcurrency(t *testing.T) {
+	var running, peak int32
+	steps := make([]Step, 10)
+	for i := range steps {
+		steps[i] = &mockStep{name: fmt.Sprintf("step%d", i), runFunc: func(ctx *flow.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}}
+	}
+
+	err := NewParallelStep("bounded", steps...).WithMaxConcurrency(2).Run(flow.NewContext().WithLogger(zap.NewNop()))
+	if err != nil {
+		t.Errorf("Run() failed: %v", err)
+	}
+	if p := atomic.LoadInt32(&peak); p > 2 {
+		t.Errorf("Peak concurrency = %d, want at most 2", p)
+	}
+}
+
 func TestParallelStep_Empty(t *testing.T) {
 	parallelStep := NewParallelStep("empty_parallel")
 	ctx := flow.NewContext()
@@ -446,6 +472,29 @@ func TestRetryStep_MaxRetriesExceeded(t *testing.T) {
 	}
 }
 
+func TestRetryStep_DelayHonoursCancellation(t *testing.T) {
+	mockStep := &mockStep{
+		name: "retry_inner",
+		runFunc: func(ctx *flow.Context) error {
+			return errors.New("persistent error")
+		},
+	}
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	retryStep := NewRetryStep("retry_cancelled", mockStep, 3, 5*time.Second)
+
+	start := time.Now()
+	err := retryStep.Run(flow.NewContext().WithLogger(zap.NewNop()).WithContext(goCtx))
+
+	if time.Since(start) > time.Second {
+		t.Fatal("The retry delay should end when the context is cancelled")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() error = %v, want the context error", err)
+	}
+}
+
 func TestRetryStep_WithRetryCondition(t *testing.T) {
 	attempts := 0
 	nonRetryableError := errors.New("non-retryable error")
@@ -559,3 +608,41 @@ func TestDelayStep_ShortDelay(t *testing.T) {
 		t.Errorf("Short delay took too long: %v", duration)
 	}
 }
+
+func TestSequentialStep_ChildTimeout(t *testing.T) {
+	slow := NewDelayStep("slow", 5*time.Second)
+	slow.WithTimeout(30 * time.Millisecond)
+
+	ctx := flow.NewContext().WithLogger(zap.NewNop())
+	start := time.Now()
+	err := NewSequentialStep("sequence", slow).Run(ctx)
+
+	if err == nil {
+		t.Fatal("Run() should have timed out")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Child timeout was not enforced")
+	}
+	if !strings.Contains(err.Error(), "Step 'slow' timed out") {
+		t.Errorf("Error = %v, want timeout naming 'slow'", err)
+	}
+}
+
+func TestParallelStep_ChildTimeout(t *testing.T) {
+	slow := NewDelayStep("slow", 5*time.Second)
+	slow.WithTimeout(30 * time.Millisecond)
+	fast := &mockStep{name: "fast", runFunc: func(ctx *flow.Context) error {
+		ctx.Set("fast", true)
+		return nil
+	}}
+
+	ctx := flow.NewContext().WithLogger(zap.NewNop())
+	err := NewParallelStep("fanout", fast, slow).Run(ctx)
+
+	if err == nil || !strings.Contains(err.Error(), "Step 'slow' timed out") {
+		t.Errorf("Error = %v, want timeout naming 'slow'", err)
+	}
+	if !ctx.Has("fast") {
+		t.Error("Fast branch result should still be merged")
+	}
+}
@@ -1,11 +1,14 @@
 package bff
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/transformers"
 	"go.uber.org/zap"
@@ -14,11 +17,12 @@ import (
 // AggregationStep combines multiple API responses for mobile BFF patterns
 type AggregationStep struct {
 	*base.BaseStep
-	steps       []base.Step
+	steps       []interfaces.Step
 	transformer transformers.Transformer
 	parallel    bool
 	timeout     time.Duration
 	failFast    bool
+	maxParallel int
 	required    map[string]bool        // Which steps are required vs optional
 	fallbacks   map[string]interface{} // Fallback data for failed steps
 }
@@ -27,7 +31,7 @@ type AggregationStep struct {
 func NewAggregationStep(name string) *AggregationStep {
 	return &AggregationStep{
 		BaseStep:  base.NewBaseStep(name, "BFF Aggregation: "+name),
-		steps:     make([]base.Step, 0),
+		steps:     make([]interfaces.Step, 0),
 		parallel:  true,
 		timeout:   30 * time.Second,
 		failFast:  false,
@@ -39,20 +43,20 @@ func NewAggregationStep(name string) *AggregationStep {
 // Configuration methods
 
 // AddStep adds a step to the aggregation
-func (a *AggregationStep) AddStep(step base.Step) *AggregationStep {
+func (a *AggregationStep) AddStep(step interfaces.Step) *AggregationStep {
 	a.steps = append(a.steps, step)
 	return a
 }
 
 // AddRequiredStep adds a required step (failure will fail the aggregation)
-func (a *AggregationStep) AddRequiredStep(step base.Step) *AggregationStep {
+func (a *AggregationStep) AddRequiredStep(step interfaces.Step) *AggregationStep {
 	a.steps = append(a.steps, step)
 	a.required[step.Name()] = true
 	return a
 }
 
 // AddOptionalStep adds an optional step with fallback data
-func (a *AggregationStep) AddOptionalStep(step base.Step, fallback interface{}) *AggregationStep {
+func (a *AggregationStep) AddOptionalStep(step interfaces.Step, fallback interface{}) *AggregationStep {
 	a.steps = append(a.steps, step)
 	a.required[step.Name()] = false
 	a.fallbacks[step.Name()] = fallback
@@ -77,6 +81,13 @@ func (a *AggregationStep) WithTimeout(timeout time.Duration) *AggregationStep {
 	return a
 }
 
+// WithMaxConcurrency limits how many steps run at once in parallel mode.
+// n <= 0 removes the limit.
+func (a *AggregationStep) WithMaxConcurrency(n int) *AggregationStep {
+	a.maxParallel = n
+	return a
+}
+
 // WithFailFast controls whether to fail immediately on any error
 func (a *AggregationStep) WithFailFast(failFast bool) *AggregationStep {
 	a.failFast = failFast
@@ -84,7 +95,7 @@ func (a *AggregationStep) WithFailFast(failFast bool) *AggregationStep {
 }
 
 // Run executes the aggregation step
-func (a *AggregationStep) Run(ctx *flow.Context) error {
+func (a *AggregationStep) Run(ctx interfaces.ExecutionContext) error {
 	startTime := time.Now()
 
 	ctx.Logger().Info("Starting BFF aggregation",
@@ -140,44 +151,39 @@ func (a *AggregationStep) Run(ctx *flow.Context) error {
 }
 
 // runParallel executes steps in parallel with timeout
-func (a *AggregationStep) runParallel(ctx *flow.Context) (map[string]interface{}, error) {
+func (a *AggregationStep) runParallel(ctx interfaces.ExecutionContext) (map[string]interface{}, error) {
 	results := make(map[string]interface{})
-	errors := make(map[string]error)
+	failures := make(map[string]error)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	limiter := flow.NewLimiter(a.Name(), a.maxParallel)
 
-	// Create a context with timeout
-	timeoutCtx := ctx.WithTimeout(a.timeout)
-	defer func() {
-		// Context cleanup - no Cancel method needed
-	}()
-
-	// Execute steps in parallel
+	// Execute steps in parallel, each bounded by the aggregation timeout
 	for _, step := range a.steps {
+		if err := limiter.Acquire(ctx.Context()); err != nil {
+			mu.Lock()
+			failures[step.Name()] = err
+			if fallback, hasFallback := a.fallbacks[step.Name()]; hasFallback {
+				results[step.Name()] = fallback
+			}
+			mu.Unlock()
+			continue
+		}
+
 		wg.Add(1)
-		go func(s base.Step) {
+		go func(s interfaces.Step) {
 			defer wg.Done()
+			goCtx, release := limiter.Hold(ctx.Context())
+			defer release()
 
-			clonedCtx := timeoutCtx.Clone()
-			stepCtx, ok := clonedCtx.(*flow.Context)
-			if !ok {
-				// Fallback: create a new context with the same data
-				stepCtx = flow.NewContext()
-				for _, key := range timeoutCtx.Keys() {
-					if val, exists := timeoutCtx.Get(key); exists {
-						stepCtx.Set(key, val)
-					}
-				}
-				stepCtx.WithFlowName(timeoutCtx.FlowName()).WithLogger(timeoutCtx.Logger())
-			}
-
-			err := s.Run(stepCtx)
+			stepCtx := flow.WithGoContext(ctx.Clone(), goCtx)
+			err := flow.RunStepWithTimeout(stepCtx, s, a.timeout)
 
 			mu.Lock()
 			defer mu.Unlock()
 
 			if err != nil {
-				errors[s.Name()] = err
+				failures[s.Name()] = err
 
 				// Use fallback if available
 				if fallback, hasFallback := a.fallbacks[s.Name()]; hasFallback {
@@ -210,7 +216,7 @@ func (a *AggregationStep) runParallel(ctx *flow.Context) (map[string]interface{}
 	// Check for required step failures
 	for stepName, isRequired := range a.required {
 		if isRequired {
-			if err, hasError := errors[stepName]; hasError {
+			if err, hasError := failures[stepName]; hasError {
 				if _, hasFallback := a.fallbacks[stepName]; !hasFallback {
 					return nil, fmt.Errorf("required step %s failed: %w", stepName, err)
 				}
@@ -219,8 +225,8 @@ func (a *AggregationStep) runParallel(ctx *flow.Context) (map[string]interface{}
 	}
 
 	// Fail fast if enabled and any error occurred
-	if a.failFast && len(errors) > 0 {
-		for stepName, err := range errors {
+	if a.failFast && len(failures) > 0 {
+		for stepName, err := range failures {
 			return nil, fmt.Errorf("step %s failed (fail-fast enabled): %w", stepName, err)
 		}
 	}
@@ -229,24 +235,24 @@ func (a *AggregationStep) runParallel(ctx *flow.Context) (map[string]interface{}
 }
 
 // runSequential executes steps sequentially
-func (a *AggregationStep) runSequential(ctx *flow.Context) (map[string]interface{}, error) {
+func (a *AggregationStep) runSequential(ctx interfaces.ExecutionContext) (map[string]interface{}, error) {
 	results := make(map[string]interface{})
 
+	deadline := time.Now().Add(a.timeout)
+
 	for _, step := range a.steps {
-		clonedCtx := ctx.Clone()
-		stepCtx, ok := clonedCtx.(*flow.Context)
-		if !ok {
-			// Fallback: create a new context with the same data
-			stepCtx = flow.NewContext()
-			for _, key := range ctx.Keys() {
-				if val, exists := ctx.Get(key); exists {
-					stepCtx.Set(key, val)
-				}
-			}
-			stepCtx.WithFlowName(ctx.FlowName()).WithLogger(ctx.Logger())
+		stepCtx := ctx.Clone()
+
+		// The aggregation timeout bounds the whole sequence
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, errors.NewTimeoutError(errors.ErrCodeExecutionTimeout,
+				fmt.Sprintf("Aggregation '%s' timed out after %s before step '%s'", a.Name(), a.timeout, step.Name())).
+				WithContext("step", a.Name()).
+				WithContext("timeout", a.timeout.String()).
+				WithCause(context.DeadlineExceeded)
 		}
-
-		err := step.Run(stepCtx)
+		err := flow.RunStepWithTimeout(stepCtx, step, remaining)
 
 		if err != nil {
 			// Use fallback if available
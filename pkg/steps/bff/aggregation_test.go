@@ -1,15 +1,18 @@
 package bff
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
-	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 )
 
 // MockStep for testing
@@ -34,7 +37,7 @@ func (m *MockStep) Description() string {
 	return m.description
 }
 
-func (m *MockStep) Run(ctx *flow.Context) error {
+func (m *MockStep) Run(ctx interfaces.ExecutionContext) error {
 	args := m.Called(ctx)
 
 	// Simulate setting some data in context
@@ -46,7 +49,7 @@ func (m *MockStep) Run(ctx *flow.Context) error {
 	return args.Error(0)
 }
 
-func (m *MockStep) SetTimeout(timeout time.Duration) base.Step {
+func (m *MockStep) SetTimeout(timeout time.Duration) interfaces.Step {
 	return m
 }
 
@@ -176,6 +179,30 @@ func TestAggregationStep_RunParallel_Success(t *testing.T) {
 	step3.AssertExpectations(t)
 }
 
+func TestAggregationStep_RunParallel_MaxConcurrency(t *testing.T) {
+	var running, peak int32
+	aggregation := NewAggregationStep("bounded").WithMaxConcurrency(2)
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("step%d", i)
+		aggregation.AddStep(flow.NewNamedStep(name, flow.StepFunc(func(ctx interfaces.ExecutionContext) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			time.Sleep(5 * time.Millisecond)
+			ctx.Set(name, true)
+			return nil
+		})))
+	}
+
+	ctx := flow.NewContext().WithFlowName("test_flow")
+	assert.NoError(t, aggregation.Run(ctx))
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+
+	results, _ := ctx.Get("bff_bounded")
+	assert.Len(t, results, 8)
+}
+
 func TestAggregationStep_RunSequential_Success(t *testing.T) {
 	aggregation := NewAggregationStep("test_aggregation").
 		WithParallel(false).
@@ -461,6 +488,26 @@ func TestAggregationStep_Timeout(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAggregationStep_SequentialTimeout(t *testing.T) {
+	slowStep := NewMockStep("slow_step")
+	slowStep.On("Run", mock.Anything).Run(func(args mock.Arguments) {
+		time.Sleep(60 * time.Millisecond)
+	}).Return(nil)
+	nextStep := NewMockStep("next_step")
+
+	aggregation := NewAggregationStep("timeout_test").
+		WithTimeout(30 * time.Millisecond).
+		WithParallel(false).
+		AddStep(slowStep).
+		AddStep(nextStep)
+
+	err := aggregation.Run(flow.NewContext().WithFlowName("test_flow"))
+
+	assert.Equal(t, errors.ErrorTypeTimeout, errors.GetErrorType(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	nextStep.AssertNotCalled(t, "Run", mock.Anything)
+}
+
 func TestAggregationStep_FluentAPI(t *testing.T) {
 	mockTransformer := &MockTransformer{}
 	step1 := NewMockStep("step1")
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/http"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/transformers"
@@ -104,7 +104,7 @@ func (m *MobileAPIStep) SaveAs(key string) *MobileAPIStep {
 }
 
 // Run executes the mobile API step with BFF optimizations
-func (m *MobileAPIStep) Run(ctx *flow.Context) error {
+func (m *MobileAPIStep) Run(ctx interfaces.ExecutionContext) error {
 	startTime := time.Now()
 	m.metrics.RequestCount++
 
@@ -171,7 +171,7 @@ func (m *MobileAPIStep) Run(ctx *flow.Context) error {
 
 // Helper methods
 
-func (m *MobileAPIStep) checkCache(ctx *flow.Context) (map[string]interface{}, bool) {
+func (m *MobileAPIStep) checkCache(ctx interfaces.ExecutionContext) (map[string]interface{}, bool) {
 	cacheData, exists := ctx.Get("cache_" + m.cacheKey)
 	if !exists {
 		return nil, false
@@ -188,7 +188,7 @@ func (m *MobileAPIStep) checkCache(ctx *flow.Context) (map[string]interface{}, b
 	return nil, false
 }
 
-func (m *MobileAPIStep) cacheResponse(ctx *flow.Context) {
+func (m *MobileAPIStep) cacheResponse(ctx interfaces.ExecutionContext) {
 	if responseData, exists := ctx.Get("mobile_response"); exists {
 		cacheData := map[string]interface{}{
 			"data":      responseData,
@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
-	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/http"
 )
 
@@ -34,7 +34,7 @@ func (m *MockHTTPStep) Description() string {
 	return m.description
 }
 
-func (m *MockHTTPStep) Run(ctx *flow.Context) error {
+func (m *MockHTTPStep) Run(ctx interfaces.ExecutionContext) error {
 	args := m.Called(ctx)
 
 	// Simulate setting response data
@@ -46,7 +46,7 @@ func (m *MockHTTPStep) Run(ctx *flow.Context) error {
 	return args.Error(0)
 }
 
-func (m *MockHTTPStep) SetTimeout(timeout time.Duration) base.Step {
+func (m *MockHTTPStep) SetTimeout(timeout time.Duration) interfaces.Step {
 	return m
 }
 
@@ -0,0 +1,44 @@
+package bff
+
+import (
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+// MobileAPIConfig configures a "mobile_api" step created through the step registry
+type MobileAPIConfig struct {
+	Name           string        `json:"name,omitempty" default:"mobile_api"`
+	Method         string        `json:"method,omitempty" default:"GET"`
+	URL            string        `json:"url"`
+	Fields         []string      `json:"fields,omitempty" default:"[]" description:"Fields kept in the mobile response"`
+	SaveAs         string        `json:"save_as,omitempty" default:""`
+	CacheKey       string        `json:"cache_key,omitempty" default:"" description:"Enables response caching when set"`
+	CacheTTL       time.Duration `json:"cache_ttl,omitempty" default:"5m"`
+	AuthTokenField string        `json:"auth_token_field,omitempty" default:"" description:"Context field holding the bearer token"`
+}
+
+// RegisterSteps registers the BFF steps with reg
+func RegisterSteps(reg *registry.StepRegistry) error {
+	return reg.RegisterWithReflection("mobile_api", "Mobile-optimized API call", "bff", "v1", newMobileAPIStep, MobileAPIConfig{})
+}
+
+func newMobileAPIStep(config map[string]interface{}) (interfaces.Step, error) {
+	var cfg MobileAPIConfig
+	if err := registry.DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+
+	step := NewMobileAPIStep(cfg.Name, cfg.Method, cfg.URL, cfg.Fields)
+	if cfg.SaveAs != "" {
+		step.SaveAs(cfg.SaveAs)
+	}
+	if cfg.CacheKey != "" {
+		step.WithCaching(cfg.CacheKey, cfg.CacheTTL)
+	}
+	if cfg.AuthTokenField != "" {
+		step.WithAuth(cfg.AuthTokenField)
+	}
+	return step, nil
+}
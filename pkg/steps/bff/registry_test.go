@@ -0,0 +1,47 @@
+package bff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+func TestRegisterSteps(t *testing.T) {
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("mobile_api", map[string]interface{}{
+		"name":      "profile",
+		"url":       "http://example.com/users/${user_id}",
+		"fields":    []interface{}{"id", "name"},
+		"cache_key": "profile",
+		"cache_ttl": "1m",
+	})
+	require.NoError(t, err)
+
+	mobile, ok := step.(*MobileAPIStep)
+	require.True(t, ok)
+	assert.Equal(t, "profile", mobile.Name())
+	assert.Equal(t, []string{"id", "name"}, mobile.fields)
+	assert.Equal(t, "profile", mobile.cacheKey)
+	assert.Equal(t, time.Minute, mobile.cacheTTL)
+}
+
+func TestRegisterSteps_Defaults(t *testing.T) {
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("mobile_api", map[string]interface{}{"url": "http://example.com"})
+	require.NoError(t, err)
+
+	mobile := step.(*MobileAPIStep)
+	assert.Equal(t, "mobile_api", mobile.Name())
+	assert.Empty(t, mobile.cacheKey)
+	assert.Equal(t, 5*time.Minute, mobile.cacheTTL)
+
+	assert.Error(t, reg.ValidateConfig("mobile_api", map[string]interface{}{"method": "GET"}))
+}
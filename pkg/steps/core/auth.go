@@ -5,7 +5,7 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
 	"go.uber.org/zap"
@@ -60,7 +60,7 @@ func (tvs *TokenValidationStep) RemoveValidToken(token string) {
 	tvs.validTokens.Delete(token)
 }
 
-func (tvs *TokenValidationStep) Run(ctx *flow.Context) error {
+func (tvs *TokenValidationStep) Run(ctx interfaces.ExecutionContext) error {
 	// Extract token from headers
 	headers, ok := ctx.Get("headers")
 	if !ok {
@@ -181,7 +181,7 @@ func (hes *HeaderExtractionStep) WithSanitization(sanitize bool) *HeaderExtracti
 	return hes
 }
 
-func (hes *HeaderExtractionStep) Run(ctx *flow.Context) error {
+func (hes *HeaderExtractionStep) Run(ctx interfaces.ExecutionContext) error {
 	headers, ok := ctx.Get("headers")
 	if !ok {
 		return fmt.Errorf("no headers found in context")
@@ -10,6 +10,8 @@ import (
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -83,13 +85,20 @@ func (c *CacheStep) Run(ctx interfaces.ExecutionContext) error {
 	}
 }
 
+// setCacheHit records the outcome of a lookup in the context and on the
+// step's tracing span
+func setCacheHit(ctx interfaces.ExecutionContext, hit bool) {
+	ctx.Set("cache_hit", hit)
+	trace.SpanFromContext(ctx.Context()).SetAttributes(attribute.Bool("cache.hit", hit))
+}
+
 func (cs *CacheStep) handleGet(ctx interfaces.ExecutionContext, cacheKey string) error {
 	value, exists := cs.cache.Load(cacheKey)
 	if !exists {
 		ctx.Logger().Info("Cache miss",
 			zap.String("step", cs.Name()),
 			zap.String("cache_key", cacheKey))
-		ctx.Set("cache_hit", false)
+		setCacheHit(ctx, false)
 		return nil
 	}
 
@@ -100,7 +109,7 @@ func (cs *CacheStep) handleGet(ctx interfaces.ExecutionContext, cacheKey string)
 		ctx.Logger().Warn("Invalid cache entry removed",
 			zap.String("step", cs.Name()),
 			zap.String("cache_key", cacheKey))
-		ctx.Set("cache_hit", false)
+		setCacheHit(ctx, false)
 		return nil
 	}
 
@@ -111,7 +120,7 @@ func (cs *CacheStep) handleGet(ctx interfaces.ExecutionContext, cacheKey string)
 			zap.String("step", cs.Name()),
 			zap.String("cache_key", cacheKey),
 			zap.Time("expired_at", entry.ExpiresAt))
-		ctx.Set("cache_hit", false)
+		setCacheHit(ctx, false)
 		return nil
 	}
 
@@ -122,7 +131,7 @@ func (cs *CacheStep) handleGet(ctx interfaces.ExecutionContext, cacheKey string)
 	}
 
 	ctx.Set(targetField, entry.Value)
-	ctx.Set("cache_hit", true)
+	setCacheHit(ctx, true)
 	ctx.Set("cache_created_at", entry.CreatedAt)
 
 	ctx.Logger().Info("Cache hit",
@@ -6,6 +6,8 @@ import (
 
 	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 )
 
@@ -258,6 +260,37 @@ func TestCacheStep_HandleGet_Miss(t *testing.T) {
 	}
 }
 
+func TestCacheStep_HandleGet_SpanAttribute(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	step := NewCacheStep("test_cache").
+		WithOperation("get").
+		WithKey("test_key")
+
+	_, err := flow.NewFlow("cache_flow").
+		WithTracerProvider(provider).
+		Step("lookup", step).
+		Execute(flow.NewContext().WithLogger(zap.NewNop()))
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "step test_cache" {
+			continue
+		}
+		for _, attr := range span.Attributes {
+			if attr.Key == "cache.hit" {
+				if attr.Value.AsBool() {
+					t.Error("cache.hit should be false on a miss")
+				}
+				return
+			}
+		}
+	}
+	t.Error("cache.hit attribute should be set on the step span")
+}
+
 func TestCacheStep_HandleGet_Expired(t *testing.T) {
 	step := NewCacheStep("test_cache").
 		WithOperation("get").
@@ -6,7 +6,8 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/expr"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
 	"go.uber.org/zap"
@@ -15,10 +16,11 @@ import (
 // ConditionStep evaluates conditions and controls flow execution
 type ConditionStep struct {
 	*base.BaseStep
-	field     string
-	operator  string
-	value     interface{}
-	condition func(*flow.Context) bool
+	field      string
+	operator   string
+	value      interface{}
+	condition  func(interfaces.ExecutionContext) bool
+	expression *expr.Expression
 }
 
 // NewConditionStep creates a new condition step
@@ -31,13 +33,31 @@ func NewConditionStep(name, field, operator string, value interface{}) *Conditio
 	}
 }
 
+// NewExpressionCondition creates a condition step evaluating an expression
+// such as `user.tier == "gold" && len(cart.items) > 3`. The expression is
+// compiled once; see the expr package for the syntax.
+func NewExpressionCondition(name, expression string) (*ConditionStep, error) {
+	compiled, err := expr.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	return NewConditionStep(name, "", "", nil).WithExpression(compiled), nil
+}
+
+// WithExpression evaluates a compiled expression instead of the field
+// condition
+func (cs *ConditionStep) WithExpression(expression *expr.Expression) *ConditionStep {
+	cs.expression = expression
+	return cs
+}
+
 // WithCustomCondition sets a custom condition function
-func (cs *ConditionStep) WithCustomCondition(condition func(*flow.Context) bool) *ConditionStep {
+func (cs *ConditionStep) WithCustomCondition(condition func(interfaces.ExecutionContext) bool) *ConditionStep {
 	cs.condition = condition
 	return cs
 }
 
-func (cs *ConditionStep) Run(ctx *flow.Context) error {
+func (cs *ConditionStep) Run(ctx interfaces.ExecutionContext) error {
 	var result bool
 	var err error
 
@@ -47,6 +67,20 @@ func (cs *ConditionStep) Run(ctx *flow.Context) error {
 		ctx.Logger().Info("Custom condition evaluated",
 			zap.String("step", cs.Name()),
 			zap.Bool("result", result))
+	} else if cs.expression != nil {
+		result, err = cs.expression.Bool(ctx)
+		if err != nil {
+			ctx.Logger().Error("Condition evaluation failed",
+				zap.String("step", cs.Name()),
+				zap.String("expression", cs.expression.String()),
+				zap.Error(err))
+			return err
+		}
+
+		ctx.Logger().Info("Expression condition evaluated",
+			zap.String("step", cs.Name()),
+			zap.String("expression", cs.expression.String()),
+			zap.Bool("result", result))
 	} else {
 		// Use field-based condition
 		result, err = cs.evaluateFieldCondition(ctx)
@@ -74,7 +108,19 @@ func (cs *ConditionStep) Run(ctx *flow.Context) error {
 	return nil
 }
 
-func (cs *ConditionStep) evaluateFieldCondition(ctx *flow.Context) (bool, error) {
+// Evaluate reports whether the condition holds for ctx without storing the
+// result in the context
+func (cs *ConditionStep) Evaluate(ctx interfaces.ExecutionContext) (bool, error) {
+	if cs.condition != nil {
+		return cs.condition(ctx), nil
+	}
+	if cs.expression != nil {
+		return cs.expression.Bool(ctx)
+	}
+	return cs.evaluateFieldCondition(ctx)
+}
+
+func (cs *ConditionStep) evaluateFieldCondition(ctx interfaces.ExecutionContext) (bool, error) {
 	// Get field value from context
 	var fieldValue interface{}
 	var exists bool
@@ -229,23 +275,8 @@ func (cs *ConditionStep) normalizeValue(value interface{}) interface{} {
 }
 
 func (cs *ConditionStep) toFloat64(value interface{}) (float64, bool) {
-	switch v := value.(type) {
-	case int:
-		return float64(v), true
-	case int32:
-		return float64(v), true
-	case int64:
-		return float64(v), true
-	case float32:
-		return float64(v), true
-	case float64:
-		return v, true
-	case string:
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			return f, true
-		}
-	}
-	return 0, false
+	f, err := utils.Convert[float64](value)
+	return f, err == nil
 }
 
 func (cs *ConditionStep) stringContains(fieldValue, expectedValue interface{}) bool {
@@ -282,6 +313,21 @@ func (cs *ConditionStep) valueInList(fieldValue, expectedValue interface{}) bool
 	return false
 }
 
+// IsSupportedOperator reports whether operator is understood by field-based
+// condition steps
+func IsSupportedOperator(operator string) bool {
+	switch strings.ToLower(operator) {
+	case "exists", "not_exists", "!exists", "empty", "not_empty", "!empty",
+		"equals", "eq", "==", "not_equals", "ne", "!=",
+		"greater_than", "gt", ">", "greater_equal", "gte", ">=",
+		"less_than", "lt", "<", "less_equal", "lte", "<=",
+		"contains", "not_contains", "!contains", "starts_with", "ends_with",
+		"in", "not_in", "!in":
+		return true
+	}
+	return false
+}
+
 // Helper functions for creating common conditions
 
 // NewExistsCondition creates a condition that checks if a field exists
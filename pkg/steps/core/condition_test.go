@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 )
 
 func TestNewConditionStep(t *testing.T) {
@@ -21,7 +23,7 @@ func TestNewConditionStep(t *testing.T) {
 }
 
 func TestConditionStep_WithCustomCondition(t *testing.T) {
-	customCondition := func(ctx *flow.Context) bool {
+	customCondition := func(ctx interfaces.ExecutionContext) bool {
 		return true
 	}
 
@@ -32,7 +34,7 @@ func TestConditionStep_WithCustomCondition(t *testing.T) {
 }
 
 func TestConditionStep_Run_CustomCondition(t *testing.T) {
-	customCondition := func(ctx *flow.Context) bool {
+	customCondition := func(ctx interfaces.ExecutionContext) bool {
 		value, _ := ctx.Get("test_field")
 		return value == "expected"
 	}
@@ -563,3 +565,47 @@ func TestConditionStep_Run_AlternativeOperatorNames(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionStep_Evaluate(t *testing.T) {
+	ctx := flow.NewContext()
+	ctx.Set("status", "active")
+
+	result, err := NewConditionStep("test", "status", "equals", "active").Evaluate(ctx)
+	assert.NoError(t, err)
+	assert.True(t, result)
+	_, exists := ctx.Get("condition_test")
+	assert.False(t, exists, "Evaluate should not store the result")
+
+	_, err = NewConditionStep("test", "status", "bogus", nil).Evaluate(ctx)
+	assert.Error(t, err)
+}
+
+func TestIsSupportedOperator(t *testing.T) {
+	for _, op := range []string{"exists", "eq", "!=", "gte", "contains", "not_in"} {
+		assert.True(t, IsSupportedOperator(op), op)
+	}
+	assert.False(t, IsSupportedOperator("bogus"))
+	assert.False(t, IsSupportedOperator(""))
+}
+
+func TestNewExpressionCondition(t *testing.T) {
+	ctx := flow.NewContext()
+	ctx.Set("user", map[string]interface{}{"tier": "gold", "age": 32})
+
+	step, err := NewExpressionCondition("adult_gold", `user.tier == "gold" && user.age >= 18`)
+	require.NoError(t, err)
+	require.NoError(t, step.Run(ctx))
+	result, _ := ctx.Get("condition_adult_gold")
+	assert.Equal(t, true, result)
+
+	ok, err := step.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = NewExpressionCondition("bad", `user.tier ==`)
+	assert.Error(t, err)
+
+	failing, err := NewExpressionCondition("failing", `user.tier * 2`)
+	require.NoError(t, err)
+	assert.Error(t, failing.Run(ctx))
+}
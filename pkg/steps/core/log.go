@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
 	"go.uber.org/zap"
@@ -60,7 +60,7 @@ func (ls *LogStep) WithContext(include bool, keys ...string) *LogStep {
 	return ls
 }
 
-func (ls *LogStep) Run(ctx *flow.Context) error {
+func (ls *LogStep) Run(ctx interfaces.ExecutionContext) error {
 	// Interpolate message
 	logMessage, err := utils.InterpolateString(ls.message, ctx)
 	if err != nil {
@@ -96,7 +96,7 @@ func (ls *LogStep) Run(ctx *flow.Context) error {
 	return nil
 }
 
-func (ls *LogStep) prepareLogFields(ctx *flow.Context) []zap.Field {
+func (ls *LogStep) prepareLogFields(ctx interfaces.ExecutionContext) []zap.Field {
 	var fields []zap.Field
 
 	// Add step name
@@ -127,7 +127,7 @@ func (ls *LogStep) prepareLogFields(ctx *flow.Context) []zap.Field {
 	return fields
 }
 
-func (ls *LogStep) getContextData(ctx *flow.Context) map[string]interface{} {
+func (ls *LogStep) getContextData(ctx interfaces.ExecutionContext) map[string]interface{} {
 	result := make(map[string]interface{})
 
 	if len(ls.contextKeys) > 0 {
@@ -214,7 +214,7 @@ func (mls *MetricsLogStep) WithStats(include bool) *MetricsLogStep {
 	return mls
 }
 
-func (mls *MetricsLogStep) Run(ctx *flow.Context) error {
+func (mls *MetricsLogStep) Run(ctx interfaces.ExecutionContext) error {
 	// Interpolate metric value
 	var metricValue interface{} = 1 // Default value for counters
 	if mls.metricValue != "" {
@@ -261,7 +261,7 @@ func (mls *MetricsLogStep) Run(ctx *flow.Context) error {
 	return nil
 }
 
-func (mls *MetricsLogStep) gatherStats(ctx *flow.Context) map[string]interface{} {
+func (mls *MetricsLogStep) gatherStats(ctx interfaces.ExecutionContext) map[string]interface{} {
 	stats := make(map[string]interface{})
 
 	// Add context size
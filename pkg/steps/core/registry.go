@@ -0,0 +1,227 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+// Config types for the core steps registered by RegisterSteps. Fields with a
+// default tag are optional.
+
+// SetValueConfig configures a "set_value" step
+type SetValueConfig struct {
+	Name      string      `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Target    string      `json:"target" description:"Context key to set (dot notation sets nested fields)"`
+	Value     interface{} `json:"value,omitempty" default:"" description:"Value to set; strings are interpolated"`
+	ValueType string      `json:"value_type,omitempty" default:"json" description:"string, int, float, bool or json (keep as-is)"`
+}
+
+// CopyValueConfig configures "copy_value" and "move_value" steps
+type CopyValueConfig struct {
+	Name      string `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Source    string `json:"source" description:"Context key to read"`
+	Target    string `json:"target" description:"Context key to write"`
+	ValueType string `json:"value_type,omitempty" default:"json" description:"string, int, float, bool or json (keep as-is)"`
+}
+
+// DeleteValueConfig configures a "delete_value" step
+type DeleteValueConfig struct {
+	Name  string `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Field string `json:"field" description:"Context key to delete"`
+}
+
+// LogConfig configures a "log" step
+type LogConfig struct {
+	Name           string            `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Level          string            `json:"level,omitempty" default:"info" description:"debug, info, warn or error"`
+	Message        string            `json:"message" description:"Message template"`
+	Fields         map[string]string `json:"fields,omitempty" default:"{}" description:"Extra fields; values are interpolated"`
+	IncludeContext bool              `json:"include_context,omitempty" default:"false"`
+	ContextKeys    []string          `json:"context_keys,omitempty" default:"[]"`
+	Sanitize       bool              `json:"sanitize,omitempty" default:"true"`
+}
+
+// ConditionConfig configures a "condition" step
+type ConditionConfig struct {
+	Name     string      `json:"name,omitempty" default:"condition" description:"Result is stored as condition_<name>"`
+	Field    string      `json:"field,omitempty" default:"" description:"Context field to evaluate"`
+	Operator string      `json:"operator,omitempty" default:"" description:"Comparison operator, e.g. equals, gt, in"`
+	Value    interface{} `json:"value,omitempty" default:""`
+	Expr     string      `json:"expr,omitempty" default:"" description:"Expression evaluated instead of field/operator/value"`
+}
+
+// TokenValidationConfig configures a "token_validation" step
+type TokenValidationConfig struct {
+	Name          string   `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Header        string   `json:"header,omitempty" default:"Authorization"`
+	TokenPrefix   string   `json:"token_prefix,omitempty" default:"Bearer "`
+	Tokens        []string `json:"tokens,omitempty" default:"[]" description:"Whitelisted tokens"`
+	ExtractClaims bool     `json:"extract_claims,omitempty" default:"false"`
+}
+
+// HeaderExtractionConfig configures a "header_extraction" step
+type HeaderExtractionConfig struct {
+	Name     string   `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Headers  []string `json:"headers" description:"Headers to copy from the request"`
+	Required []string `json:"required,omitempty" default:"[]"`
+	Sanitize bool     `json:"sanitize,omitempty" default:"true"`
+}
+
+// RequiredFieldsConfig configures a "required_fields" validation step
+type RequiredFieldsConfig struct {
+	Name            string   `json:"name,omitempty" default:"" description:"Step name; defaults to the step type"`
+	Fields          []string `json:"fields"`
+	DataField       string   `json:"data_field,omitempty" default:"" description:"Map to validate; empty validates the whole context"`
+	ContinueOnError bool     `json:"continue_on_error,omitempty" default:"false"`
+}
+
+// RegisterSteps registers the core steps with reg so they can be created by
+// name, e.g. from a declarative flow definition
+func RegisterSteps(reg *registry.StepRegistry) error {
+	steps := []struct {
+		name, description string
+		factory           func(name string) registry.StepFactory
+		config            interface{}
+	}{
+		{"set_value", "Set a context value", newSetValueStep, SetValueConfig{}},
+		{"copy_value", "Copy a context value", newCopyValueStep("copy"), CopyValueConfig{}},
+		{"move_value", "Move a context value", newCopyValueStep("move"), CopyValueConfig{}},
+		{"delete_value", "Delete a context value", newDeleteValueStep, DeleteValueConfig{}},
+		{"log", "Log a message", newLogStep, LogConfig{}},
+		{"condition", "Evaluate a field condition", newConditionStep, ConditionConfig{}},
+		{"token_validation", "Validate a bearer token", newTokenValidationStep, TokenValidationConfig{}},
+		{"header_extraction", "Extract request headers", newHeaderExtractionStep, HeaderExtractionConfig{}},
+		{"required_fields", "Validate required fields", newRequiredFieldsStep, RequiredFieldsConfig{}},
+	}
+
+	// Each factory is given the name it is registered under, used for the
+	// steps it creates unless their config names them
+	for _, s := range steps {
+		if err := reg.RegisterWithReflection(s.name, s.description, "core", "v1", s.factory(s.name), s.config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepName returns the name set in a step's config, or the name its type is
+// registered under
+func stepName(configured, registered string) string {
+	if configured != "" {
+		return configured
+	}
+	return registered
+}
+
+func newSetValueStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg SetValueConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return NewSetValueStep(stepName(cfg.Name, name), cfg.Target, cfg.Value).WithValueType(cfg.ValueType), nil
+	}
+}
+
+func newCopyValueStep(operation string) func(name string) registry.StepFactory {
+	return func(name string) registry.StepFactory {
+		return func(config map[string]interface{}) (interfaces.Step, error) {
+			var cfg CopyValueConfig
+			if err := registry.DecodeConfig(config, &cfg); err != nil {
+				return nil, err
+			}
+			return NewValueStep(stepName(cfg.Name, name), operation).
+				WithSource(cfg.Source).
+				WithTarget(cfg.Target).
+				WithValueType(cfg.ValueType), nil
+		}
+	}
+}
+
+func newDeleteValueStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg DeleteValueConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return NewDeleteValueStep(stepName(cfg.Name, name), cfg.Field), nil
+	}
+}
+
+func newLogStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg LogConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return NewLogStep(stepName(cfg.Name, name), cfg.Level, cfg.Message).
+			WithFields(cfg.Fields).
+			WithSanitization(cfg.Sanitize).
+			WithContext(cfg.IncludeContext, cfg.ContextKeys...), nil
+	}
+}
+
+func newConditionStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg ConditionConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		cfg.Name = stepName(cfg.Name, name)
+		if cfg.Expr != "" {
+			if cfg.Field != "" || cfg.Operator != "" {
+				return nil, fmt.Errorf("expr cannot be combined with field and operator")
+			}
+			return NewExpressionCondition(cfg.Name, cfg.Expr)
+		}
+		if cfg.Field == "" {
+			return nil, fmt.Errorf("either field and operator or expr is required")
+		}
+		if !IsSupportedOperator(cfg.Operator) {
+			return nil, fmt.Errorf("unsupported operator: %s", cfg.Operator)
+		}
+		return NewConditionStep(cfg.Name, cfg.Field, cfg.Operator, cfg.Value), nil
+	}
+}
+
+func newTokenValidationStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg TokenValidationConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		step := NewTokenValidationStep(stepName(cfg.Name, name), cfg.Header).
+			WithTokenPrefix(cfg.TokenPrefix).
+			WithClaimsExtraction(cfg.ExtractClaims)
+		for _, token := range cfg.Tokens {
+			step.AddValidToken(token)
+		}
+		return step, nil
+	}
+}
+
+func newHeaderExtractionStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg HeaderExtractionConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return NewHeaderExtractionStep(stepName(cfg.Name, name), cfg.Headers...).
+			WithRequired(cfg.Required...).
+			WithSanitization(cfg.Sanitize), nil
+	}
+}
+
+func newRequiredFieldsStep(name string) registry.StepFactory {
+	return func(config map[string]interface{}) (interfaces.Step, error) {
+		var cfg RequiredFieldsConfig
+		if err := registry.DecodeConfig(config, &cfg); err != nil {
+			return nil, err
+		}
+		return NewRequiredFieldsValidationStep(stepName(cfg.Name, name), cfg.Fields...).
+			WithDataField(cfg.DataField).
+			WithContinueOnError(cfg.ContinueOnError), nil
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+func newCoreRegistry(t *testing.T) *registry.StepRegistry {
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+	return reg
+}
+
+func TestRegisterSteps(t *testing.T) {
+	reg := newCoreRegistry(t)
+
+	for _, name := range []string{
+		"set_value", "copy_value", "move_value", "delete_value", "log",
+		"condition", "token_validation", "header_extraction", "required_fields",
+	} {
+		info, err := reg.GetInfo(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, "core", info.Category)
+	}
+
+	// Registering twice reports the duplicate
+	assert.Error(t, RegisterSteps(reg))
+}
+
+func TestRegisterSteps_CreateAndRun(t *testing.T) {
+	reg := newCoreRegistry(t)
+	ctx := flow.NewContext().WithLogger(zap.NewNop())
+
+	set, err := reg.Create("set_value", map[string]interface{}{
+		"target": "user",
+		"value":  map[string]interface{}{"id": 7},
+	})
+	require.NoError(t, err)
+	require.NoError(t, set.Run(ctx))
+
+	copyStep, err := reg.Create("copy_value", map[string]interface{}{"source": "user", "target": "profile"})
+	require.NoError(t, err)
+	require.NoError(t, copyStep.Run(ctx))
+
+	profile, ok := ctx.Get("profile")
+	require.True(t, ok)
+	assert.Equal(t, map[string]interface{}{"id": 7}, profile)
+
+	cond, err := reg.Create("condition", map[string]interface{}{
+		"name": "has_user", "field": "user", "operator": "exists",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cond.Run(ctx))
+	matched, err := ctx.GetBool("condition_has_user")
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	exprCond, err := reg.Create("condition", map[string]interface{}{
+		"name": "big_user", "expr": "user.id > 5 && len(profile) == 1",
+	})
+	require.NoError(t, err)
+	require.NoError(t, exprCond.Run(ctx))
+	matched, err = ctx.GetBool("condition_big_user")
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	del, err := reg.Create("delete_value", map[string]interface{}{"field": "profile"})
+	require.NoError(t, err)
+	require.NoError(t, del.Run(ctx))
+	assert.False(t, ctx.Has("profile"))
+
+	logStep, err := reg.Create("log", map[string]interface{}{"message": "user ${user.id}"})
+	require.NoError(t, err)
+	assert.NoError(t, logStep.Run(ctx))
+}
+
+func TestRegisterSteps_StepNames(t *testing.T) {
+	reg := newCoreRegistry(t)
+
+	move, err := reg.Create("move_value", map[string]interface{}{"source": "a", "target": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "move_value", move.Name())
+
+	named, err := reg.Create("log", map[string]interface{}{"name": "audit", "message": "done"})
+	require.NoError(t, err)
+	assert.Equal(t, "audit", named.Name())
+}
+
+func TestRegisterSteps_InvalidConfig(t *testing.T) {
+	reg := newCoreRegistry(t)
+
+	_, err := reg.Create("condition", map[string]interface{}{"field": "x", "operator": "resembles"})
+	assert.Error(t, err)
+	_, err = reg.Create("condition", map[string]interface{}{"expr": "x =="})
+	assert.Error(t, err)
+	_, err = reg.Create("condition", map[string]interface{}{"expr": "x", "field": "x", "operator": "exists"})
+	assert.Error(t, err)
+	_, err = reg.Create("condition", map[string]interface{}{})
+	assert.Error(t, err)
+
+	_, err = reg.Create("header_extraction", map[string]interface{}{"headers": "X-Request-ID"})
+	assert.Error(t, err)
+
+	err = reg.ValidateConfig("set_value", map[string]interface{}{"value": 1})
+	assert.Error(t, err)
+	assert.NoError(t, reg.ValidateConfig("set_value", map[string]interface{}{"target": "x"}))
+}
@@ -3,7 +3,7 @@ package core
 import (
 	"fmt"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/validators"
 	"go.uber.org/zap"
@@ -52,7 +52,7 @@ func (vs *ValidationStep) WithResultStorage(store bool, field string) *Validatio
 	return vs
 }
 
-func (vs *ValidationStep) Run(ctx *flow.Context) error {
+func (vs *ValidationStep) Run(ctx interfaces.ExecutionContext) error {
 	// Get data to validate
 	dataToValidate, err := vs.getDataToValidate(ctx)
 	if err != nil {
@@ -102,7 +102,7 @@ func (vs *ValidationStep) Run(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValidationStep) getDataToValidate(ctx *flow.Context) (map[string]interface{}, error) {
+func (vs *ValidationStep) getDataToValidate(ctx interfaces.ExecutionContext) (map[string]interface{}, error) {
 	if vs.dataField == "" {
 		// Validate entire context
 		result := make(map[string]interface{})
@@ -203,7 +203,7 @@ func (vcs *ValidationChainStep) WithResultStorage(store bool, field string) *Val
 	return vcs
 }
 
-func (vcs *ValidationChainStep) Run(ctx *flow.Context) error {
+func (vcs *ValidationChainStep) Run(ctx interfaces.ExecutionContext) error {
 	// Get data to validate
 	dataToValidate, err := vcs.getDataToValidate(ctx)
 	if err != nil {
@@ -293,7 +293,7 @@ func (vcs *ValidationChainStep) Run(ctx *flow.Context) error {
 	return nil
 }
 
-func (vcs *ValidationChainStep) getDataToValidate(ctx *flow.Context) (map[string]interface{}, error) {
+func (vcs *ValidationChainStep) getDataToValidate(ctx interfaces.ExecutionContext) (map[string]interface{}, error) {
 	if vcs.dataField == "" {
 		// Validate entire context
 		result := make(map[string]interface{})
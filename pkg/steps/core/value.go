@@ -5,7 +5,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
 	"go.uber.org/zap"
@@ -61,7 +61,7 @@ func (vs *ValueStep) WithTransform(transform func(interface{}) interface{}) *Val
 	return vs
 }
 
-func (vs *ValueStep) Run(ctx *flow.Context) error {
+func (vs *ValueStep) Run(ctx interfaces.ExecutionContext) error {
 	switch vs.operation {
 	case "set":
 		return vs.handleSet(ctx)
@@ -78,7 +78,7 @@ func (vs *ValueStep) Run(ctx *flow.Context) error {
 	}
 }
 
-func (vs *ValueStep) handleSet(ctx *flow.Context) error {
+func (vs *ValueStep) handleSet(ctx interfaces.ExecutionContext) error {
 	if vs.targetField == "" {
 		return fmt.Errorf("target field is required for set operation")
 	}
@@ -110,7 +110,7 @@ func (vs *ValueStep) handleSet(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValueStep) handleCopy(ctx *flow.Context) error {
+func (vs *ValueStep) handleCopy(ctx interfaces.ExecutionContext) error {
 	if vs.sourceField == "" || vs.targetField == "" {
 		return fmt.Errorf("both source and target fields are required for copy operation")
 	}
@@ -142,7 +142,7 @@ func (vs *ValueStep) handleCopy(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValueStep) handleMove(ctx *flow.Context) error {
+func (vs *ValueStep) handleMove(ctx interfaces.ExecutionContext) error {
 	if vs.sourceField == "" || vs.targetField == "" {
 		return fmt.Errorf("both source and target fields are required for move operation")
 	}
@@ -186,7 +186,7 @@ func (vs *ValueStep) handleMove(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValueStep) handleDelete(ctx *flow.Context) error {
+func (vs *ValueStep) handleDelete(ctx interfaces.ExecutionContext) error {
 	field := vs.targetField
 	if field == "" {
 		field = vs.sourceField
@@ -215,7 +215,7 @@ func (vs *ValueStep) handleDelete(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValueStep) handleTransform(ctx *flow.Context) error {
+func (vs *ValueStep) handleTransform(ctx interfaces.ExecutionContext) error {
 	if vs.sourceField == "" {
 		return fmt.Errorf("source field is required for transform operation")
 	}
@@ -259,7 +259,7 @@ func (vs *ValueStep) handleTransform(ctx *flow.Context) error {
 	return nil
 }
 
-func (vs *ValueStep) getFieldValue(ctx *flow.Context, field string) (interface{}, error) {
+func (vs *ValueStep) getFieldValue(ctx interfaces.ExecutionContext, field string) (interface{}, error) {
 	if strings.Contains(field, ".") {
 		// Handle nested field access
 		value := utils.GetNestedValue(field, ctx)
@@ -277,7 +277,7 @@ func (vs *ValueStep) getFieldValue(ctx *flow.Context, field string) (interface{}
 	}
 }
 
-func (vs *ValueStep) setNestedValue(ctx *flow.Context, field string, value interface{}) error {
+func (vs *ValueStep) setNestedValue(ctx interfaces.ExecutionContext, field string, value interface{}) error {
 	// For nested field setting, we need to get the root object and modify it
 	parts := strings.Split(field, ".")
 	if len(parts) < 2 {
@@ -303,7 +303,7 @@ func (vs *ValueStep) setNestedValue(ctx *flow.Context, field string, value inter
 	return utils.SetNestedValue(rootMap, nestedKey, value)
 }
 
-func (vs *ValueStep) processValue(ctx *flow.Context, value interface{}) (interface{}, error) {
+func (vs *ValueStep) processValue(ctx interfaces.ExecutionContext, value interface{}) (interface{}, error) {
 	// Handle string interpolation
 	if strValue, ok := value.(string); ok {
 		interpolated, err := utils.InterpolateString(strValue, ctx)
@@ -1,18 +1,24 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
+	"net"
 	"net/http"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/failsafe-go/failsafe-go"
+	"github.com/failsafe-go/failsafe-go/bulkhead"
 	"github.com/failsafe-go/failsafe-go/circuitbreaker"
 	"github.com/failsafe-go/failsafe-go/failsafehttp"
 	"github.com/failsafe-go/failsafe-go/fallback"
 	"github.com/failsafe-go/failsafe-go/retrypolicy"
 	"github.com/failsafe-go/failsafe-go/timeout"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
 )
 
 // HTTPClient interface for dependency injection and testing
@@ -21,11 +27,21 @@ type HTTPClient interface {
 	DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error)
 }
 
+// CircuitBreakerStateMetric is the gauge of each upstream's circuit breaker
+// state, tagged with the upstream name: 0 closed, 1 half-open, 2 open
+const CircuitBreakerStateMetric = "http_circuit_breaker_state"
+
 // ResilientHTTPClient provides HTTP client with comprehensive resilience patterns
 type ResilientHTTPClient struct {
+	name           string
 	baseClient     *http.Client
+	policies       []failsafe.Policy[*http.Response]
+	singlePolicies []failsafe.Policy[*http.Response]
+	retryBudget    *retryBudget
 	retryPolicy    retrypolicy.RetryPolicy[*http.Response]
 	circuitBreaker circuitbreaker.CircuitBreaker[*http.Response]
+	bulkhead       bulkhead.Bulkhead[*http.Response]
+	rateLimiter    *rateLimiter
 	timeoutPolicy  timeout.Timeout[*http.Response]
 	fallbackPolicy fallback.Fallback[*http.Response]
 	config         *ClientConfig
@@ -49,6 +65,19 @@ type ClientConfig struct {
 	RetryJitter          bool
 	RetryableStatusCodes []int
 
+	// Only idempotent requests are retried, unless RetryNonIdempotent is
+	// set or they carry an Idempotency-Key header, which AutoIdempotencyKey
+	// adds to every non-idempotent request
+	RetryNonIdempotent bool
+	AutoIdempotencyKey bool
+
+	// Retry budget: once retries reach RetryBudgetRatio of the requests
+	// sent in the last RetryBudgetWindow, and at least RetryBudgetMinRetries,
+	// failed requests are no longer retried; a zero ratio disables it
+	RetryBudgetRatio      float64
+	RetryBudgetMinRetries int
+	RetryBudgetWindow     time.Duration
+
 	// Circuit breaker settings
 	FailureThreshold    uint
 	SuccessThreshold    uint
@@ -66,10 +95,30 @@ type ClientConfig struct {
 	FallbackBody       []byte
 	FallbackHeaders    map[string]string
 
-	// Rate limiting
+	// Bulkhead settings: at most MaxConcurrent requests run at once, and
+	// requests wait up to MaxWaitTime for a slot before failing; 0 disables
+	// the bulkhead
+	MaxConcurrent int
+	MaxWaitTime   time.Duration
+
+	// Hedging: idempotent requests not answered within HedgeDelay are sent
+	// again, up to MaxHedges more times, and the first successful response
+	// is used; 0 disables hedging
+	HedgeDelay time.Duration
+	MaxHedges  int
+
+	// Rate limiting: a token bucket of BurstSize tokens refilled at
+	// RequestsPerSecond, shared by the client or, with RateLimitPerHost, one
+	// per request host. Every attempt, retries and hedges included, waits for
+	// a token unless it would not arrive before the request's context
+	// deadline; with RateLimitFailFast it never waits.
+	// Requests getting no token fail with RATE_LIMIT_EXCEEDED, and a 429
+	// response holds back the host's tokens for its Retry-After.
 	EnableRateLimit   bool
 	RequestsPerSecond int
 	BurstSize         int
+	RateLimitPerHost  bool
+	RateLimitFailFast bool
 
 	// Metrics and monitoring
 	EnableMetrics bool
@@ -95,6 +144,11 @@ func DefaultClientConfig() *ClientConfig {
 		RetryJitter:          true,
 		RetryableStatusCodes: []int{429, 502, 503, 504},
 
+		// Retry budget settings
+		RetryBudgetRatio:      0.2,
+		RetryBudgetMinRetries: 10,
+		RetryBudgetWindow:     10 * time.Second,
+
 		// Circuit breaker settings
 		FailureThreshold:    5,
 		SuccessThreshold:    3,
@@ -123,14 +177,22 @@ func DefaultClientConfig() *ClientConfig {
 	}
 }
 
-// NewResilientHTTPClient creates a new HTTP client with comprehensive resilience
+// NewResilientHTTPClient creates a new HTTP client with comprehensive resilience.
+// A zero RequestTimeout or FailureThreshold disables the timeout policy or
+// the circuit breaker.
 func NewResilientHTTPClient(config *ClientConfig) *ResilientHTTPClient {
+	return newResilientHTTPClient("", config)
+}
+
+// newResilientHTTPClient creates a client for the named upstream. Named
+// clients export their circuit breaker state as CircuitBreakerStateMetric.
+func newResilientHTTPClient(name string, config *ClientConfig) *ResilientHTTPClient {
 	if config == nil {
 		config = DefaultClientConfig()
 	}
 
 	// Create base HTTP client with optimized transport
-	transport := &http.Transport{
+	baseTransport := &http.Transport{
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
@@ -139,45 +201,167 @@ func NewResilientHTTPClient(config *ClientConfig) *ResilientHTTPClient {
 	}
 
 	// Create resilience policies
-	retryPolicy := createAdvancedRetryPolicy(config)
-	circuitBreaker := createAdvancedCircuitBreaker(config)
+	budget := newRetryBudget(config)
+	retryPolicy := createAdvancedRetryPolicy(name, config, budget)
+	circuitBreaker := createAdvancedCircuitBreaker(name, config)
+	bulkheadPolicy := createBulkhead(config)
 	timeoutPolicy := createAdvancedTimeoutPolicy(config)
 	fallbackPolicy := createAdvancedFallbackPolicy(config)
 
 	// Create failsafe RoundTripper with policy composition
 	// Only include non-nil policies
-	var policies []failsafe.Policy[*http.Response]
-	if fallbackPolicy != nil {
-		policies = append(policies, fallbackPolicy)
+	// A full bulkhead is not a failure of the upstream, so the bulkhead
+	// sits outside the circuit breaker
+	var inner []failsafe.Policy[*http.Response]
+	if bulkheadPolicy != nil {
+		inner = append(inner, bulkheadPolicy)
+	}
+	if circuitBreaker != nil {
+		inner = append(inner, circuitBreaker)
+	}
+	if timeoutPolicy != nil {
+		inner = append(inner, timeoutPolicy)
 	}
-	policies = append(policies, retryPolicy, circuitBreaker, timeoutPolicy)
 
-	roundTripper := failsafehttp.NewRoundTripper(transport, policies...)
+	// Requests that may not be retried share every policy but the retry
+	// policy. The fallback is applied per request by send, around either.
+	limiter := newRateLimiter(name, config)
+	transport := &bufferedTransport{next: baseTransport, limiter: limiter}
 
 	resilientClient := &ResilientHTTPClient{
+		name: name,
 		baseClient: &http.Client{
-			Transport: roundTripper,
+			Transport: transport,
 			Timeout:   config.BaseTimeout,
 		},
+		policies:       append([]failsafe.Policy[*http.Response]{retryPolicy}, inner...),
+		singlePolicies: inner,
+		retryBudget:    budget,
 		retryPolicy:    retryPolicy,
 		circuitBreaker: circuitBreaker,
+		bulkhead:       bulkheadPolicy,
+		rateLimiter:    limiter,
 		timeoutPolicy:  timeoutPolicy,
 		fallbackPolicy: fallbackPolicy,
 		config:         config,
 	}
 
+	if name != "" && circuitBreaker != nil {
+		recordBreakerState(name, circuitbreaker.ClosedState)
+	}
 	return resilientClient
 }
 
+// Name returns the upstream the client was created for, empty for clients
+// created with NewResilientHTTPClient
+func (c *ResilientHTTPClient) Name() string {
+	return c.name
+}
+
 // Do executes an HTTP request with resilience policies
 func (c *ResilientHTTPClient) Do(req *http.Request) (*http.Response, error) {
-	return c.baseClient.Do(req)
+	return c.DoWithContext(req.Context(), req)
 }
 
-// DoWithContext executes an HTTP request with context and resilience policies
+// DoWithContext executes an HTTP request with context and resilience policies.
+// With hedging, requests that are not idempotent are sent once and counted
+// in HedgeSkippedMetric.
 func (c *ResilientHTTPClient) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
-	req = req.WithContext(ctx)
-	return c.baseClient.Do(req)
+	req = c.withIdempotencyKey(req)
+	// The retry budget counts each request once, however many hedged
+	// copies are sent for it
+	c.retryBudget.recordRequest()
+	if h := c.hedgingFor(ctx); h.enabled() {
+		if isIdempotent(req) {
+			return c.hedge(ctx, req, h)
+		}
+		metrics.IncrementCounter(HedgeSkippedMetric, map[string]string{"upstream": c.name, "method": req.Method})
+	}
+	return c.send(ctx, req)
+}
+
+// send sends req through the resilience policies, bounded by BaseTimeout.
+// Non-idempotent requests are only retried with RetryNonIdempotent.
+func (c *ResilientHTTPClient) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.config.BaseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.BaseTimeout)
+		defer cancel()
+	}
+
+	policies := c.policies
+	if !c.config.RetryNonIdempotent && !isIdempotent(req) {
+		policies = c.singlePolicies
+	}
+	if fallbackPolicy := c.fallbackFor(ctx); fallbackPolicy != nil {
+		policies = append([]failsafe.Policy[*http.Response]{fallbackPolicy}, policies...)
+	}
+
+	// Every attempt runs on a context derived from ctx, so that the transport
+	// sees its deadline and values; failsafehttp would otherwise merge the
+	// request and execution contexts into a bare one
+	executor := failsafe.NewExecutor(policies...).WithContext(ctx)
+	return failsafehttp.NewRequestWithExecutor(req.WithContext(context.Background()), c.baseClient, executor).Do()
+}
+
+// fallbackKey is the context key of the fallbackOptions of a request
+type fallbackKey struct{}
+
+// fallbackOptions narrow the client's fallback for the requests sent with a
+// context
+type fallbackOptions struct {
+	disabled bool  // the request never falls back
+	keep     []int // statuses the fallback never replaces
+}
+
+// withFallbackOptions makes requests sent with ctx use opts
+func withFallbackOptions(ctx context.Context, opts fallbackOptions) context.Context {
+	return context.WithValue(ctx, fallbackKey{}, opts)
+}
+
+// fallbackFor returns the fallback policy for a request sent with ctx, or
+// nil when the request must not fall back
+func (c *ResilientHTTPClient) fallbackFor(ctx context.Context) fallback.Fallback[*http.Response] {
+	opts, _ := ctx.Value(fallbackKey{}).(fallbackOptions)
+	if c.fallbackPolicy == nil || opts.disabled {
+		return nil
+	}
+	if len(opts.keep) > 0 {
+		return createAdvancedFallbackPolicy(c.config, opts.keep...)
+	}
+	return c.fallbackPolicy
+}
+
+// bufferedTransport reads response bodies within the attempt that made the
+// request. The resilience policies cancel an attempt's context as soon as
+// it returns, which would break reading the body afterwards, and buffered
+// responses can be dropped safely when a policy retries or falls back.
+// Every attempt, retries and hedges included, also takes a token from the
+// rate limiter and shows it its response, so that a 429 on a retry still
+// slows the client down.
+type bufferedTransport struct {
+	next    http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t *bufferedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.acquire(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	t.limiter.observe(req, resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
 }
 
 // GetMetrics returns client metrics
@@ -192,6 +376,9 @@ func (c *ResilientHTTPClient) GetMetrics() map[string]interface{} {
 	}
 
 	// Basic metrics
+	if c.name != "" {
+		metrics["upstream"] = c.name
+	}
 	metrics["config"] = map[string]interface{}{
 		"max_retries":       c.config.MaxRetries,
 		"request_timeout":   c.config.RequestTimeout.String(),
@@ -201,6 +388,15 @@ func (c *ResilientHTTPClient) GetMetrics() map[string]interface{} {
 	return metrics
 }
 
+// CircuitBreakerState returns "closed", "open" or "half-open", or
+// "disabled" when the client has no circuit breaker
+func (c *ResilientHTTPClient) CircuitBreakerState() string {
+	if c.circuitBreaker == nil {
+		return "disabled"
+	}
+	return c.circuitBreaker.State().String()
+}
+
 // IsCircuitBreakerOpen returns true if circuit breaker is open
 func (c *ResilientHTTPClient) IsCircuitBreakerOpen() bool {
 	if c.circuitBreaker == nil {
@@ -217,11 +413,11 @@ func (c *ResilientHTTPClient) ResetCircuitBreaker() {
 }
 
 // createAdvancedRetryPolicy creates a sophisticated retry policy
-func createAdvancedRetryPolicy(config *ClientConfig) retrypolicy.RetryPolicy[*http.Response] {
-	builder := retrypolicy.Builder[*http.Response]().
+func createAdvancedRetryPolicy(name string, config *ClientConfig, budget *retryBudget) retrypolicy.RetryPolicy[*http.Response] {
+	return retrypolicy.Builder[*http.Response]().
 		HandleIf(func(response *http.Response, err error) bool {
 			if err != nil {
-				return true
+				return isRetryableError(err)
 			}
 			// Retry on specific status codes
 			for _, code := range config.RetryableStatusCodes {
@@ -231,24 +427,53 @@ func createAdvancedRetryPolicy(config *ClientConfig) retrypolicy.RetryPolicy[*ht
 			}
 			return false
 		}).
-		WithBackoff(config.InitialRetryDelay, config.MaxRetryDelay).
-		WithMaxRetries(config.MaxRetries)
+		AbortIf(func(*http.Response, error) bool {
+			if budget.allowRetry() {
+				return false
+			}
+			metrics.IncrementCounter(RetryBudgetExhaustedMetric, map[string]string{"upstream": name})
+			return true
+		}).
+		OnRetry(func(failsafe.ExecutionEvent[*http.Response]) {
+			budget.recordRetry()
+		}).
+		WithDelayFunc(retryDelayFunc(config)).
+		WithMaxRetries(config.MaxRetries).
+		ReturnLastFailure().
+		Build()
+}
 
-	if config.RetryJitter {
-		builder = builder.OnRetryScheduled(func(e failsafe.ExecutionScheduledEvent[*http.Response]) {
-			// Add jitter logic here if needed
-		})
+// isRetryableError reports whether a failed request may succeed when sent
+// again. Unknown hosts, open circuit breakers and callers that gave up are
+// not retried, and neither are requests rejected by a full bulkhead or the
+// rate limiter.
+func isRetryableError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
 	}
-
-	return builder.Build()
+	if errors.Is(err, circuitbreaker.ErrOpen) || errors.Is(err, bulkhead.ErrFull) || isRateLimited(err) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
 }
 
 // createAdvancedCircuitBreaker creates a sophisticated circuit breaker
-func createAdvancedCircuitBreaker(config *ClientConfig) circuitbreaker.CircuitBreaker[*http.Response] {
-	return circuitbreaker.Builder[*http.Response]().
+func createAdvancedCircuitBreaker(name string, config *ClientConfig) circuitbreaker.CircuitBreaker[*http.Response] {
+	if config.FailureThreshold == 0 {
+		return nil
+	}
+	builder := circuitbreaker.Builder[*http.Response]()
+	if name != "" {
+		builder = builder.OnStateChanged(func(e circuitbreaker.StateChangedEvent) {
+			recordBreakerState(name, e.NewState)
+		})
+	}
+	return builder.
 		HandleIf(func(response *http.Response, err error) bool {
 			if err != nil {
-				return true
+				// Throttled requests never reached the upstream
+				return !isRateLimited(err)
 			}
 			return response.StatusCode >= 500
 		}).
@@ -258,35 +483,65 @@ func createAdvancedCircuitBreaker(config *ClientConfig) circuitbreaker.CircuitBr
 		Build()
 }
 
-// createAdvancedTimeoutPolicy creates a comprehensive timeout policy
-func createAdvancedTimeoutPolicy(config *ClientConfig) timeout.Timeout[*http.Response] {
-	return timeout.Builder[*http.Response](config.RequestTimeout).Build()
+// recordBreakerState sets the circuit breaker gauge of an upstream
+func recordBreakerState(name string, state circuitbreaker.State) {
+	value := 0.0
+	switch state {
+	case circuitbreaker.HalfOpenState:
+		value = 1
+	case circuitbreaker.OpenState:
+		value = 2
+	}
+	metrics.GetGlobalMetrics().SetGauge(CircuitBreakerStateMetric, value, map[string]string{"upstream": name})
 }
 
-// createAdvancedFallbackPolicy creates a sophisticated fallback policy
-func createAdvancedFallbackPolicy(config *ClientConfig) fallback.Fallback[*http.Response] {
-	if !config.EnableFallback {
+// createBulkhead creates the bulkhead bounding concurrent requests, or nil
+// when MaxConcurrent is not set
+func createBulkhead(config *ClientConfig) bulkhead.Bulkhead[*http.Response] {
+	if config.MaxConcurrent <= 0 {
 		return nil
 	}
+	return bulkhead.Builder[*http.Response](uint(config.MaxConcurrent)).
+		WithMaxWaitTime(config.MaxWaitTime).
+		Build()
+}
 
-	// Create fallback response
-	fallbackResp := &http.Response{
-		StatusCode: config.FallbackStatusCode,
-		Header:     make(http.Header),
-		Body:       io.NopCloser(strings.NewReader(string(config.FallbackBody))),
+// createAdvancedTimeoutPolicy creates a comprehensive timeout policy
+func createAdvancedTimeoutPolicy(config *ClientConfig) timeout.Timeout[*http.Response] {
+	if config.RequestTimeout <= 0 {
+		return nil
 	}
+	return timeout.Builder[*http.Response](config.RequestTimeout).Build()
+}
 
-	// Set fallback headers
-	for key, value := range config.FallbackHeaders {
-		fallbackResp.Header.Set(key, value)
+// createAdvancedFallbackPolicy creates a sophisticated fallback policy.
+// Responses with a status in keep are returned as they are.
+func createAdvancedFallbackPolicy(config *ClientConfig, keep ...int) fallback.Fallback[*http.Response] {
+	if !config.EnableFallback {
+		return nil
 	}
 
-	return fallback.BuilderWithResult(fallbackResp).
+	// Each fallback gets its own response, since callers read and close
+	// the body
+	return fallback.BuilderWithFunc(func(exec failsafe.Execution[*http.Response]) (*http.Response, error) {
+		fallbackResp := &http.Response{
+			StatusCode:    config.FallbackStatusCode,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(bytes.NewReader(config.FallbackBody)),
+			ContentLength: int64(len(config.FallbackBody)),
+		}
+		for key, value := range config.FallbackHeaders {
+			fallbackResp.Header.Set(key, value)
+		}
+		return fallbackResp, nil
+	}).
 		HandleIf(func(response *http.Response, err error) bool {
 			if err != nil {
-				return true
+				// A caller that gave up does not want a fallback, and a
+				// throttled request never reached the upstream
+				return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !isRateLimited(err)
 			}
-			return response.StatusCode >= 500
+			return response.StatusCode >= 500 && !slices.Contains(keep, response.StatusCode)
 		}).
 		Build()
 }
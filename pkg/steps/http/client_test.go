@@ -2,11 +2,17 @@ package http
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/failsafe-go/failsafe-go/circuitbreaker"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -309,3 +315,82 @@ func TestResilientHTTPClient_HTTPSRequest(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestResilientHTTPClient_LargeBody(t *testing.T) {
+	// The body is read after the policies finished the attempt
+	body := strings.Repeat("x", 4<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequest("GET", server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := NewResilientHTTPClient(nil).DoWithContext(ctx, req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Len(t, data, len(body))
+}
+
+func TestResilientHTTPClient_FallbackPerResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.MaxRetries = 0
+	client := NewResilientHTTPClient(config)
+
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, string(config.FallbackBody), string(data), "fallback %d", i)
+	}
+}
+
+func TestResilientHTTPClient_FallbackOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": "real upstream detail"}`)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.MaxRetries = 0
+	client := NewResilientHTTPClient(config)
+
+	for name, opts := range map[string]fallbackOptions{
+		"disabled": {disabled: true},
+		"kept":     {keep: []int{http.StatusOK, http.StatusInternalServerError}},
+	} {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.DoWithContext(withFallbackOptions(context.Background(), opts), req)
+		require.NoError(t, err, name)
+
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode, name)
+		assert.JSONEq(t, `{"error": "real upstream detail"}`, string(data), name)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, isRetryableError(errors.New("connection reset")))
+	assert.False(t, isRetryableError(&net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.False(t, isRetryableError(circuitbreaker.ErrOpen))
+	assert.False(t, isRetryableError(context.Canceled))
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// Metrics recorded by hedged requests, tagged with the upstream name
+const (
+	// HedgeMetric counts duplicate requests sent because the earlier ones
+	// had not answered within the hedge delay
+	HedgeMetric = "http_client_hedges_total"
+	// HedgeWinMetric counts hedged requests whose response was used
+	HedgeWinMetric = "http_client_hedge_wins_total"
+	// HedgeSkippedMetric counts requests sent unhedged by a hedging client
+	// because they were not idempotent, tagged with the method as well
+	HedgeSkippedMetric = "http_client_hedges_skipped_total"
+)
+
+// IdempotencyKeyHeader marks a request as safe to send more than once, so
+// that non-idempotent methods may be hedged
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// isIdempotent reports whether sending req more than once has the same
+// effect as sending it once: its method is idempotent, or it carries an
+// idempotency key
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// hedging configures hedged requests: a request not answered within delay
+// is sent again, up to maxHedges more times
+type hedging struct {
+	delay     time.Duration
+	maxHedges int
+}
+
+func (h hedging) enabled() bool {
+	return h.delay > 0 && h.maxHedges > 0
+}
+
+type hedgingKey struct{}
+
+// withHedging makes requests sent with ctx use h instead of the client's
+// hedging settings
+func withHedging(ctx context.Context, h hedging) context.Context {
+	return context.WithValue(ctx, hedgingKey{}, h)
+}
+
+// hedgingFor returns the hedging settings for a request sent with ctx
+func (c *ResilientHTTPClient) hedgingFor(ctx context.Context) hedging {
+	if h, ok := ctx.Value(hedgingKey{}).(hedging); ok {
+		return h
+	}
+	return hedging{delay: c.config.HedgeDelay, maxHedges: c.config.MaxHedges}
+}
+
+type hedgeResult struct {
+	resp    *http.Response
+	err     error
+	attempt int
+}
+
+// hedgeSucceeded reports whether a response ends a hedged request
+func hedgeSucceeded(r hedgeResult) bool {
+	return r.err == nil && r.resp.StatusCode < 500
+}
+
+// hedge sends req, and sends it again each time delay passes without an
+// answer, up to maxHedges more times. Each copy goes through the client's
+// resilience policies on its own. The first successful response is
+// returned and the other copies are cancelled; when every copy fails, the
+// last failure is returned.
+func (c *ResilientHTTPClient) hedge(ctx context.Context, req *http.Request, h hedging) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tags := map[string]string{"upstream": c.name}
+	results := make(chan hedgeResult, h.maxHedges+1)
+	send := func(attempt int) {
+		attemptReq := req.Clone(ctx)
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			attemptReq.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+		go func() {
+			resp, err := c.send(ctx, attemptReq)
+			results <- hedgeResult{resp: resp, err: err, attempt: attempt}
+		}()
+	}
+
+	send(0)
+	sent, received := 1, 0
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if sent <= h.maxHedges && ctx.Err() == nil {
+				metrics.IncrementCounter(HedgeMetric, tags)
+				send(sent)
+				sent++
+				timer.Reset(h.delay)
+			}
+
+		case result := <-results:
+			received++
+			if hedgeSucceeded(result) {
+				if result.attempt > 0 {
+					metrics.IncrementCounter(HedgeWinMetric, tags)
+				}
+				// Responses are read into memory by bufferedTransport, so
+				// cancelling the losers leaves the winner intact
+				go drainHedges(results, sent-received)
+				return result.resp, nil
+			}
+			if last.resp != nil {
+				last.resp.Body.Close()
+			}
+			last = result
+			// Failed copies were already retried by the policies, so
+			// hedging does not start over once they all failed
+			if received == sent {
+				return last.resp, last.err
+			}
+		}
+	}
+}
+
+// drainHedges closes the responses of the n copies still running once a
+// hedged request has its answer
+func drainHedges(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if result := <-results; result.resp != nil {
+			result.resp.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// slowFirstServer stalls the first request until it is cancelled, reported
+// on cancelled, and answers later ones at once
+func slowFirstServer(calls *int32, cancelled chan<- struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("fast"))
+	}))
+}
+
+func hedgingConfig(delay time.Duration) *ClientConfig {
+	config := DefaultClientConfig()
+	config.MaxRetries = 0
+	config.EnableFallback = false
+	config.HedgeDelay = delay
+	config.MaxHedges = 1
+	return config
+}
+
+func TestResilientHTTPClient_Hedging(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	var calls int32
+	cancelled := make(chan struct{}, 1)
+	server := slowFirstServer(&calls, cancelled)
+	defer server.Close()
+
+	client := NewResilientHTTPClient(hedgingConfig(20 * time.Millisecond))
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "fast", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("The slow request should have been cancelled")
+	}
+
+	counters := collector.GetCounters()
+	assert.Equal(t, int64(1), metricTotal(counters, HedgeMetric))
+	assert.Equal(t, int64(1), metricTotal(counters, HedgeWinMetric))
+}
+
+func TestResilientHTTPClient_HedgingRetryBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	config := hedgingConfig(5 * time.Millisecond)
+	config.MaxHedges = 2
+	client := NewResilientHTTPClient(config)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Greater(t, atomic.LoadInt32(&calls), int32(1), "the request should have been hedged")
+
+	requests := 0
+	for _, bucket := range client.retryBudget.buckets {
+		requests += bucket.requests
+	}
+	assert.Equal(t, 1, requests, "hedged copies do not count as requests in the retry budget")
+}
+
+func TestResilientHTTPClient_HedgingIdempotentOnly(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "payload", string(body), "every copy sends the body")
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewResilientHTTPClient(hedgingConfig(5 * time.Millisecond))
+	post := func(idempotencyKey string) {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader("payload"))
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	post("")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "POST is not hedged")
+	assert.Equal(t, int64(1), metricTotal(collector.GetCounters(), HedgeSkippedMetric))
+
+	atomic.StoreInt32(&calls, 0)
+	post("order-42")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "an idempotency key allows hedging")
+	assert.Equal(t, int64(1), metricTotal(collector.GetCounters(), HedgeSkippedMetric), "keyed requests are hedged")
+}
+
+func TestHTTPStepRun_WithHedging(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{}, 1)
+	server := slowFirstServer(&calls, cancelled)
+	defer server.Close()
+
+	ctx := NewMockExecutionContext()
+	err := GET(server.URL).
+		WithClientConfig(hedgingConfig(0)).
+		WithHedging(20*time.Millisecond, 2).
+		SaveAs("result").
+		Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	err = POST(server.URL).WithHedging(20*time.Millisecond, 1).Run(NewMockExecutionContext())
+	var fwErr *frameworkErrors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, frameworkErrors.ErrCodeInvalidConfiguration, fwErr.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "the POST should not have been sent")
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
+)
+
+// PollStep re-issues a GET request until a field of the JSON response body
+// matches an expected value, e.g. to wait for a job accepted with 202 to
+// reach status "done". It is a flow.LoopStep, so the attempt counter is
+// available under flow.IterationKey(step.Name()).
+type PollStep struct {
+	*flow.LoopStep
+	request  *HTTPStep
+	saveAs   string
+	field    string
+	expected interface{}
+	failOn   []interface{}
+}
+
+// NewPollStep creates a step polling url until the dot-notation field of
+// the response body equals expected. Values are compared by their string
+// form, so a JSON number 3 matches an expected "3". By default the request
+// is re-issued every second for at most a minute.
+func NewPollStep(url, field string, expected interface{}) *PollStep {
+	s := &PollStep{
+		request:  GET(url),
+		saveAs:   "http_response",
+		field:    field,
+		expected: expected,
+	}
+	s.LoopStep = flow.NewLoopStep("poll_"+utils.SanitizeURL(url), s.request, &pollCheck{s}).
+		WithUntil(s.matched).
+		WithInterval(time.Second).
+		WithDeadline(time.Minute)
+	return s
+}
+
+// Request returns the polled request, e.g. to add headers or authentication
+func (s *PollStep) Request() *HTTPStep {
+	return s.request
+}
+
+// SaveAs sets the context key the last response is saved under
+func (s *PollStep) SaveAs(key string) *PollStep {
+	s.saveAs = key
+	s.request.SaveAs(key)
+	return s
+}
+
+// WithInterval sets the wait between requests
+func (s *PollStep) WithInterval(interval time.Duration) *PollStep {
+	s.LoopStep.WithInterval(interval)
+	return s
+}
+
+// WithBackoff multiplies the interval by multiplier after every request, up
+// to maxInterval (0 for no cap)
+func (s *PollStep) WithBackoff(multiplier float64, maxInterval time.Duration) *PollStep {
+	s.LoopStep.WithBackoff(multiplier, maxInterval)
+	return s
+}
+
+// WithMaxAttempts caps the number of requests; 0 means no cap
+func (s *PollStep) WithMaxAttempts(n int) *PollStep {
+	s.LoopStep.WithMaxIterations(n)
+	return s
+}
+
+// WithDeadline bounds the total polling time
+func (s *PollStep) WithDeadline(deadline time.Duration) *PollStep {
+	s.LoopStep.WithDeadline(deadline)
+	return s
+}
+
+// FailOn stops polling with an error when the field takes one of values,
+// e.g. a terminal "failed" status
+func (s *PollStep) FailOn(values ...interface{}) *PollStep {
+	s.failOn = values
+	return s
+}
+
+// value returns the polled field of the last response
+func (s *PollStep) value(ctx interfaces.ExecutionContext) (interface{}, bool) {
+	return utils.LookupNestedValue(s.saveAs+".body."+s.field, ctx)
+}
+
+func (s *PollStep) matched(ctx interfaces.ExecutionContext) bool {
+	value, ok := s.value(ctx)
+	return ok && sameValue(value, s.expected)
+}
+
+// pollCheck fails the loop once the polled field reaches a failure value
+type pollCheck struct {
+	poll *PollStep
+}
+
+func (c *pollCheck) Name() string {
+	return c.poll.Name() + "_check"
+}
+
+func (c *pollCheck) Description() string {
+	return "Check the polled field for failure values"
+}
+
+func (c *pollCheck) Run(ctx interfaces.ExecutionContext) error {
+	value, ok := c.poll.value(ctx)
+	if !ok {
+		return nil
+	}
+	for _, failure := range c.poll.failOn {
+		if sameValue(value, failure) {
+			return errors.NewExternalError(errors.ErrCodeExternalServiceError,
+				fmt.Sprintf("Poll step '%s': %s is %v", c.poll.Name(), c.poll.field, value)).
+				WithContext("step", c.poll.Name()).
+				WithContext("field", c.poll.field)
+		}
+	}
+	return nil
+}
+
+// sameValue compares a decoded JSON value with a configured one by their
+// string form
+func sameValue(value, expected interface{}) bool {
+	return fmt.Sprint(value) == fmt.Sprint(expected)
+}
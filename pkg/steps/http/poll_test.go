@@ -0,0 +1,120 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/utils"
+)
+
+// jobServer serves a job whose status is taken from statuses in turn,
+// repeating the last one
+func jobServer(statuses ...string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"job":{"id":42,"status":%q}}`, statuses[n-1])
+	}))
+	return server, &requests
+}
+
+func TestPollStep_UntilMatch(t *testing.T) {
+	server, requests := jobServer("pending", "running", "done")
+	defer server.Close()
+
+	ctx := flow.NewContext()
+	step := NewPollStep(server.URL+"/jobs/42", "job.status", "done").
+		SaveAs("job").
+		WithInterval(time.Millisecond).
+		WithBackoff(2, 5*time.Millisecond)
+
+	require.NoError(t, step.Run(ctx))
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+
+	status, _ := utils.LookupNestedValue("job.body.job.status", ctx)
+	assert.Equal(t, "done", status)
+	iteration, _ := ctx.Get(flow.IterationKey(step.Name()))
+	assert.Equal(t, 3, iteration)
+}
+
+func TestPollStep_FailOn(t *testing.T) {
+	server, requests := jobServer("pending", "failed")
+	defer server.Close()
+
+	err := NewPollStep(server.URL, "job.status", "done").
+		FailOn("failed", "cancelled").
+		WithInterval(time.Millisecond).
+		Run(flow.NewContext())
+
+	var fwErr *frameworkErrors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, frameworkErrors.ErrCodeExternalServiceError, fwErr.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestPollStep_MaxAttempts(t *testing.T) {
+	server, requests := jobServer("pending")
+	defer server.Close()
+
+	err := NewPollStep(server.URL, "job.status", "done").
+		WithInterval(time.Millisecond).
+		WithMaxAttempts(2).
+		Run(flow.NewContext())
+
+	var fwErr *frameworkErrors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, frameworkErrors.ErrCodeLoopExhausted, fwErr.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestPollStep_MatchesNumbersByValue(t *testing.T) {
+	server, _ := jobServer("done")
+	defer server.Close()
+
+	step := NewPollStep(server.URL, "job.id", 42).WithMaxAttempts(1)
+	assert.NoError(t, step.Run(flow.NewContext()))
+}
+
+func TestRegisterSteps_Poll(t *testing.T) {
+	server, requests := jobServer("pending", "done")
+	defer server.Close()
+
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("http_poll", map[string]interface{}{
+		"url":          server.URL,
+		"field":        "job.status",
+		"expected":     "done",
+		"fail_on":      []interface{}{"failed"},
+		"interval":     "1ms",
+		"max_attempts": 5,
+		"save_as":      "job",
+	})
+	require.NoError(t, err)
+
+	pollStep, ok := step.(*PollStep)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"failed"}, pollStep.failOn)
+
+	ctx := flow.NewContext()
+	require.NoError(t, step.Run(ctx))
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+	assert.True(t, ctx.Has("job"))
+
+	assert.Error(t, reg.ValidateConfig("http_poll", map[string]interface{}{"url": server.URL}))
+}
@@ -0,0 +1,252 @@
+package http
+
+import (
+	"context"
+	stderrors "errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// Metrics recorded by client-side rate limiting, tagged with the upstream
+// name and the request host
+const (
+	// RateLimitWaitMetric records how long throttled requests waited for a
+	// token
+	RateLimitWaitMetric = "http_client_rate_limit_wait_seconds"
+	// RateLimitRejectedMetric counts requests failed because no token was
+	// available in time
+	RateLimitRejectedMetric = "http_client_rate_limit_rejected_total"
+)
+
+// tokenBucket holds up to burst tokens, refilled at rate tokens per second.
+// Tokens may go negative: each caller reserves the next token and waits
+// until it has been refilled, so waiting callers are served in order.
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newTokenBucket(rate, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. Nothing is taken and ok is false when the wait would exceed
+// maxWait.
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (wait time.Duration, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	tokens := b.tokens - 1
+	if tokens < 0 {
+		wait = time.Duration(-tokens / b.rate * float64(time.Second))
+	}
+	if paused := b.pausedUntil.Sub(now); paused > wait {
+		wait = paused
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+	b.tokens = tokens
+	return wait, true
+}
+
+// cancel returns a token reserved by a caller that gave up waiting
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// pause holds back all tokens until the given time
+func (b *tokenBucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// rateLimiter limits the requests of a ResilientHTTPClient with one token
+// bucket for the client, or one per request host. A nil rateLimiter does not
+// limit.
+type rateLimiter struct {
+	name     string
+	rate     int
+	burst    int
+	perHost  bool
+	failFast bool
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// newRateLimiter creates the limiter configured by config, or nil when rate
+// limiting is disabled
+func newRateLimiter(name string, config *ClientConfig) *rateLimiter {
+	if !config.EnableRateLimit || config.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		name:     name,
+		rate:     config.RequestsPerSecond,
+		burst:    config.BurstSize,
+		perHost:  config.RateLimitPerHost,
+		failFast: config.RateLimitFailFast,
+		buckets:  make(map[string]*tokenBucket),
+	}
+}
+
+// bucket returns the token bucket requests to host take from
+func (l *rateLimiter) bucket(host string) *tokenBucket {
+	if !l.perHost {
+		host = ""
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = newTokenBucket(l.rate, l.burst)
+		l.buckets[host] = b
+	}
+	return b
+}
+
+// wait takes a token for a request to host. Without fail-fast it waits for
+// the token, unless it would not arrive before the deadline of ctx; requests
+// that get no token fail with RATE_LIMIT_EXCEEDED.
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	maxWait := time.Duration(math.MaxInt64)
+	if l.failFast {
+		maxWait = 0
+	} else if deadline, ok := ctx.Deadline(); ok {
+		maxWait = time.Until(deadline)
+	}
+
+	tags := map[string]string{"upstream": l.name, "host": host}
+	b := l.bucket(host)
+	wait, ok := b.reserve(time.Now(), maxWait)
+	if !ok {
+		metrics.IncrementCounter(RateLimitRejectedMetric, tags)
+		return errors.RateLimitExceeded(l.rate, "second").
+			WithContext("host", host).
+			WithContext("retry_after", wait.String())
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		metrics.RecordDuration(RateLimitWaitMetric, wait, tags)
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+// tokenKey is the context key of a token taken ahead of a request
+type tokenKey struct{}
+
+// take waits for a token for a request to host ahead of sending it, so that
+// callers can wait for it before acquiring other resources. The first
+// attempt sent with the returned context uses the token.
+func (l *rateLimiter) take(ctx context.Context, host string) (context.Context, error) {
+	if l == nil {
+		return ctx, nil
+	}
+	if err := l.wait(ctx, host); err != nil {
+		return ctx, err
+	}
+	token := new(atomic.Bool)
+	token.Store(true)
+	return context.WithValue(ctx, tokenKey{}, token), nil
+}
+
+// acquire takes a token for an attempt sent with ctx: the one taken ahead
+// with take, if still unused, or a new one
+func (l *rateLimiter) acquire(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	if token, ok := ctx.Value(tokenKey{}).(*atomic.Bool); ok && token.CompareAndSwap(true, false) {
+		return nil
+	}
+	return l.wait(ctx, host)
+}
+
+// isRateLimited reports whether err is a request rejected by the rate
+// limiter
+func isRateLimited(err error) bool {
+	var fwErr *errors.FrameworkError
+	return stderrors.As(err, &fwErr) && fwErr.Code == errors.ErrCodeRateLimitExceeded
+}
+
+// observe pauses the host's bucket for the Retry-After of a 429 response
+func (l *rateLimiter) observe(req *http.Request, resp *http.Response) {
+	if l == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	now := time.Now()
+	if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+		l.bucket(req.URL.Host).pause(now.Add(delay))
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or
+// as an HTTP date, into the delay from now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// rateLimitedConfig returns a client configuration limited to rps requests
+// per second that neither retries nor falls back
+func rateLimitedConfig(rps, burst int) *ClientConfig {
+	config := DefaultClientConfig()
+	config.MaxRetries = 0
+	config.RetryableStatusCodes = nil
+	config.EnableFallback = false
+	config.EnableRateLimit = true
+	config.RequestsPerSecond = rps
+	config.BurstSize = burst
+	return config
+}
+
+func get(t *testing.T, client *ResilientHTTPClient, ctx context.Context, url string) error {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func assertRateLimited(t *testing.T, err error) *frameworkErrors.FrameworkError {
+	t.Helper()
+	var fwErr *frameworkErrors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, frameworkErrors.ErrCodeRateLimitExceeded, fwErr.Code)
+	return fwErr
+}
+
+// metricTotal sums the values recorded under name, whatever their tags
+func metricTotal[V int64 | float64](values map[string]V, name string) V {
+	var total V
+	for key, value := range values {
+		if strings.HasPrefix(key, name+",") {
+			total += value
+		}
+	}
+	return total
+}
+
+func newOKServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestResilientHTTPClient_RateLimitFailFast(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	server := newOKServer()
+	defer server.Close()
+
+	config := rateLimitedConfig(1, 1)
+	config.RateLimitFailFast = true
+	client := NewResilientHTTPClient(config)
+
+	require.NoError(t, get(t, client, context.Background(), server.URL))
+	assertRateLimited(t, get(t, client, context.Background(), server.URL))
+	assert.Equal(t, int64(1), metricTotal(collector.GetCounters(), RateLimitRejectedMetric))
+}
+
+func TestResilientHTTPClient_RateLimitWaits(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	server := newOKServer()
+	defer server.Close()
+
+	client := NewResilientHTTPClient(rateLimitedConfig(20, 1))
+	require.NoError(t, get(t, client, context.Background(), server.URL))
+
+	start := time.Now()
+	require.NoError(t, get(t, client, context.Background(), server.URL))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond, "the second request waits for a token")
+	assert.Len(t, collector.GetHistograms(), 1)
+
+	// A token that would arrive after the deadline is not waited for, so
+	// the request fails with the rate limit rather than the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assertRateLimited(t, get(t, client, ctx, server.URL))
+}
+
+func TestResilientHTTPClient_RateLimitPerHost(t *testing.T) {
+	first := newOKServer()
+	defer first.Close()
+	second := newOKServer()
+	defer second.Close()
+
+	config := rateLimitedConfig(1, 1)
+	config.RateLimitPerHost = true
+	config.RateLimitFailFast = true
+	client := NewResilientHTTPClient(config)
+
+	require.NoError(t, get(t, client, context.Background(), first.URL))
+	require.NoError(t, get(t, client, context.Background(), second.URL), "each host has its own limit")
+	assertRateLimited(t, get(t, client, context.Background(), first.URL))
+}
+
+func TestResilientHTTPClient_RateLimitRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	config := rateLimitedConfig(100, 10)
+	config.RateLimitFailFast = true
+	client := NewResilientHTTPClient(config)
+
+	require.NoError(t, get(t, client, context.Background(), server.URL))
+	fwErr := assertRateLimited(t, get(t, client, context.Background(), server.URL))
+	assert.Equal(t, server.Listener.Addr().String(), fwErr.Context["host"])
+}
+
+func TestResilientHTTPClient_RateLimitRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := rateLimitedConfig(1, 1)
+	config.RateLimitFailFast = true
+	config.MaxRetries = 2
+	config.InitialRetryDelay = time.Millisecond
+	config.RetryableStatusCodes = []int{http.StatusServiceUnavailable}
+	client := NewResilientHTTPClient(config)
+
+	assertRateLimited(t, get(t, client, context.Background(), server.URL))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "the retry needs a token of its own")
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 90*time.Second, delay)
+
+	delay, ok = parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Zero(t, delay, "dates in the past mean no delay")
+
+	for _, value := range []string{"", "-1", "soon"} {
+		_, ok = parseRetryAfter(value, now)
+		assert.False(t, ok, value)
+	}
+}
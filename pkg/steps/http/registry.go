@@ -0,0 +1,100 @@
+package http
+
+import (
+	"time"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+// StepConfig configures an "http" step created through the step registry
+type StepConfig struct {
+	Method         string            `json:"method,omitempty" default:"GET"`
+	URL            string            `json:"url" description:"Request URL; ${var} placeholders are interpolated"`
+	Headers        map[string]string `json:"headers,omitempty" default:"{}"`
+	QueryParams    map[string]string `json:"query_params,omitempty" default:"{}"`
+	Body           interface{}       `json:"body,omitempty" default:"" description:"JSON request body"`
+	Timeout        time.Duration     `json:"timeout,omitempty" default:"30s"`
+	SaveAs         string            `json:"save_as,omitempty" default:""`
+	ExpectedStatus []int             `json:"expected_status,omitempty" default:"[]"`
+	BearerToken    string            `json:"bearer_token,omitempty" default:""`
+	Propagate      bool              `json:"propagate,omitempty" default:"true" description:"Forward the request ID, trace context and baggage"`
+	Upstream       string            `json:"upstream,omitempty" default:"" description:"Upstream whose client sends the request; by default picked by host"`
+	HedgeDelay     time.Duration     `json:"hedge_delay,omitempty" default:"0s" description:"Send the request again when unanswered after this delay; 0 disables hedging"`
+	MaxHedges      int               `json:"max_hedges,omitempty" default:"1"`
+}
+
+// PollConfig configures an "http_poll" step created through the step
+// registry
+type PollConfig struct {
+	URL         string            `json:"url" description:"URL polled with GET; ${var} placeholders are interpolated"`
+	Field       string            `json:"field" description:"Dot-notation path into the JSON response body"`
+	Expected    interface{}       `json:"expected" description:"Value of field that ends polling"`
+	FailOn      []interface{}     `json:"fail_on,omitempty" default:"[]" description:"Values of field that fail the step"`
+	Headers     map[string]string `json:"headers,omitempty" default:"{}"`
+	Interval    time.Duration     `json:"interval,omitempty" default:"1s"`
+	Multiplier  float64           `json:"multiplier,omitempty" default:"1"`
+	MaxInterval time.Duration     `json:"max_interval,omitempty" default:"0s"`
+	MaxAttempts int               `json:"max_attempts,omitempty" default:"0"`
+	Deadline    time.Duration     `json:"deadline,omitempty" default:"1m"`
+	SaveAs      string            `json:"save_as,omitempty" default:""`
+}
+
+// RegisterSteps registers the "http" and "http_poll" steps with reg
+func RegisterSteps(reg *registry.StepRegistry) error {
+	if err := reg.RegisterWithReflection("http", "Perform an HTTP request", "http", "v1", newStepFromConfig, StepConfig{}); err != nil {
+		return err
+	}
+	return reg.RegisterWithReflection("http_poll", "Poll a URL until a response field matches", "http", "v1", newPollStepFromConfig, PollConfig{})
+}
+
+func newStepFromConfig(config map[string]interface{}) (interfaces.Step, error) {
+	var cfg StepConfig
+	if err := registry.DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+
+	step := NewHTTPStep(cfg.Method, cfg.URL).
+		WithHeaders(cfg.Headers).
+		WithQueryParams(cfg.QueryParams).
+		WithTimeout(cfg.Timeout).
+		WithPropagation(cfg.Propagate)
+	if cfg.Body != nil {
+		step.WithJSONBody(cfg.Body)
+	}
+	if cfg.SaveAs != "" {
+		step.SaveAs(cfg.SaveAs)
+	}
+	if len(cfg.ExpectedStatus) > 0 {
+		step.WithExpectedStatus(cfg.ExpectedStatus...)
+	}
+	if cfg.BearerToken != "" {
+		step.WithBearerToken(cfg.BearerToken)
+	}
+	if cfg.Upstream != "" {
+		step.WithUpstream(cfg.Upstream)
+	}
+	if cfg.HedgeDelay > 0 {
+		step.WithHedging(cfg.HedgeDelay, cfg.MaxHedges)
+	}
+	return step, nil
+}
+
+func newPollStepFromConfig(config map[string]interface{}) (interfaces.Step, error) {
+	var cfg PollConfig
+	if err := registry.DecodeConfig(config, &cfg); err != nil {
+		return nil, err
+	}
+
+	step := NewPollStep(cfg.URL, cfg.Field, cfg.Expected).
+		FailOn(cfg.FailOn...).
+		WithInterval(cfg.Interval).
+		WithBackoff(cfg.Multiplier, cfg.MaxInterval).
+		WithMaxAttempts(cfg.MaxAttempts).
+		WithDeadline(cfg.Deadline)
+	step.Request().WithHeaders(cfg.Headers)
+	if cfg.SaveAs != "" {
+		step.SaveAs(cfg.SaveAs)
+	}
+	return step, nil
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/registry"
+)
+
+func TestRegisterSteps(t *testing.T) {
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("http", map[string]interface{}{
+		"method":          "post",
+		"url":             "http://example.com/users/${user_id}",
+		"headers":         map[string]interface{}{"X-Test": "yes"},
+		"query_params":    map[string]interface{}{"page": "1"},
+		"body":            map[string]interface{}{"name": "test"},
+		"timeout":         "2s",
+		"save_as":         "created",
+		"expected_status": []interface{}{201},
+		"bearer_token":    "secret",
+		"propagate":       false,
+	})
+	require.NoError(t, err)
+
+	httpStep, ok := step.(*HTTPStep)
+	require.True(t, ok)
+	assert.Equal(t, "POST", httpStep.method)
+	assert.Equal(t, "yes", httpStep.headers["X-Test"])
+	assert.Equal(t, "1", httpStep.queryParams["page"])
+	assert.Equal(t, map[string]interface{}{"name": "test"}, httpStep.body)
+	assert.Equal(t, 2*time.Second, httpStep.responseTimeout)
+	assert.Equal(t, "created", httpStep.saveAs)
+	assert.Equal(t, []int{201}, httpStep.expectedStatus)
+	assert.Equal(t, "secret", httpStep.bearerToken)
+	assert.False(t, httpStep.propagate)
+}
+
+func TestRegisterSteps_Defaults(t *testing.T) {
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("http", map[string]interface{}{"url": "http://example.com"})
+	require.NoError(t, err)
+
+	httpStep := step.(*HTTPStep)
+	assert.Equal(t, http.MethodGet, httpStep.method)
+	assert.Nil(t, httpStep.body)
+	assert.Equal(t, 30*time.Second, httpStep.responseTimeout)
+	assert.Equal(t, []int{200, 201, 202, 204}, httpStep.expectedStatus)
+	assert.True(t, httpStep.propagate)
+
+	assert.Error(t, reg.ValidateConfig("http", map[string]interface{}{}))
+	_, err = reg.Create("http", map[string]interface{}{"url": "http://example.com", "timeout": "later"})
+	assert.Error(t, err)
+}
+
+func TestRegisterSteps_Run(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	reg := registry.NewStepRegistry()
+	require.NoError(t, RegisterSteps(reg))
+
+	step, err := reg.Create("http", map[string]interface{}{"url": server.URL, "save_as": "result"})
+	require.NoError(t, err)
+
+	ctx := NewMockExecutionContext()
+	require.NoError(t, step.Run(ctx))
+	assert.True(t, ctx.Has("result"))
+}
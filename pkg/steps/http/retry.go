@@ -0,0 +1,156 @@
+package http
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/failsafe-go/failsafe-go"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+)
+
+// RetryBudgetExhaustedMetric counts failed requests not retried because the
+// client's retry budget was spent, tagged with the upstream name
+const RetryBudgetExhaustedMetric = "http_client_retry_budget_exhausted_total"
+
+// retryDelay returns how long to wait before retry number retries+1 of a
+// request whose last attempt returned resp. A Retry-After on a 429 or 503
+// response is honored up to MaxRetryDelay; otherwise the delay backs off
+// exponentially from InitialRetryDelay, and with RetryJitter it is drawn
+// at random between zero and the backoff ("full jitter"), so that clients
+// failing together do not retry together.
+func retryDelay(config *ClientConfig, retries int, resp *http.Response, now time.Time) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			if config.MaxRetryDelay > 0 && delay > config.MaxRetryDelay {
+				delay = config.MaxRetryDelay
+			}
+			return delay
+		}
+	}
+
+	multiplier := config.RetryMultiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	delay := float64(config.InitialRetryDelay) * math.Pow(multiplier, float64(retries))
+	if config.MaxRetryDelay > 0 && delay > float64(config.MaxRetryDelay) {
+		delay = float64(config.MaxRetryDelay)
+	}
+	if config.RetryJitter {
+		delay *= rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// retryDelayFunc adapts retryDelay to the retry policy
+func retryDelayFunc(config *ClientConfig) failsafe.DelayFunc[*http.Response] {
+	return func(exec failsafe.ExecutionAttempt[*http.Response]) time.Duration {
+		return retryDelay(config, max(exec.Attempts()-1, 0), exec.LastResult(), time.Now())
+	}
+}
+
+// retryBudgetBuckets is the number of buckets a retry budget window is
+// split into
+const retryBudgetBuckets = 10
+
+// retryBudget caps retries at a fraction of the requests sent over a
+// sliding window, so that an upstream in trouble is not hit by a retry
+// storm on top of its normal load. A nil retryBudget allows every retry.
+type retryBudget struct {
+	ratio      float64
+	minRetries int
+	bucketSize time.Duration
+
+	mu      sync.Mutex
+	buckets [retryBudgetBuckets]retryBudgetBucket
+}
+
+type retryBudgetBucket struct {
+	epoch    int64
+	requests int
+	retries  int
+}
+
+// newRetryBudget creates the budget configured by config, or nil when it
+// is disabled
+func newRetryBudget(config *ClientConfig) *retryBudget {
+	if config.RetryBudgetRatio <= 0 {
+		return nil
+	}
+	window := config.RetryBudgetWindow
+	if window <= 0 {
+		window = 10 * time.Second
+	}
+	return &retryBudget{
+		ratio:      config.RetryBudgetRatio,
+		minRetries: config.RetryBudgetMinRetries,
+		bucketSize: max(window/retryBudgetBuckets, time.Millisecond),
+	}
+}
+
+// bucket returns the bucket for now, cleared if it was last used in an
+// earlier window; the caller holds mu
+func (b *retryBudget) bucket(now time.Time) *retryBudgetBucket {
+	epoch := now.UnixNano() / int64(b.bucketSize)
+	bucket := &b.buckets[epoch%retryBudgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = retryBudgetBucket{epoch: epoch}
+	}
+	return bucket
+}
+
+// recordRequest counts a request sent by the client, retries excluded
+func (b *retryBudget) recordRequest() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).requests++
+}
+
+// recordRetry counts a retry
+func (b *retryBudget) recordRetry() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).retries++
+}
+
+// allowRetry reports whether another retry fits in the budget: fewer than
+// minRetries retries, or fewer than ratio of the requests, in the window
+func (b *retryBudget) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldest := time.Now().UnixNano()/int64(b.bucketSize) - retryBudgetBuckets + 1
+	requests, retries := 0, 0
+	for _, bucket := range b.buckets {
+		if bucket.epoch >= oldest {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+	return retries < b.minRetries || float64(retries) < b.ratio*float64(requests)
+}
+
+// withIdempotencyKey returns req with a generated Idempotency-Key header
+// when AutoIdempotencyKey is set and req is not idempotent. The key is set
+// once per request, so retries and hedges of the request share it.
+func (c *ResilientHTTPClient) withIdempotencyKey(req *http.Request) *http.Request {
+	if !c.config.AutoIdempotencyKey || isIdempotent(req) {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(IdempotencyKeyHeader, config.RandomID())
+	return req
+}
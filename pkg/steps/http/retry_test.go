@@ -0,0 +1,167 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultClientConfig()
+	config.InitialRetryDelay = 100 * time.Millisecond
+	config.MaxRetryDelay = time.Second
+	config.RetryJitter = false
+
+	assert.Equal(t, 100*time.Millisecond, retryDelay(config, 0, nil, now))
+	assert.Equal(t, 400*time.Millisecond, retryDelay(config, 2, nil, now))
+	assert.Equal(t, time.Second, retryDelay(config, 5, nil, now), "backoff is capped")
+
+	config.RetryJitter = true
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		delay := retryDelay(config, 2, nil, now)
+		assert.True(t, delay >= 0 && delay <= 400*time.Millisecond, "delay %v outside the backoff", delay)
+		seen[delay] = true
+	}
+	assert.Greater(t, len(seen), 1, "jitter should vary the delay")
+
+	retryAfter := func(status int, value string) *http.Response {
+		return &http.Response{StatusCode: status, Header: http.Header{"Retry-After": []string{value}}}
+	}
+	config.MaxRetryDelay = 10 * time.Second
+	assert.Equal(t, 3*time.Second, retryDelay(config, 0, retryAfter(http.StatusTooManyRequests, now.Add(3*time.Second).Format(http.TimeFormat)), now))
+	assert.Equal(t, 10*time.Second, retryDelay(config, 0, retryAfter(http.StatusServiceUnavailable, "120"), now), "Retry-After is capped")
+	assert.LessOrEqual(t, retryDelay(config, 0, retryAfter(http.StatusBadGateway, "120"), now), 100*time.Millisecond,
+		"Retry-After only applies to 429 and 503")
+}
+
+func TestResilientHTTPClient_RetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.InitialRetryDelay = time.Millisecond
+	config.MaxRetryDelay = 50 * time.Millisecond
+	config.EnableFallback = false
+	client := NewResilientHTTPClient(config)
+
+	start := time.Now()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 40*time.Millisecond, "the retry waits for Retry-After")
+	assert.Less(t, elapsed, 900*time.Millisecond, "Retry-After is capped by MaxRetryDelay")
+}
+
+func TestResilientHTTPClient_RetriesIdempotentOnly(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	newClient := func(configure func(*ClientConfig)) *ResilientHTTPClient {
+		config := DefaultClientConfig()
+		config.MaxRetries = 2
+		config.InitialRetryDelay = time.Millisecond
+		config.EnableFallback = false
+		config.FailureThreshold = 0
+		configure(config)
+		return NewResilientHTTPClient(config)
+	}
+	post := func(client *ResilientHTTPClient, header http.Header) int32 {
+		atomic.StoreInt32(&calls, 0)
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{}`))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+		}
+		return atomic.LoadInt32(&calls)
+	}
+
+	client := newClient(func(*ClientConfig) {})
+	assert.Equal(t, int32(1), post(client, nil), "POST is not retried")
+	assert.Equal(t, int32(3), post(client, http.Header{IdempotencyKeyHeader: {"order-42"}}))
+
+	client = newClient(func(c *ClientConfig) { c.RetryNonIdempotent = true })
+	assert.Equal(t, int32(3), post(client, nil))
+
+	client = newClient(func(c *ClientConfig) { c.AutoIdempotencyKey = true })
+	assert.Equal(t, int32(3), post(client, nil))
+}
+
+func TestRetryBudget(t *testing.T) {
+	config := DefaultClientConfig()
+	config.RetryBudgetRatio = 0.5
+	config.RetryBudgetMinRetries = 1
+	config.RetryBudgetWindow = time.Minute
+	budget := newRetryBudget(config)
+
+	assert.True(t, budget.allowRetry(), "the minimum is always allowed")
+	budget.recordRetry()
+	assert.False(t, budget.allowRetry())
+	for i := 0; i < 4; i++ {
+		budget.recordRequest()
+	}
+	assert.True(t, budget.allowRetry(), "1 retry for 4 requests is within the ratio")
+
+	config.RetryBudgetRatio = 0
+	assert.Nil(t, newRetryBudget(config))
+	assert.True(t, (*retryBudget)(nil).allowRetry())
+}
+
+func TestResilientHTTPClient_RetryBudget(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.MaxRetries = 5
+	config.InitialRetryDelay = time.Millisecond
+	config.EnableFallback = false
+	config.FailureThreshold = 0
+	config.RetryBudgetRatio = 0.1
+	config.RetryBudgetMinRetries = 2
+	client := NewResilientHTTPClient(config)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "retries stop once the budget is spent")
+	assert.Equal(t, int64(1), metricTotal(collector.GetCounters(), RetryBudgetExhaustedMetric))
+}
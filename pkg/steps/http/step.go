@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,8 +10,12 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/steps/base"
@@ -30,6 +35,9 @@ type HTTPStep struct {
 	saveAs          string
 	client          HTTPClient
 	clientConfig    *ClientConfig
+	upstream        string
+	hedging         hedging
+	fallback        bool
 	transformer     transformers.Transformer
 	validator       validators.Validator
 	responseTimeout time.Duration
@@ -39,6 +47,7 @@ type HTTPStep struct {
 	queryParams     map[string]string
 	cookies         []*http.Cookie
 	expectedStatus  []int
+	propagate       bool
 }
 
 // BasicAuth holds basic authentication credentials
@@ -57,12 +66,12 @@ func NewHTTPStep(method, url string) *HTTPStep {
 		url:             url,
 		headers:         make(map[string]string),
 		queryParams:     make(map[string]string),
-		client:          DefaultClient,
 		clientConfig:    DefaultClientConfig(),
 		bodyType:        "json",
 		responseTimeout: 30 * time.Second,
 		expectedStatus:  []int{200, 201, 202, 204},
 		userAgent:       "API-Orchestration-Framework/1.0",
+		propagate:       true,
 	}
 }
 
@@ -178,9 +187,11 @@ func (h *HTTPStep) WithUserAgent(userAgent string) *HTTPStep {
 	return h
 }
 
-// WithTimeout sets request timeout
+// WithTimeout sets the request timeout, which is also the deadline the
+// step runs under in a flow
 func (h *HTTPStep) WithTimeout(timeout time.Duration) *HTTPStep {
 	h.responseTimeout = timeout
+	h.BaseStep.WithTimeout(timeout)
 	return h
 }
 
@@ -196,16 +207,60 @@ func (h *HTTPStep) SaveAs(key string) *HTTPStep {
 	return h
 }
 
-// WithClient sets a custom HTTP client
+// WithPropagation controls whether the request ID, trace context and baggage
+// of the flow are forwarded to the upstream. It is enabled by default; turn
+// it off for third-party APIs that should not see internal identifiers.
+func (h *HTTPStep) WithPropagation(enabled bool) *HTTPStep {
+	h.propagate = enabled
+	return h
+}
+
+// WithClient sets the client requests are sent with, e.g. a shared
+// ResilientHTTPClient or a test double
 func (h *HTTPStep) WithClient(client HTTPClient) *HTTPStep {
 	h.client = client
 	return h
 }
 
-// WithClientConfig sets HTTP client configuration
+// WithClientConfig gives the step its own ResilientHTTPClient built from
+// config, with its own retry policy, circuit breaker and connection pool
 func (h *HTTPStep) WithClientConfig(config *ClientConfig) *HTTPStep {
 	h.clientConfig = config
 	h.client = NewResilientHTTPClient(config)
+	h.fallback = config.EnableFallback
+	return h
+}
+
+// WithFallback lets the client answer upstream 5xx responses and errors
+// with its configured fallback response. Steps sharing an upstream client
+// do not fall back unless they opt in; WithClientConfig follows
+// ClientConfig.EnableFallback. Statuses passed to WithExpectedStatus are
+// never replaced.
+func (h *HTTPStep) WithFallback(enabled bool) *HTTPStep {
+	h.fallback = enabled
+	return h
+}
+
+// WithUpstream sends the step's requests through the client of the named
+// upstream in the process-wide registry (see Upstreams), sharing its
+// connection pool, circuit breaker and bulkhead with the other steps calling
+// it. Without it the upstream is picked by request host. The step fails with
+// INVALID_CONFIGURATION when no upstream of that name is registered.
+func (h *HTTPStep) WithUpstream(name string) *HTTPStep {
+	h.upstream = name
+	return h
+}
+
+// WithHedging sends the request again when it has not been answered within
+// delay, up to maxHedges more times, and uses the first successful response,
+// cancelling the others. It cuts tail latency from a slow replica at the
+// cost of extra upstream load. Only idempotent requests can be hedged: the
+// step fails for other methods unless an Idempotency-Key header is set or
+// the client adds one (ClientConfig.AutoIdempotencyKey).
+// Hedging takes effect with ResilientHTTPClient and overrides its HedgeDelay
+// and MaxHedges.
+func (h *HTTPStep) WithHedging(delay time.Duration, maxHedges int) *HTTPStep {
+	h.hedging = hedging{delay: delay, maxHedges: maxHedges}
 	return h
 }
 
@@ -239,23 +294,57 @@ func (h *HTTPStep) Run(ctx interfaces.ExecutionContext) error {
 		return fmt.Errorf("failed to prepare request: %w", err)
 	}
 
-	// Create a simple but robust HTTP client
-	client := &http.Client{
-		Timeout: h.responseTimeout,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   false,
-			DisableCompression:  false,
-		},
+	// The step timeout bounds the whole call, including retries and reading
+	// the response
+	timeout := h.responseTimeout
+	if timeout == 0 {
+		timeout = 60 * time.Second // Default timeout
 	}
-	if h.responseTimeout == 0 {
-		client.Timeout = 60 * time.Second // Default timeout
+	reqCtx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+	client, err := h.httpClient(req)
+	if err != nil {
+		return err
+	}
+	if h.hedging.enabled() {
+		if !isIdempotent(req) && !addsIdempotencyKey(client) {
+			return errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+				fmt.Sprintf("Step '%s' cannot hedge %s requests without an %s header", h.Name(), h.method, IdempotencyKeyHeader)).
+				WithContext("step", h.Name()).
+				WithContext("method", h.method)
+		}
+		reqCtx = withHedging(reqCtx, h.hedging)
+	} else if resilient, ok := client.(*ResilientHTTPClient); ok && resilient.hedgingFor(reqCtx).enabled() &&
+		!isIdempotent(req) && !addsIdempotencyKey(client) {
+		ctx.Logger().Warn("Client hedging skipped for a non-idempotent request",
+			zap.String("step", h.Name()),
+			zap.String("method", h.method),
+			zap.String("hint", "set an "+IdempotencyKeyHeader+" header or ClientConfig.AutoIdempotencyKey"))
 	}
+	reqCtx = withFallbackOptions(reqCtx, fallbackOptions{disabled: !h.fallback, keep: h.expectedStatus})
+
+	// Wait for the client's first rate-limit token before taking a slot, so
+	// that throttled requests do not hold slots other requests could use
+	if resilient, ok := client.(*ResilientHTTPClient); ok {
+		if reqCtx, err = resilient.rateLimiter.take(reqCtx, req.URL.Host); err != nil {
+			return fmt.Errorf("HTTP request not sent: %w", err)
+		}
+	}
+
+	// Wait for a slot under the process-wide cap on upstream calls; the
+	// slot is held until the response body has been read
+	limiter := flow.UpstreamLimiter()
+	if err := limiter.Acquire(reqCtx); err != nil {
+		return fmt.Errorf("HTTP request not sent: %w", err)
+	}
+	defer limiter.Release()
 
 	// Execute request
-	resp, err := client.Do(req)
+	span := trace.SpanFromContext(req.Context())
+	span.SetAttributes(
+		attribute.String("http.method", h.method),
+		attribute.String("http.url", h.url))
+	resp, err := client.DoWithContext(reqCtx, req)
 	if err != nil {
 		metrics.RecordHTTPRequest(h.method, h.url, 0, time.Since(start))
 		return fmt.Errorf("HTTP request failed: %w", err)
@@ -264,6 +353,7 @@ func (h *HTTPStep) Run(ctx interfaces.ExecutionContext) error {
 
 	// Record HTTP metrics
 	metrics.RecordHTTPRequest(h.method, h.url, resp.StatusCode, time.Since(start))
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 
 	// Process response
 	return h.processResponse(ctx, resp)
@@ -271,6 +361,34 @@ func (h *HTTPStep) Run(ctx interfaces.ExecutionContext) error {
 
 // Helper methods
 
+// httpClient returns the client req is sent with. Unless given their own
+// client or client configuration, steps share the client of their upstream,
+// and with it connection pools and circuit breaker state. An unknown
+// upstream name is an error.
+func (h *HTTPStep) httpClient(req *http.Request) (HTTPClient, error) {
+	if h.client != nil {
+		return h.client, nil
+	}
+	if h.upstream == "" {
+		return Upstreams().ClientFor(req.URL), nil
+	}
+	client, ok := Upstreams().Lookup(h.upstream)
+	if !ok {
+		return nil, errors.NewConfigurationError(errors.ErrCodeInvalidConfiguration,
+			fmt.Sprintf("Step '%s' uses unknown upstream '%s'", h.Name(), h.upstream)).
+			WithContext("step", h.Name()).
+			WithContext("upstream", h.upstream)
+	}
+	return client, nil
+}
+
+// addsIdempotencyKey reports whether client sets an Idempotency-Key header
+// on non-idempotent requests itself
+func addsIdempotencyKey(client HTTPClient) bool {
+	resilient, ok := client.(*ResilientHTTPClient)
+	return ok && resilient.config.AutoIdempotencyKey
+}
+
 // interpolateURL interpolates variables in the URL
 func (h *HTTPStep) interpolateURL(ctx interfaces.ExecutionContext) (string, error) {
 	return utils.InterpolateString(h.url, ctx)
@@ -346,6 +464,12 @@ func (h *HTTPStep) prepareRequest(ctx interfaces.ExecutionContext, bodyBuffer *b
 		req.AddCookie(cookie)
 	}
 
+	// Forward the request ID, the step's span as a W3C traceparent and
+	// the caller's baggage to the upstream
+	if h.propagate {
+		flow.InjectHeaders(req.Context(), req.Header)
+	}
+
 	// Set query parameters
 	if len(h.queryParams) > 0 {
 		q := req.URL.Query()
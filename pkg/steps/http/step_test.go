@@ -7,13 +7,22 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
 )
 
@@ -581,6 +590,46 @@ func TestHTTPStepRun_UnexpectedStatus(t *testing.T) {
 	assert.Contains(t, err.Error(), "unexpected status code: 500")
 }
 
+func TestHTTPStepRun_FallbackKeepsExpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": "real upstream detail"}`)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.MaxRetries = 0
+	ctx := NewMockExecutionContext()
+	err := GET(server.URL).
+		WithClientConfig(config).
+		WithExpectedStatus(200, 500).
+		SaveAs("result").
+		Run(ctx)
+	require.NoError(t, err)
+
+	result, _ := ctx.Get("result")
+	response := result.(map[string]interface{})
+	assert.Equal(t, 500, response["status_code"])
+	assert.Equal(t, "real upstream detail", response["body"].(map[string]interface{})["error"])
+}
+
+func TestHTTPStepRun_SharedClientDoesNotFallBack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.HTTP.MaxRetries = 0
+	ConfigureUpstreams(cfg)
+	defer ConfigureUpstreams(config.DefaultConfig())
+
+	err := GET(server.URL).Run(NewMockExecutionContext())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code: 502")
+}
+
 func TestHTTPStepRun_WithTransformer(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -727,6 +776,15 @@ func TestHTTPStepRun_Timeout(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestHTTPStep_WithTimeoutSetsStepDeadline(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Timeouts.StepExecution = 10 * time.Second
+	ctx := flow.NewContextWithConfig(cfg)
+
+	assert.Equal(t, 10*time.Second, flow.StepTimeout(ctx, GET("http://example.com")))
+	assert.Equal(t, 30*time.Second, flow.StepTimeout(ctx, GET("http://example.com").WithTimeout(30*time.Second)))
+}
+
 func TestNewJSONAPIStep(t *testing.T) {
 	step := NewJSONAPIStep("POST", "https://api.example.com/data")
 
@@ -806,3 +864,212 @@ func TestHTTPStepRun_EmptyResponse(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, 204, responseMap["status_code"])
 }
+
+// spanContext is a MockExecutionContext carrying a Go context with a span
+type spanContext struct {
+	*MockExecutionContext
+	ctx context.Context
+}
+
+func (s *spanContext) Context() context.Context {
+	return s.ctx
+}
+
+func TestHTTPStepRun_Tracing(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	goCtx, span := provider.Tracer("test").Start(context.Background(), "step")
+
+	ctx := &spanContext{MockExecutionContext: NewMockExecutionContext(), ctx: goCtx}
+	err := GET(server.URL + "/traced").Run(ctx)
+	span.End()
+
+	assert.NoError(t, err)
+	sc := span.SpanContext()
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", sc.TraceID(), sc.SpanID()), traceparent)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, attr := range spans[0].Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		assert.Equal(t, "GET", attrs["http.method"].AsString())
+		assert.Equal(t, int64(http.StatusOK), attrs["http.status_code"].AsInt64())
+	}
+}
+
+func TestHTTPStepRun_NoTraceparentWithoutSpan(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := GET(server.URL).Run(NewMockExecutionContext())
+
+	assert.NoError(t, err)
+	assert.Empty(t, header.Get("traceparent"))
+}
+
+func TestHTTPStepRun_Propagation(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m, _ := baggage.NewMember("tenant", "acme")
+	bag, _ := baggage.New(m)
+	goCtx := baggage.ContextWithBaggage(flow.ContextWithRequestID(context.Background(), "req-42"), bag)
+	ctx := &spanContext{MockExecutionContext: NewMockExecutionContext(), ctx: goCtx}
+
+	err := GET(server.URL).Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-42", header.Get("X-Request-ID"))
+	assert.Equal(t, "tenant=acme", header.Get("baggage"))
+
+	err = GET(server.URL).WithPropagation(false).Run(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, header.Get("X-Request-ID"))
+	assert.Empty(t, header.Get("baggage"))
+}
+
+func TestHTTPStepRun_UpstreamLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HTTP.MaxInFlight = 1
+	flow.ConfigureUpstreamLimit(cfg)
+	defer flow.ConfigureUpstreamLimit(config.DefaultConfig())
+
+	var running, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		if n > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	done := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { done <- GET(server.URL).Run(NewMockExecutionContext()) }()
+	}
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, <-done)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&peak))
+}
+
+func TestHTTPStepRun_RateLimitWaitHoldsNoSlot(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HTTP.MaxInFlight = 1
+	flow.ConfigureUpstreamLimit(cfg)
+	defer flow.ConfigureUpstreamLimit(config.DefaultConfig())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	throttledConfig := DefaultClientConfig()
+	throttledConfig.EnableRateLimit = true
+	throttledConfig.RequestsPerSecond = 5
+	throttledConfig.BurstSize = 1
+	throttled := NewResilientHTTPClient(throttledConfig)
+	require.NoError(t, GET(server.URL).WithClient(throttled).Run(NewMockExecutionContext()))
+
+	// The throttled step waits about 200ms for its token without holding
+	// the only slot
+	done := make(chan error, 1)
+	go func() { done <- GET(server.URL).WithClient(throttled).Run(NewMockExecutionContext()) }()
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, GET(server.URL).Run(NewMockExecutionContext()))
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.NoError(t, <-done)
+}
+
+func TestHTTPStep_FlowWriteCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"path": %q}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	for _, check := range []flow.WriteCheck{flow.WriteCheckWarn, flow.WriteCheckFail} {
+		result, err := flow.NewFlow("dashboard").
+			WithWriteCheck(check).
+			Step("profile", GET(server.URL+"/profile").SaveAs("profile")).
+			Step("orders", GET(server.URL+"/orders").SaveAs("orders")).
+			Execute(flow.NewContext().WithLogger(zap.NewNop()))
+		require.NoError(t, err, check)
+		assert.Empty(t, result.Overwrites, "%s: bookkeeping keys such as http_metadata are not overwrites", check)
+		assert.True(t, result.Context.Has("profile") && result.Context.Has("orders"), check)
+	}
+}
+
+func TestHTTPStepRun_UsesInjectedClient(t *testing.T) {
+	client := new(MockHTTPClient)
+	client.On("DoWithContext", mock.Anything, mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"id": 7}`)),
+	}, nil)
+
+	ctx := NewMockExecutionContext()
+	err := GET("https://api.example.com/users/7").WithClient(client).SaveAs("user").Run(ctx)
+	require.NoError(t, err)
+
+	client.AssertNumberOfCalls(t, "DoWithContext", 1)
+	req := client.Calls[0].Arguments.Get(1).(*http.Request)
+	assert.Equal(t, "https://api.example.com/users/7", req.URL.String())
+
+	user, _ := ctx.Get("user")
+	assert.Equal(t, float64(7), user.(map[string]interface{})["body"].(map[string]interface{})["id"])
+}
+
+func TestHTTPStepRun_ClientConfigRetries(t *testing.T) {
+	var calls int32
+	keys := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "Ada"}`, string(body), "every attempt sends the body")
+		keys <- r.Header.Get(IdempotencyKeyHeader)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	config := DefaultClientConfig()
+	config.InitialRetryDelay = time.Millisecond
+	config.MaxRetryDelay = 5 * time.Millisecond
+	config.EnableFallback = false
+	config.AutoIdempotencyKey = true
+
+	err := POST(server.URL).
+		WithJSONBody(map[string]interface{}{"name": "Ada"}).
+		WithClientConfig(config).
+		Run(NewMockExecutionContext())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	first, second := <-keys, <-keys
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second, "retries reuse the idempotency key")
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+)
+
+// UpstreamRegistry holds one ResilientHTTPClient per upstream service, so
+// that each upstream has its own connection pool, circuit breaker and
+// bulkhead and one failing backend cannot trip the breaker of another.
+//
+// Upstreams are named explicitly with Register or through the configuration,
+// or implicitly by request host: a step without an upstream name uses the
+// client of the upstream its host is mapped to, or otherwise a client of
+// its own host with the base settings. Once MaxHostUpstreams unmapped hosts
+// have a client, further hosts share the client of DefaultUpstream. Clients
+// are created on first use.
+type UpstreamRegistry struct {
+	mu       sync.RWMutex
+	base     *ClientConfig
+	configs  map[string]*ClientConfig
+	hosts    map[string]string
+	clients  map[string]*ResilientHTTPClient
+	unmapped map[string]bool // hosts with a client of their own
+	maxHosts int
+}
+
+// DefaultUpstream is the upstream requests to unmapped hosts are sent
+// through once MaxHostUpstreams hosts have a client of their own. It uses
+// the registry's base settings unless registered.
+const DefaultUpstream = "default"
+
+// MaxHostUpstreams bounds the number of unmapped hosts given a client, and
+// with it a connection pool and breaker gauge, of their own
+const MaxHostUpstreams = 256
+
+// NewUpstreamRegistry creates a registry whose unconfigured upstreams use
+// base, or DefaultClientConfig when base is nil
+func NewUpstreamRegistry(base *ClientConfig) *UpstreamRegistry {
+	if base == nil {
+		base = DefaultClientConfig()
+	}
+	return &UpstreamRegistry{
+		base:     base,
+		configs:  make(map[string]*ClientConfig),
+		hosts:    make(map[string]string),
+		clients:  make(map[string]*ResilientHTTPClient),
+		unmapped: make(map[string]bool),
+		maxHosts: MaxHostUpstreams,
+	}
+}
+
+// NewUpstreamRegistryFromConfig creates a registry from cfg.HTTP: the base
+// settings apply to every upstream, and each entry of cfg.HTTP.Upstreams
+// registers a named upstream with its overrides and hosts
+func NewUpstreamRegistryFromConfig(cfg *config.FrameworkConfig) *UpstreamRegistry {
+	registry := NewUpstreamRegistry(ClientConfigFromHTTP(cfg.HTTP))
+	for name, upstream := range cfg.HTTP.Upstreams {
+		registry.Register(name, UpstreamClientConfig(cfg.HTTP, upstream), upstream.Hosts...)
+	}
+	return registry
+}
+
+// Register configures the named upstream and routes requests to hosts
+// through it. A client already created for the upstream is replaced.
+func (r *UpstreamRegistry) Register(name string, cfg *ClientConfig, hosts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.configs[name] = cfg
+	delete(r.clients, name)
+	delete(r.unmapped, name)
+	for _, host := range hosts {
+		r.hosts[host] = name
+		if r.unmapped[host] {
+			delete(r.clients, host)
+			delete(r.unmapped, host)
+		}
+	}
+}
+
+// Client returns the client of the named upstream, creating it on first
+// use. Unregistered names get the client of DefaultUpstream; use Lookup to
+// tell them apart.
+func (r *UpstreamRegistry) Client(name string) *ResilientHTTPClient {
+	if client, ok := r.Lookup(name); ok {
+		return client
+	}
+	client, _ := r.Lookup(DefaultUpstream)
+	return client
+}
+
+// Lookup returns the client of the named upstream, creating it on first
+// use, and false when no upstream of that name is registered.
+// DefaultUpstream is always known.
+func (r *UpstreamRegistry) Lookup(name string) (*ResilientHTTPClient, bool) {
+	r.mu.RLock()
+	client, ok := r.clients[name]
+	r.mu.RUnlock()
+	if ok {
+		return client, true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if client, ok := r.clients[name]; ok {
+		return client, true
+	}
+	cfg, ok := r.configs[name]
+	if !ok {
+		if name != DefaultUpstream {
+			return nil, false
+		}
+		cfg = r.base
+	}
+	client = newResilientHTTPClient(name, cfg)
+	r.clients[name] = client
+	return client, true
+}
+
+// ClientFor returns the client for requests to u: the upstream its host,
+// with or without port, is registered for, or otherwise an upstream named
+// after the host
+func (r *UpstreamRegistry) ClientFor(u *url.URL) *ResilientHTTPClient {
+	name := r.UpstreamFor(u)
+	if client, ok := r.Lookup(name); ok {
+		return client
+	}
+	return r.hostClient(name)
+}
+
+// hostClient returns the client of an unmapped host, creating it with the
+// base settings while fewer than maxHosts hosts have one, and otherwise the
+// client of DefaultUpstream
+func (r *UpstreamRegistry) hostClient(host string) *ResilientHTTPClient {
+	r.mu.Lock()
+	if client, ok := r.clients[host]; ok {
+		r.mu.Unlock()
+		return client
+	}
+	if len(r.unmapped) < r.maxHosts {
+		client := newResilientHTTPClient(host, r.base)
+		r.clients[host] = client
+		r.unmapped[host] = true
+		r.mu.Unlock()
+		return client
+	}
+	r.mu.Unlock()
+
+	client, _ := r.Lookup(DefaultUpstream)
+	return client
+}
+
+// UpstreamFor returns the upstream name requests to u are sent through
+func (r *UpstreamRegistry) UpstreamFor(u *url.URL) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name, ok := r.hosts[u.Host]; ok {
+		return name
+	}
+	if name, ok := r.hosts[u.Hostname()]; ok {
+		return name
+	}
+	if _, ok := r.configs[u.Host]; ok || r.unmapped[u.Host] || len(r.unmapped) < r.maxHosts {
+		return u.Host
+	}
+	return DefaultUpstream
+}
+
+// Upstreams returns the names of the configured upstreams and of those a
+// client was created for, sorted
+func (r *UpstreamRegistry) Upstreams() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool, len(r.configs)+len(r.clients))
+	for name := range r.configs {
+		seen[name] = true
+	}
+	for name := range r.clients {
+		seen[name] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BreakerState returns the circuit breaker state of the named upstream
+// ("closed", "open", "half-open" or "disabled"), and false when no client
+// was created for it yet
+func (r *UpstreamRegistry) BreakerState(name string) (string, bool) {
+	r.mu.RLock()
+	client, ok := r.clients[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return client.CircuitBreakerState(), true
+}
+
+// BreakerStates returns the circuit breaker state of every upstream a client
+// was created for
+func (r *UpstreamRegistry) BreakerStates() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	states := make(map[string]string, len(r.clients))
+	for name, client := range r.clients {
+		states[name] = client.CircuitBreakerState()
+	}
+	return states
+}
+
+// ClientConfigFromHTTP returns DefaultClientConfig with the settings of cfg
+// applied
+func ClientConfigFromHTTP(cfg config.HTTPConfig) *ClientConfig {
+	clientConfig := DefaultClientConfig()
+	clientConfig.MaxIdleConns = cfg.MaxIdleConns
+	clientConfig.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	clientConfig.IdleConnTimeout = cfg.IdleConnTimeout
+	clientConfig.MaxRetries = cfg.MaxRetries
+	clientConfig.InitialRetryDelay = cfg.RetryDelay
+	clientConfig.MaxRetryDelay = cfg.MaxRetryDelay
+	clientConfig.FailureThreshold = cfg.FailureThreshold
+	clientConfig.SuccessThreshold = cfg.SuccessThreshold
+	clientConfig.CircuitBreakerDelay = cfg.CircuitBreakerDelay
+	clientConfig.RequestTimeout = cfg.RequestTimeout
+	clientConfig.EnableFallback = cfg.EnableFallback
+	applyRateLimit(clientConfig, cfg.RateLimit)
+	return clientConfig
+}
+
+// applyRateLimit sets the client rate limit from limit, disabling it when no
+// rate is set
+func applyRateLimit(clientConfig *ClientConfig, limit config.RateLimitConfig) {
+	clientConfig.EnableRateLimit = limit.RequestsPerSecond > 0
+	if !clientConfig.EnableRateLimit {
+		return
+	}
+	clientConfig.RequestsPerSecond = limit.RequestsPerSecond
+	clientConfig.BurstSize = limit.BurstSize
+	clientConfig.RateLimitPerHost = limit.PerHost
+	clientConfig.RateLimitFailFast = limit.FailFast
+}
+
+// UpstreamClientConfig returns the client configuration of an upstream:
+// the HTTPConfig settings with the upstream's overrides applied
+func UpstreamClientConfig(cfg config.HTTPConfig, upstream config.UpstreamConfig) *ClientConfig {
+	clientConfig := ClientConfigFromHTTP(cfg)
+	if upstream.MaxIdleConnsPerHost > 0 {
+		clientConfig.MaxIdleConnsPerHost = upstream.MaxIdleConnsPerHost
+	}
+	if upstream.MaxRetries != nil {
+		clientConfig.MaxRetries = *upstream.MaxRetries
+	}
+	if upstream.RetryDelay > 0 {
+		clientConfig.InitialRetryDelay = upstream.RetryDelay
+	}
+	if upstream.MaxRetryDelay > 0 {
+		clientConfig.MaxRetryDelay = upstream.MaxRetryDelay
+	}
+	if upstream.FailureThreshold > 0 {
+		clientConfig.FailureThreshold = upstream.FailureThreshold
+	}
+	if upstream.SuccessThreshold > 0 {
+		clientConfig.SuccessThreshold = upstream.SuccessThreshold
+	}
+	if upstream.CircuitBreakerDelay > 0 {
+		clientConfig.CircuitBreakerDelay = upstream.CircuitBreakerDelay
+	}
+	if upstream.RequestTimeout > 0 {
+		clientConfig.RequestTimeout = upstream.RequestTimeout
+	}
+	if upstream.EnableFallback != nil {
+		clientConfig.EnableFallback = *upstream.EnableFallback
+	}
+	clientConfig.MaxConcurrent = upstream.MaxConcurrent
+	clientConfig.MaxWaitTime = upstream.MaxWait
+	if upstream.RateLimit != nil {
+		applyRateLimit(clientConfig, *upstream.RateLimit)
+	}
+	return clientConfig
+}
+
+var (
+	upstreamsMu sync.Mutex
+	upstreams   *UpstreamRegistry
+)
+
+// ConfigureUpstreams replaces the process-wide upstream registry with one
+// built from cfg. Requests already running keep their client.
+func ConfigureUpstreams(cfg *config.FrameworkConfig) {
+	registry := NewUpstreamRegistryFromConfig(cfg)
+	upstreamsMu.Lock()
+	defer upstreamsMu.Unlock()
+	upstreams = registry
+}
+
+// Upstreams returns the process-wide upstream registry used by HTTP steps.
+// Until ConfigureUpstreams is called it follows the default configuration.
+func Upstreams() *UpstreamRegistry {
+	upstreamsMu.Lock()
+	defer upstreamsMu.Unlock()
+	if upstreams == nil {
+		upstreams = NewUpstreamRegistryFromConfig(config.DefaultConfig())
+	}
+	return upstreams
+}
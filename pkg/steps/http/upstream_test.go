@@ -0,0 +1,242 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/failsafe-go/failsafe-go/bulkhead"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/config"
+	frameworkErrors "github.com/venkatvghub/api-orchestration-framework/pkg/errors"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/metrics"
+)
+
+// upstreamTestConfig returns a configuration whose upstreams fail fast: one
+// failure opens a breaker, and failures are neither retried nor replaced by
+// a fallback response
+func upstreamTestConfig(upstreams map[string]config.UpstreamConfig) *config.FrameworkConfig {
+	cfg := config.DefaultConfig()
+	cfg.HTTP.MaxRetries = 0
+	cfg.HTTP.FailureThreshold = 1
+	cfg.HTTP.CircuitBreakerDelay = time.Minute
+	cfg.HTTP.EnableFallback = false
+	cfg.HTTP.Upstreams = upstreams
+	return cfg
+}
+
+func TestUpstreamRegistry_IsolatesBreakers(t *testing.T) {
+	collector := metrics.NewInMemoryMetrics()
+	previous := metrics.GetGlobalMetrics()
+	metrics.SetGlobalMetrics(collector)
+	defer metrics.SetGlobalMetrics(previous)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	failingURL, _ := url.Parse(failing.URL)
+	healthyURL, _ := url.Parse(healthy.URL)
+	registry := NewUpstreamRegistryFromConfig(upstreamTestConfig(map[string]config.UpstreamConfig{
+		"profile-service": {Hosts: []string{failingURL.Host}},
+		"orders-service":  {Hosts: []string{healthyURL.Host}},
+	}))
+
+	for _, u := range []*url.URL{failingURL, healthyURL} {
+		req, _ := http.NewRequest(http.MethodGet, u.String(), nil)
+		resp, err := registry.ClientFor(u).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, map[string]string{
+		"profile-service": "open",
+		"orders-service":  "closed",
+	}, registry.BreakerStates())
+	assert.Equal(t, []string{"orders-service", "profile-service"}, registry.Upstreams())
+
+	gauges := collector.GetGauges()
+	assert.Equal(t, 2.0, gauges[CircuitBreakerStateMetric+",upstream=profile-service"])
+	assert.Equal(t, 0.0, gauges[CircuitBreakerStateMetric+",upstream=orders-service"])
+
+	_, ok := registry.BreakerState("inventory-service")
+	assert.False(t, ok, "no client was created for the upstream")
+}
+
+func TestUpstreamRegistry_HostRouting(t *testing.T) {
+	registry := NewUpstreamRegistry(nil)
+	registry.Register("profile-service", DefaultClientConfig(), "profile.internal")
+
+	profile, _ := url.Parse("http://profile.internal:8080/users/1")
+	other, _ := url.Parse("http://orders.internal:8080/orders")
+	assert.Equal(t, "profile-service", registry.UpstreamFor(profile))
+	assert.Equal(t, "orders.internal:8080", registry.UpstreamFor(other))
+
+	client := registry.ClientFor(profile)
+	assert.Same(t, client, registry.Client("profile-service"))
+	assert.Equal(t, "profile-service", client.Name())
+	assert.NotSame(t, client, registry.ClientFor(other))
+	assert.Same(t, registry.ClientFor(other), registry.ClientFor(other))
+
+	_, ok := registry.Lookup("profile-servce")
+	assert.False(t, ok)
+	assert.Same(t, registry.Client(DefaultUpstream), registry.Client("profile-servce"))
+}
+
+func TestUpstreamRegistry_UnmappedHostsIsolateBreakers(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	// No upstream is configured, so both hosts are unmapped
+	registry := NewUpstreamRegistryFromConfig(upstreamTestConfig(nil))
+	failingURL, _ := url.Parse(failing.URL)
+	healthyURL, _ := url.Parse(healthy.URL)
+	for _, u := range []*url.URL{failingURL, healthyURL} {
+		req, _ := http.NewRequest(http.MethodGet, u.String(), nil)
+		resp, err := registry.ClientFor(u).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, map[string]string{
+		failingURL.Host: "open",
+		healthyURL.Host: "closed",
+	}, registry.BreakerStates())
+}
+
+func TestUpstreamRegistry_MaxHostUpstreams(t *testing.T) {
+	registry := NewUpstreamRegistry(nil)
+	registry.maxHosts = 1
+
+	first, _ := url.Parse("http://orders.internal/orders")
+	second, _ := url.Parse("http://inventory.internal/items")
+	third, _ := url.Parse("http://search.internal/query")
+
+	assert.Equal(t, "orders.internal", registry.ClientFor(first).Name())
+	assert.Equal(t, DefaultUpstream, registry.UpstreamFor(second))
+	assert.Same(t, registry.Client(DefaultUpstream), registry.ClientFor(second))
+	assert.Same(t, registry.ClientFor(second), registry.ClientFor(third), "hosts over the cap share the default client")
+	assert.Equal(t, "orders.internal", registry.UpstreamFor(first), "hosts with a client keep it")
+}
+
+func TestUpstreamClientConfig(t *testing.T) {
+	retries := 0
+	fallback := false
+	cfg := config.DefaultConfig()
+	cfg.HTTP.RequestTimeout = 20 * time.Second
+
+	clientConfig := UpstreamClientConfig(cfg.HTTP, config.UpstreamConfig{
+		MaxRetries:       &retries,
+		FailureThreshold: 2,
+		EnableFallback:   &fallback,
+		MaxConcurrent:    8,
+		MaxWait:          time.Second,
+	})
+
+	assert.Equal(t, 0, clientConfig.MaxRetries)
+	assert.Equal(t, uint(2), clientConfig.FailureThreshold)
+	assert.False(t, clientConfig.EnableFallback)
+	assert.Equal(t, 8, clientConfig.MaxConcurrent)
+	assert.Equal(t, time.Second, clientConfig.MaxWaitTime)
+
+	// Unset fields inherit the HTTP settings
+	assert.Equal(t, 20*time.Second, clientConfig.RequestTimeout)
+	assert.Equal(t, cfg.HTTP.SuccessThreshold, clientConfig.SuccessThreshold)
+	assert.Equal(t, cfg.HTTP.RetryDelay, clientConfig.InitialRetryDelay)
+}
+
+func TestUpstreamRegistry_Bulkhead(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewUpstreamRegistryFromConfig(upstreamTestConfig(map[string]config.UpstreamConfig{
+		"profile-service": {MaxConcurrent: 1},
+	}))
+	client := registry.Client("profile-service")
+
+	done := make(chan error, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	<-started
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	_, err := client.Do(req)
+	assert.ErrorIs(t, err, bulkhead.ErrFull)
+	assert.Equal(t, "closed", client.CircuitBreakerState(), "a full bulkhead is not an upstream failure")
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestHTTPStepRun_WithUpstream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ConfigureUpstreams(upstreamTestConfig(map[string]config.UpstreamConfig{
+		"profile-service": {},
+	}))
+	defer ConfigureUpstreams(config.DefaultConfig())
+
+	err := GET(server.URL).WithUpstream("profile-service").Run(NewMockExecutionContext())
+	require.Error(t, err)
+
+	state, ok := Upstreams().BreakerState("profile-service")
+	assert.True(t, ok)
+	assert.Equal(t, "open", state)
+
+	serverURL, _ := url.Parse(server.URL)
+	_, ok = Upstreams().BreakerState(serverURL.Host)
+	assert.False(t, ok, "the host's own upstream should not have been used")
+}
+
+func TestHTTPStepRun_UnknownUpstream(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ConfigureUpstreams(upstreamTestConfig(map[string]config.UpstreamConfig{
+		"profile-service": {},
+	}))
+	defer ConfigureUpstreams(config.DefaultConfig())
+
+	err := GET(server.URL).WithUpstream("profile-servce").Run(NewMockExecutionContext())
+	var fwErr *frameworkErrors.FrameworkError
+	require.ErrorAs(t, err, &fwErr)
+	assert.Equal(t, frameworkErrors.ErrCodeInvalidConfiguration, fwErr.Code)
+	assert.Contains(t, err.Error(), "profile-servce")
+	assert.Zero(t, atomic.LoadInt32(&calls), "nothing should be sent")
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/expr"
 )
 
 // MockTransformer is a helper for testing chains
@@ -452,6 +454,18 @@ func TestConditionalTransformerChain(t *testing.T) {
 		assert.Equal(t, expected, output)
 	})
 
+	t.Run("Transform_ExpressionCondition", func(t *testing.T) {
+		ctc := NewConditionalTransformerChain("exprCond")
+		mockB := NewMockTransformer("transformB", func(data map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"transformed_by": "B"}, nil
+		})
+		ctc.When(expr.MustCompile(`type in ["B", "C"] && len(items) > 0`).MapCondition(), mockB)
+
+		output, err := ctc.Transform(map[string]interface{}{"type": "C", "items": []interface{}{1}})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"transformed_by": "B"}, output)
+	})
+
 	t.Run("Transform_NoConditionMet_FallbackUsed", func(t *testing.T) {
 		ctc := NewConditionalTransformerChain("fallbackUsed")
 		condFunc := func(data map[string]interface{}) bool { return data["type"] == "A" }
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"reflect"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
+)
+
+// Convert converts a value read from the context or a decoded JSON document
+// to T. See values.Convert for the conversions it allows.
+func Convert[T any](value interface{}) (T, error) {
+	return values.Convert[T](value)
+}
+
+// ConvertValue is the reflection form of Convert. The returned value has
+// exactly the target type.
+func ConvertValue(value interface{}, target reflect.Type) (reflect.Value, error) {
+	return values.ConvertValue(value, target)
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type convertProfile struct {
+	Name  string   `json:"name"`
+	Age   int      `json:"age"`
+	Roles []string `json:"roles"`
+}
+
+func TestConvert_Numbers(t *testing.T) {
+	n, err := Convert[int](42.0)
+	require.NoError(t, err)
+	assert.Equal(t, 42, n)
+
+	n, err = Convert[int](" 7 ")
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+
+	n, err = Convert[int](json.Number("12"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, n)
+
+	u, err := Convert[uint8](int64(200))
+	require.NoError(t, err)
+	assert.Equal(t, uint8(200), u)
+
+	f, err := Convert[float64]("2.5")
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, f)
+
+	f32, err := Convert[float32](3)
+	require.NoError(t, err)
+	assert.Equal(t, float32(3), f32)
+
+	for _, bad := range []interface{}{42.5, "abc", true, nil} {
+		_, err := Convert[int](bad)
+		assert.Error(t, err, "%v", bad)
+	}
+	_, err = Convert[int8](300)
+	assert.Error(t, err, "overflow")
+	_, err = Convert[uint](-1)
+	assert.Error(t, err, "negative to unsigned")
+}
+
+func TestConvert_Scalars(t *testing.T) {
+	b, err := Convert[bool]("true")
+	require.NoError(t, err)
+	assert.True(t, b)
+	_, err = Convert[bool](1)
+	assert.Error(t, err)
+
+	s, err := Convert[string]([]byte("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "hi", s)
+	_, err = Convert[string](42)
+	assert.Error(t, err, "numbers do not become strings")
+
+	d, err := Convert[time.Duration]("1.5s")
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, d)
+
+	type tier string
+	tr, err := Convert[tier]("gold")
+	require.NoError(t, err)
+	assert.Equal(t, tier("gold"), tr)
+}
+
+func TestConvert_Structured(t *testing.T) {
+	decoded := map[string]interface{}{
+		"name":  "Ada",
+		"age":   36.0,
+		"roles": []interface{}{"admin"},
+	}
+
+	profile, err := Convert[convertProfile](decoded)
+	require.NoError(t, err)
+	assert.Equal(t, convertProfile{Name: "Ada", Age: 36, Roles: []string{"admin"}}, profile)
+
+	ptr, err := Convert[*convertProfile](decoded)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", ptr.Name)
+
+	roles, err := Convert[[]string]([]interface{}{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, roles)
+
+	m, err := Convert[map[string]interface{}](convertProfile{Name: "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", m["name"])
+
+	_, err = Convert[convertProfile]("not an object")
+	assert.Error(t, err)
+
+	var nilMap map[string]int
+	got, err := Convert[map[string]int](nil)
+	require.NoError(t, err)
+	assert.Equal(t, nilMap, got)
+
+	empty, err := Convert[interface{}](nil)
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+}
@@ -10,12 +10,15 @@ import (
 // InterpolateString replaces ${variable} patterns in template with values from context
 func InterpolateString(template string, ctx interfaces.ExecutionContext) (string, error) {
 	result := template
-	// Find all ${...} patterns
+	// Find all ${...} patterns, resuming after each substitution so values
+	// (including kept placeholders) are never re-interpolated
+	offset := 0
 	for {
-		start := strings.Index(result, "${")
+		start := strings.Index(result[offset:], "${")
 		if start == -1 {
 			break
 		}
+		start += offset
 		end := strings.Index(result[start:], "}")
 		if end == -1 {
 			return "", fmt.Errorf("unclosed variable reference in template: %s", template)
@@ -35,6 +38,7 @@ func InterpolateString(template string, ctx interfaces.ExecutionContext) (string
 		}
 
 		result = result[:start] + varValue + result[end+1:]
+		offset = start + len(varValue)
 	}
 	return result, nil
 }
@@ -2,15 +2,14 @@ package utils
 
 import (
 	"fmt"
-	"reflect"
 	"strings"
 
-	"github.com/venkatvghub/api-orchestration-framework/pkg/flow"
 	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
 )
 
 // GetNestedValue extracts nested values from context using dot notation (e.g., "user.profile.name")
-func GetNestedValue(key string, ctx *flow.Context) string {
+func GetNestedValue(key string, ctx interfaces.ExecutionContext) string {
 	parts := strings.Split(key, ".")
 	if len(parts) < 2 {
 		// Single key, try direct lookup
@@ -200,55 +199,18 @@ func GetNestedValueFromContext(path string, ctx interfaces.ExecutionContext) str
 	return fmt.Sprintf("%v", current)
 }
 
+// LookupNestedValue resolves a dot-notation path against the context and
+// returns the raw value rather than its string form
+func LookupNestedValue(path string, ctx interfaces.ExecutionContext) (interface{}, bool) {
+	return values.Lookup(path, ctx)
+}
+
 // getNestedValueFromInterface extracts a value from an interface using a key
 func getNestedValueFromInterface(data interface{}, key string) interface{} {
-	if data == nil {
-		return nil
-	}
-
-	switch v := data.(type) {
-	case map[string]interface{}:
-		return v[key]
-	case map[interface{}]interface{}:
-		return v[key]
-	case map[string]string:
-		return v[key]
-	default:
-		// Try to handle as a struct using reflection
-		return getValueFromStruct(data, key)
-	}
+	return values.Field(data, key)
 }
 
 // getValueFromStruct extracts a field value from a struct using reflection
 func getValueFromStruct(data interface{}, fieldName string) interface{} {
-	if data == nil {
-		return nil
-	}
-
-	v := reflect.ValueOf(data)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-
-	if v.Kind() != reflect.Struct {
-		return nil
-	}
-
-	field := v.FieldByName(fieldName)
-	if !field.IsValid() {
-		// Try case-insensitive lookup
-		t := v.Type()
-		for i := 0; i < t.NumField(); i++ {
-			if strings.EqualFold(t.Field(i).Name, fieldName) {
-				field = v.Field(i)
-				break
-			}
-		}
-	}
-
-	if !field.IsValid() || !field.CanInterface() {
-		return nil
-	}
-
-	return field.Interface()
+	return values.Field(data, fieldName)
 }
@@ -438,6 +438,27 @@ func TestGetNestedValueFromContext(t *testing.T) {
 	})
 }
 
+func TestLookupNestedValue(t *testing.T) {
+	profile := map[string]interface{}{"name": "John", "age": 30}
+	ctx := mockExecutionContext{
+		"user": map[string]interface{}{"profile": profile},
+	}
+
+	value, ok := LookupNestedValue("user.profile", ctx)
+	assert.True(t, ok)
+	assert.Equal(t, profile, value)
+
+	value, ok = LookupNestedValue("user.profile.age", ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 30, value)
+
+	_, ok = LookupNestedValue("user.profile.missing", ctx)
+	assert.False(t, ok)
+
+	_, ok = LookupNestedValue("missing", ctx)
+	assert.False(t, ok)
+}
+
 func TestGetNestedValueFromInterface(t *testing.T) {
 	t.Run("Map string interface", func(t *testing.T) {
 		data := map[string]interface{}{"key": "value"}
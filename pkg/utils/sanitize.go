@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/values"
 )
 
 // SanitizeURL converts a URL into a safe identifier string
@@ -107,6 +109,18 @@ func SanitizeHeaders(headers map[string]interface{}) map[string]interface{} {
 	return sanitized
 }
 
+// IsSensitiveKey reports whether values stored under key should be redacted,
+// e.g. "Authorization", "auth_token" or "client_secret"
+func IsSensitiveKey(key string) bool {
+	return values.IsSensitiveKey(key)
+}
+
+// SanitizeValue returns a copy of value that is safe to log or keep for
+// debugging. See values.Sanitize.
+func SanitizeValue(value interface{}) interface{} {
+	return values.Sanitize(value)
+}
+
 // TruncateString truncates a string to a maximum length with ellipsis
 func TruncateString(input string, maxLength int) string {
 	if len(input) <= maxLength {
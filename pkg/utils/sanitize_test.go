@@ -415,3 +415,50 @@ func TestSanitizationIntegration(t *testing.T) {
 		assert.Equal(t, "api_users_report_pdf.log", filename)
 	})
 }
+
+func TestSanitizeValue(t *testing.T) {
+	t.Run("Nested sensitive keys", func(t *testing.T) {
+		value := map[string]interface{}{
+			"user": map[string]interface{}{
+				"name":     "Ada",
+				"password": "hunter2",
+			},
+			"headers": map[string]string{"Authorization": "Bearer abc", "Accept": "*/*"},
+			"items":   []interface{}{map[string]interface{}{"api_key": "k"}},
+		}
+		expected := map[string]interface{}{
+			"user":    map[string]interface{}{"name": "Ada", "password": "***"},
+			"headers": map[string]interface{}{"Authorization": "***", "Accept": "*/*"},
+			"items":   []interface{}{map[string]interface{}{"api_key": "***"}},
+		}
+		assert.Equal(t, expected, SanitizeValue(value))
+	})
+
+	t.Run("Structs use their JSON form", func(t *testing.T) {
+		type credentials struct {
+			User         string `json:"user"`
+			ClientSecret string `json:"client_secret"`
+		}
+		expected := map[string]interface{}{"user": "svc", "client_secret": "***"}
+		assert.Equal(t, expected, SanitizeValue(&credentials{User: "svc", ClientSecret: "s"}))
+	})
+
+	t.Run("Copies values", func(t *testing.T) {
+		original := map[string]interface{}{"count": 1}
+		sanitized := SanitizeValue(original).(map[string]interface{})
+		original["count"] = 2
+		assert.Equal(t, 1, sanitized["count"])
+	})
+
+	t.Run("Scalars", func(t *testing.T) {
+		assert.Nil(t, SanitizeValue(nil))
+		assert.Equal(t, 42, SanitizeValue(42))
+		assert.Equal(t, "raw", SanitizeValue([]byte("raw")))
+	})
+
+	t.Run("Sensitive keys", func(t *testing.T) {
+		assert.True(t, IsSensitiveKey("X-API-Key"))
+		assert.True(t, IsSensitiveKey("auth_token"))
+		assert.False(t, IsSensitiveKey("user_id"))
+	})
+}
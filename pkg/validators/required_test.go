@@ -2,6 +2,8 @@ package validators
 
 import (
 	"testing"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/expr"
 )
 
 func TestRequiredFieldsValidator(t *testing.T) {
@@ -64,6 +66,22 @@ func TestConditionalRequiredValidator(t *testing.T) {
 	}
 }
 
+func TestConditionalRequiredValidator_Expression(t *testing.T) {
+	v := NewConditionalRequiredValidator().When(
+		expr.MustCompile(`payment.method == "card" && payment.amount > 0`).MapCondition(),
+		[]string{"card_number"},
+		"card_number required for card payments",
+	)
+	free := map[string]interface{}{"payment": map[string]interface{}{"method": "card", "amount": 0}}
+	if err := v.Validate(free); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	paid := map[string]interface{}{"payment": map[string]interface{}{"method": "card", "amount": 12.5}}
+	if err := v.Validate(paid); err == nil {
+		t.Error("expected error for missing card_number")
+	}
+}
+
 func TestEmailRequiredValidator(t *testing.T) {
 	v := EmailRequiredValidator("email")
 	ok := map[string]interface{}{"email": "a@b.com"}
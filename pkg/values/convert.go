@@ -0,0 +1,186 @@
+// Package values converts, looks up and redacts the loosely typed values
+// held in an execution context. It sits below both pkg/flow and pkg/utils,
+// which re-exports it, so that the flow engine does not depend on utils.
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Convert converts a value read from the context or a decoded JSON document
+// to T. Unlike a type assertion it is lenient about the shapes data takes
+// after JSON decoding:
+//   - numbers convert between all numeric types, including float64 to int
+//     when the value is whole, and numeric strings parse as numbers
+//   - strings parse as booleans ("true", "false", "1", "0") and durations
+//   - maps and slices convert to structs, typed maps and typed slices through
+//     a JSON round trip, honoring json tags; fields inside them follow the
+//     usual encoding/json rules
+//
+// Numbers never convert to strings, and conversions that would lose
+// precision or overflow fail.
+func Convert[T any](value interface{}) (T, error) {
+	var zero T
+	converted, err := ConvertValue(value, reflect.TypeOf(&zero).Elem())
+	if err != nil {
+		return zero, err
+	}
+	// The assertion only fails for a nil interface, whose zero value is
+	// the result
+	result, _ := converted.Interface().(T)
+	return result, nil
+}
+
+// ConvertValue is the reflection form of Convert. The returned value has
+// exactly the target type.
+func ConvertValue(value interface{}, target reflect.Type) (reflect.Value, error) {
+	out := reflect.New(target).Elem()
+	if value == nil {
+		switch target.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			return out, nil
+		}
+		return out, fmt.Errorf("cannot convert null to %s", target)
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(target) {
+		out.Set(v)
+		return out, nil
+	}
+
+	if target == durationType && v.Kind() == reflect.String {
+		d, err := time.ParseDuration(v.String())
+		if err != nil {
+			return out, fmt.Errorf("cannot convert %q to %s: %w", v.String(), target, err)
+		}
+		out.SetInt(int64(d))
+		return out, nil
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(v)
+		if err != nil || out.OverflowInt(n) {
+			return out, conversionError(value, target, err)
+		}
+		out.SetInt(n)
+		return out, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := toInt64(v)
+		if err != nil || n < 0 || out.OverflowUint(uint64(n)) {
+			return out, conversionError(value, target, err)
+		}
+		out.SetUint(uint64(n))
+		return out, nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(v)
+		if err != nil || out.OverflowFloat(f) {
+			return out, conversionError(value, target, err)
+		}
+		out.SetFloat(f)
+		return out, nil
+
+	case reflect.Bool:
+		if v.Kind() == reflect.Bool {
+			out.SetBool(v.Bool())
+			return out, nil
+		}
+		if v.Kind() == reflect.String {
+			b, err := strconv.ParseBool(strings.TrimSpace(v.String()))
+			if err != nil {
+				return out, conversionError(value, target, err)
+			}
+			out.SetBool(b)
+			return out, nil
+		}
+		return out, conversionError(value, target, nil)
+
+	case reflect.String:
+		switch {
+		case v.Kind() == reflect.String:
+			out.SetString(v.String())
+			return out, nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			out.SetString(string(v.Bytes()))
+			return out, nil
+		}
+		return out, conversionError(value, target, nil)
+
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return out, conversionError(value, target, err)
+		}
+		if err := json.Unmarshal(data, out.Addr().Interface()); err != nil {
+			return out, conversionError(value, target, err)
+		}
+		return out, nil
+	}
+
+	return out, conversionError(value, target, nil)
+}
+
+func conversionError(value interface{}, target reflect.Type, cause error) error {
+	if cause != nil {
+		return fmt.Errorf("cannot convert %T to %s: %w", value, target, cause)
+	}
+	return fmt.Errorf("cannot convert %T to %s", value, target)
+}
+
+// toInt64 reads an integer from a number or numeric string, rejecting
+// fractions
+func toInt64(v reflect.Value) (int64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows int64", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.String:
+		s := strings.TrimSpace(v.String())
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v is not a whole number", f)
+	}
+	return int64(f), nil
+}
+
+// toFloat64 reads a number or numeric string, including json.Number
+func toFloat64(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v.String())
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("%s is not a number", v.Type())
+}
@@ -0,0 +1,82 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/venkatvghub/api-orchestration-framework/pkg/interfaces"
+)
+
+// Lookup resolves a dot-notation path against the context and
+// returns the raw value rather than its string form
+func Lookup(path string, ctx interfaces.ExecutionContext) (interface{}, bool) {
+	parts := strings.Split(path, ".")
+
+	current, ok := ctx.Get(parts[0])
+	if !ok {
+		return nil, false
+	}
+
+	for _, part := range parts[1:] {
+		current = Field(current, part)
+		if current == nil {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// Field returns the value under key in a map, or the field named key in a
+// struct, or nil
+func Field(data interface{}, key string) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		return v[key]
+	case map[interface{}]interface{}:
+		return v[key]
+	case map[string]string:
+		return v[key]
+	default:
+		// Try to handle as a struct using reflection
+		return structField(data, key)
+	}
+}
+
+// structField extracts a field value from a struct using reflection
+func structField(data interface{}, fieldName string) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := v.FieldByName(fieldName)
+	if !field.IsValid() {
+		// Try case-insensitive lookup
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if strings.EqualFold(t.Field(i).Name, fieldName) {
+				field = v.Field(i)
+				break
+			}
+		}
+	}
+
+	if !field.IsValid() || !field.CanInterface() {
+		return nil
+	}
+
+	return field.Interface()
+}
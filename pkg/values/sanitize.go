@@ -0,0 +1,107 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// sensitiveKeyPattern matches map keys and context keys whose values are
+// credentials
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|pwd|secret|token|authorization|cookie|api[-_]?key|credential|private[-_]?key)`)
+
+// IsSensitiveKey reports whether values stored under key should be redacted,
+// e.g. "Authorization", "auth_token" or "client_secret"
+func IsSensitiveKey(key string) bool {
+	return sensitiveKeyPattern.MatchString(key)
+}
+
+// Sanitize returns a copy of value that is safe to log or keep for
+// debugging: values under sensitive keys (see IsSensitiveKey) are replaced
+// with "***" at any depth, like utils.SanitizeHeaders does for headers. Maps and
+// slices are copied, and structs are converted to their JSON form first so
+// that their fields are redacted too. The result never shares mutable state
+// with value.
+func Sanitize(value interface{}) interface{} {
+	return sanitizeValue(reflect.ValueOf(value))
+}
+
+func sanitizeValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return sanitizeValue(v.Elem())
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			return sanitizeJSON(v.Interface())
+		}
+		return sanitizeValue(v.Elem())
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return sanitizeJSON(v.Interface())
+		}
+		sanitized := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if IsSensitiveKey(key) {
+				sanitized[key] = "***"
+				continue
+			}
+			sanitized[key] = sanitizeValue(iter.Value())
+		}
+		return sanitized
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		sanitized := make([]interface{}, v.Len())
+		for i := range sanitized {
+			sanitized[i] = sanitizeValue(v.Index(i))
+		}
+		return sanitized
+
+	case reflect.Struct:
+		return sanitizeJSON(v.Interface())
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return fmt.Sprintf("%T", v.Interface())
+	}
+	return v.Interface()
+}
+
+// sanitizeJSON sanitizes the JSON form of value, falling back to its
+// formatted form when it does not encode
+func sanitizeJSON(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return sanitizeValue(reflect.ValueOf(decoded))
+}